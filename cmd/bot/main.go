@@ -1,11 +1,14 @@
 package main
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Встроенная база часовых поясов для пользовательских настроек
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/config"
@@ -13,85 +16,219 @@ import (
 	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
 	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/handlers"
 	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/scheduler"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/sender"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/server"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/pkg/logger"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/pkg/metrics"
 )
 
 func main() {
 	// 1. Загрузка конфигурации
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("Ошибка загрузки конфигурации: %v", err)
+		fatal("Ошибка загрузки конфигурации", err)
+	}
+
+	// Логгер маскирует токен бота и ключи API во всех записях, включая ошибки
+	// библиотек, в текст которых попадает адрес запроса
+	if _, err := logger.Setup(logger.Config{
+		Level:   cfg.LogLevel,
+		Format:  cfg.LogFormat,
+		Secrets: cfg.Secrets(),
+	}); err != nil {
+		fatal("Ошибка настройки логирования", err)
+	}
+	if err := tgbotapi.SetLogger(logger.StdLogger(slog.LevelWarn)); err != nil {
+		fatal("Ошибка настройки логирования Telegram API", err)
 	}
 
 	// 2. Инициализация базы данных
 	dbConn, err := database.New(cfg.DBPath)
 	if err != nil {
-		log.Fatalf("Ошибка инициализации базы данных: %v", err)
+		fatal("Ошибка инициализации базы данных", err)
 	}
 	db := dbConn.GetDB() // Получаем *gorm.DB из интерфейса
 
 	// Запускаем миграцию для исправления регистра старых подписок
 	if err := database.MigrateSubscriptionsToLower(db); err != nil {
-		log.Printf("Ошибка миграции данных: %v", err)
+		slog.Error("Ошибка миграции данных", "error", err)
+	}
+	// Переводим историю отправки и избранное с адресов статей на ключи канонических адресов
+	if err := database.MigrateArticleHashes(db, fetcher.ArticleKey); err != nil {
+		slog.Error("Ошибка миграции ключей статей", "error", err)
 	}
 
 	// 3. Инициализация бота
 	bot, err := tgbotapi.NewBotAPI(cfg.Token)
 	if err != nil {
-		log.Fatalf("Ошибка инициализации бота: %v", err)
+		fatal("Ошибка инициализации бота", err)
 	}
-	bot.Debug = true
-	log.Printf("Авторизован как %s", bot.Self.UserName)
+	slog.Info("Авторизован в Telegram", "username", bot.Self.UserName)
 
 	// 4. Создание репозиториев
 	userRepo := database.NewUserRepository(db)
 	subRepo := database.NewSubscriptionRepository(db)
 	sentArticleRepo := database.NewSentArticleRepository(db)
 	favoriteArticleRepo := database.NewFavoriteArticleRepository(db)
+	outboxRepo := database.NewOutboxRepository(db)
+	digestRepo := database.NewDigestRepository(db)
+	aliasRepo := database.NewTopicAliasRepository(db)
+	statsRepo := database.NewStatsRepository(db)
+
+	// Общий отправитель сообщений с учетом лимитов Telegram для планировщика и обработчиков
+	msgSender := sender.New(bot, sender.DefaultConfig())
 
 	// 5. Инициализация Fetcher и Scheduler
-	// Передаем оба API ключа
-	newsFetcher := fetcher.NewFetcher(cfg.GNewsAPIKey, cfg.NewsAPIKey)
+	// Регистрируем провайдеров новостей; порядок и состав цепочки задаются конфигурацией
+	httpClient := fetcher.NewHTTPClient()
+	registry := fetcher.NewRegistry()
+	for _, provider := range []fetcher.NewsProvider{
+		fetcher.NewGNewsProvider(cfg.GNewsAPIKey, httpClient),
+		fetcher.NewNewsAPIProvider(cfg.NewsAPIKey, httpClient),
+		fetcher.NewRSSProvider(cfg.RSSFeeds, httpClient),
+	} {
+		if err := registry.Register(provider); err != nil {
+			fatal("Ошибка регистрации провайдера новостей", err)
+		}
+	}
+	if err := registry.Configure(cfg.Providers); err != nil {
+		fatal("Ошибка настройки провайдеров новостей", err)
+	}
+	slog.Info("Провайдеры новостей", "providers", cfg.Providers)
+
+	newsFetcher := fetcher.NewFetcher(registry)
+	if cfg.CacheTTL > 0 {
+		var cacheStore fetcher.CacheStore
+		if cfg.CachePersist {
+			cacheStore = database.NewCacheRepository(db)
+		}
+		newsFetcher.SetCache(fetcher.NewCache(cfg.CacheTTL, cfg.CacheSize, cacheStore))
+		slog.Info("Кэш новостей включен", "ttl", cfg.CacheTTL, "size", cfg.CacheSize, "persist", cfg.CachePersist)
+	}
+	// Запросы к провайдерам учитываются в дневных квотах, которые переживают перезапуск
+	providerQuota := fetcher.NewQuota(map[string]int{
+		"gnews":   cfg.GNewsDailyQuota,
+		"newsapi": cfg.NewsAPIDailyQuota,
+	}, database.NewProviderQuotaRepository(db))
+	newsFetcher.SetQuota(providerQuota)
+	// Исправления и синонимы тем подставляются в запросы ко всем провайдерам
+	topicAliases := fetcher.NewAliases(aliasRepo)
+	if err := topicAliases.Reload(context.Background()); err != nil {
+		slog.Error("Не удалось загрузить таблицу синонимов тем", "error", err)
+	}
+	newsFetcher.SetAliases(topicAliases)
 	// Интервал проверки - 1 минута (для теста)
-	newsScheduler := scheduler.NewScheduler(bot, userRepo, subRepo, sentArticleRepo, favoriteArticleRepo, newsFetcher, 1*time.Minute)
+	newsScheduler := scheduler.NewScheduler(msgSender, userRepo, subRepo, sentArticleRepo, favoriteArticleRepo, outboxRepo, digestRepo, newsFetcher, scheduler.Options{
+		Interval:     1 * time.Minute,
+		Workers:      cfg.SchedulerWorkers,
+		BatchSize:    cfg.SchedulerBatchSize,
+		CycleTimeout: cfg.SchedulerCycleTimeout,
+	})
 
 	// 6. Создание обработчика
-	handler := handlers.NewHandler(bot, userRepo, subRepo, newsScheduler)
-
-	// 7. Настройка и запуск
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-
+	handler := handlers.NewHandler(msgSender, userRepo, subRepo, aliasRepo, statsRepo, topicAliases, newsScheduler, cfg.AdminIDs)
+
+	// 7. Запуск: оба режима используют общий жизненный цикл, который завершается по SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Получение обновлений: вебхук-сервер или long polling
+	var (
+		webhookServer *server.Server
+		poller        *server.Poller
+		updatesCheck  server.CheckFunc
+	)
 	if cfg.Mode == "webhook" {
-		log.Fatal("Режим Webhook пока не поддерживается в этой конфигурации.")
-		// TODO: Добавить graceful shutdown и для webhook
+		webhookServer = server.New(bot, handler, server.Config{
+			Port:        cfg.Port,
+			WebhookURL:  cfg.WebhookURL,
+			TLSCertPath: cfg.TLSCertPath,
+			TLSKeyPath:  cfg.TLSKeyPath,
+			SecretToken: cfg.WebhookSecret,
+		})
+		updatesCheck = webhookServer.HealthCheck
 	} else {
-		log.Println("Бот запущен в режиме long polling")
-
-		// Запускаем планировщик
-		newsScheduler.Start()
+		poller = server.NewPoller(bot, handler)
+		updatesCheck = poller.HealthCheck
+	}
 
-		// Настраиваем канал для получения обновлений.
-		updates := bot.GetUpdatesChan(tgbotapi.UpdateConfig{
-			Offset:  0,
-			Timeout: 60,
+	// Запускаем планировщик
+	newsScheduler.Start()
+
+	// Сервер метрик и проверок состояния и административное API работают в обоих режимах
+	// и останавливаются вместе с ботом
+	var serversDone sync.WaitGroup
+	if cfg.ObservabilityAddr != "" {
+		observability := server.NewObservability(cfg.ObservabilityAddr, metrics.Default)
+		observability.AddCheck("database", func(ctx context.Context) error {
+			sqlDB, err := dbConn.GetDB().DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
 		})
-
-		// Запускаем обработку обновлений в отдельной горутине.
+		observability.AddCheck("scheduler", newsScheduler.HealthCheck)
+		observability.AddCheck("updates", updatesCheck)
+		observability.AddCheck("providers", newsFetcher.HealthCheck)
+		serversDone.Add(1)
 		go func() {
-			for update := range updates {
-				go handler.HandleUpdate(update)
+			defer serversDone.Done()
+			if err := observability.Start(ctx); err != nil {
+				slog.Error("Сервер метрик остановлен с ошибкой", "error", err)
 			}
 		}()
+	}
+	if cfg.AdminAPIToken != "" && cfg.AdminAPIAddr != "" {
+		adminAPI := server.NewAdminAPI(server.AdminConfig{Addr: cfg.AdminAPIAddr, Token: cfg.AdminAPIToken},
+			userRepo, subRepo, outboxRepo, newsScheduler, registry, newsFetcher)
+		serversDone.Add(1)
+		go func() {
+			defer serversDone.Done()
+			if err := adminAPI.Start(ctx); err != nil {
+				slog.Error("Административное API остановлено с ошибкой", "error", err)
+			}
+		}()
+	}
 
-		// Ожидаем сигнал для завершения работы.
-		<-sigChan
-		log.Println("Получен сигнал завершения, останавливаем сервисы...")
+	var runErr error
+	if webhookServer != nil {
+		slog.Info("Бот запущен в режиме webhook")
+		if cfg.WebhookSecret == "" {
+			slog.Warn("WEBHOOK_SECRET не задан: заголовок X-Telegram-Bot-Api-Secret-Token не проверяется")
+		}
+		runErr = webhookServer.Start(ctx)
+	} else {
+		slog.Info("Бот запущен в режиме long polling")
+		poller.Run(ctx)
+	}
 
-		// Останавливаем планировщик
-		newsScheduler.Stop()
+	// Цикл обновлений мог завершиться с ошибкой без отмены ctx (например, порт webhook занят),
+	// поэтому отменяем его явно, иначе остальные серверы не остановятся
+	slog.Info("Останавливаем сервисы")
+	stop()
+	serversDone.Wait()
+	// Обновления больше не поступают: прерываем запущенную рассылку и дожидаемся ее
+	handler.Stop()
+
+	// Останавливаем планировщик: Stop прерывает текущий цикл и дожидается его завершения,
+	// поэтому после него базу данных можно закрыть
+	newsScheduler.Stop()
+	// Запросы к провайдерам больше не выполняются: сохраняем расход квот, накопленный в памяти
+	if err := providerQuota.Flush(context.Background()); err != nil {
+		slog.Error("Ошибка сохранения квот провайдеров", "error", err)
+	}
+	if err := dbConn.Close(); err != nil {
+		slog.Error("Ошибка закрытия базы данных", "error", err)
+	}
 
-		// Аккуратно останавливаем получение новых сообщений.
-		bot.StopReceivingUpdates()
-		log.Println("Бот успешно остановлен.")
+	if runErr != nil {
+		fatal("Бот остановлен с ошибкой", runErr)
 	}
+	slog.Info("Бот успешно остановлен")
+}
+
+// fatal записывает ошибку в лог и завершает процесс.
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
@@ -4,21 +4,47 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 // Config хранит все конфигурационные параметры для бота.
 type Config struct {
-	Token       string
-	GNewsAPIKey string
-	NewsAPIKey  string
-	DBPath      string
-	Mode        string
-	WebhookURL  string
-	Port        string
-	TLSCertPath string
-	TLSKeyPath  string
+	Token         string
+	GNewsAPIKey   string
+	NewsAPIKey    string
+	DBPath        string
+	Mode          string
+	WebhookURL    string
+	Port          string
+	TLSCertPath   string
+	TLSKeyPath    string
+	WebhookSecret string   // Секрет для заголовка X-Telegram-Bot-Api-Secret-Token
+	Providers     []string // Порядок и состав провайдеров новостей
+	RSSFeeds      []string // Адреса RSS/Atom лент для провайдера rss
+	AdminIDs      []int64  // Telegram ID администраторов бота
+
+	LogLevel  string // Уровень логирования: debug, info, warn или error
+	LogFormat string // Формат логов: text или json
+
+	ObservabilityAddr string // Адрес сервера метрик; пустая строка отключает сервер
+
+	AdminAPIAddr  string // Адрес административного API
+	AdminAPIToken string // Токен административного API; пустая строка отключает API
+
+	CacheTTL     time.Duration // Время жизни закэшированных результатов поиска
+	CacheSize    int           // Максимальное количество запросов в кэше в памяти
+	CachePersist bool          // Сохранять кэш в базе данных
+
+	GNewsDailyQuota   int // Дневной лимит запросов к GNews; 0 - без ограничения
+	NewsAPIDailyQuota int // Дневной лимит запросов к News API; 0 - без ограничения
+
+	SchedulerWorkers      int           // Сколько пользователей планировщик обрабатывает одновременно
+	SchedulerBatchSize    int           // Сколько пользователей читается из БД за один запрос
+	SchedulerCycleTimeout time.Duration // Максимальная длительность цикла рассылки
 }
 
 // Load загружает конфигурацию из .env файла и флагов командной строки.
@@ -35,24 +61,182 @@ func Load() (*Config, error) {
 	defaultNewsAPIKey := os.Getenv("NEWS_API_KEY")
 	defaultDBPath := "data/bot.db"
 	defaultMode := "polling"
+	defaultProviders := getEnv("NEWS_PROVIDERS", "gnews,newsapi")
+	defaultCacheTTL, err := getEnvDuration("CACHE_TTL", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+	defaultCacheSize, err := getEnvInt("CACHE_SIZE", 256)
+	if err != nil {
+		return nil, err
+	}
+	defaultCachePersist, err := getEnvBool("CACHE_PERSIST", false)
+	if err != nil {
+		return nil, err
+	}
+	// Лимиты бесплатных тарифов: 100 запросов в сутки у GNews и у News API
+	defaultGNewsDailyQuota, err := getEnvInt("GNEWS_DAILY_QUOTA", 100)
+	if err != nil {
+		return nil, err
+	}
+	defaultNewsAPIDailyQuota, err := getEnvInt("NEWSAPI_DAILY_QUOTA", 100)
+	if err != nil {
+		return nil, err
+	}
+	defaultSchedulerWorkers, err := getEnvInt("SCHEDULER_WORKERS", 8)
+	if err != nil {
+		return nil, err
+	}
+	defaultSchedulerBatchSize, err := getEnvInt("SCHEDULER_BATCH_SIZE", 100)
+	if err != nil {
+		return nil, err
+	}
+	defaultSchedulerCycleTimeout, err := getEnvDuration("SCHEDULER_CYCLE_TIMEOUT", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
 
 	// Определяем флаги командной строки
 	flag.StringVar(&cfg.Token, "token", defaultToken, "Telegram Bot Token")
 	flag.StringVar(&cfg.GNewsAPIKey, "gnews-api-key", defaultGNewsAPIKey, "GNews API Key")
 	flag.StringVar(&cfg.NewsAPIKey, "news-api-key", defaultNewsAPIKey, "News API Key")
 	flag.StringVar(&cfg.DBPath, "db-path", defaultDBPath, "Path to SQLite database file")
-	flag.StringVar(&cfg.Mode, "mode", defaultMode, "Bot mode (polling or webhook)")
-	flag.StringVar(&cfg.WebhookURL, "webhook-url", "", "Webhook URL for webhook mode")
-	flag.StringVar(&cfg.Port, "port", "8443", "Port for webhook server")
-	flag.StringVar(&cfg.TLSCertPath, "tls-cert-path", "", "Path to TLS certificate file")
-	flag.StringVar(&cfg.TLSKeyPath, "tls-key-path", "", "Path to TLS key file")
+	flag.StringVar(&cfg.Mode, "mode", getEnv("BOT_MODE", defaultMode), "Bot mode (polling or webhook)")
+	flag.StringVar(&cfg.WebhookURL, "webhook-url", os.Getenv("WEBHOOK_URL"), "Webhook URL for webhook mode")
+	flag.StringVar(&cfg.Port, "port", getEnv("WEBHOOK_PORT", "8443"), "Port for webhook server")
+	flag.StringVar(&cfg.TLSCertPath, "tls-cert-path", os.Getenv("TLS_CERT_PATH"), "Path to TLS certificate file")
+	flag.StringVar(&cfg.TLSKeyPath, "tls-key-path", os.Getenv("TLS_KEY_PATH"), "Path to TLS key file")
+	flag.StringVar(&cfg.WebhookSecret, "webhook-secret", os.Getenv("WEBHOOK_SECRET"), "Secret token Telegram sends in the X-Telegram-Bot-Api-Secret-Token header")
+	providers := flag.String("providers", defaultProviders, "Comma-separated list of news providers in fallback order")
+	rssFeeds := flag.String("rss-feeds", os.Getenv("RSS_FEEDS"), "Comma-separated list of RSS/Atom feed URLs")
+	adminIDs := flag.String("admin-ids", os.Getenv("ADMIN_IDS"), "Comma-separated list of Telegram IDs of bot administrators")
+	flag.StringVar(&cfg.LogLevel, "log-level", getEnv("LOG_LEVEL", "info"), "Log level: debug, info, warn or error")
+	flag.StringVar(&cfg.LogFormat, "log-format", getEnv("LOG_FORMAT", "text"), "Log format: text or json")
+	flag.StringVar(&cfg.ObservabilityAddr, "observability-addr", getEnv("OBSERVABILITY_ADDR", ":9090"), "Listen address of the metrics server (empty disables it)")
+	flag.StringVar(&cfg.AdminAPIAddr, "admin-api-addr", getEnv("ADMIN_API_ADDR", "127.0.0.1:8081"), "Listen address of the admin HTTP API")
+	flag.StringVar(&cfg.AdminAPIToken, "admin-api-token", os.Getenv("ADMIN_API_TOKEN"), "Bearer token of the admin HTTP API (empty disables the API)")
+	flag.DurationVar(&cfg.CacheTTL, "cache-ttl", defaultCacheTTL, "TTL of cached news search results (0 disables the cache)")
+	flag.IntVar(&cfg.CacheSize, "cache-size", defaultCacheSize, "Maximum number of queries kept in the in-memory cache")
+	flag.BoolVar(&cfg.CachePersist, "cache-persist", defaultCachePersist, "Persist cached search results in the database")
+	flag.IntVar(&cfg.GNewsDailyQuota, "gnews-daily-quota", defaultGNewsDailyQuota, "Daily request quota for GNews API (0 disables the limit)")
+	flag.IntVar(&cfg.NewsAPIDailyQuota, "newsapi-daily-quota", defaultNewsAPIDailyQuota, "Daily request quota for News API (0 disables the limit)")
+	flag.IntVar(&cfg.SchedulerWorkers, "scheduler-workers", defaultSchedulerWorkers, "Number of users processed concurrently by the scheduler")
+	flag.IntVar(&cfg.SchedulerBatchSize, "scheduler-batch-size", defaultSchedulerBatchSize, "Number of users loaded from the database per page")
+	flag.DurationVar(&cfg.SchedulerCycleTimeout, "scheduler-cycle-timeout", defaultSchedulerCycleTimeout, "Maximum duration of a single scheduler cycle")
 
 	flag.Parse()
 
+	cfg.Providers = splitList(*providers)
+	cfg.RSSFeeds = splitList(*rssFeeds)
+	for _, item := range splitList(*adminIDs) {
+		id, err := strconv.ParseInt(item, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("некорректный ID администратора %q в ADMIN_IDS: %w", item, err)
+		}
+		cfg.AdminIDs = append(cfg.AdminIDs, id)
+	}
+
 	// Если токен все еще пуст после всех проверок, это ошибка
 	if cfg.Token == "" {
 		return nil, fmt.Errorf("токен бота не указан. Укажите его через флаг -token или в .env файле")
 	}
 
+	switch cfg.Mode {
+	case "polling":
+	case "webhook":
+		if cfg.WebhookURL == "" {
+			return nil, fmt.Errorf("для режима webhook укажите адрес через флаг -webhook-url или WEBHOOK_URL")
+		}
+		if !validWebhookSecret(cfg.WebhookSecret) {
+			return nil, fmt.Errorf("некорректный WEBHOOK_SECRET: допускается от 1 до 256 символов A-Z, a-z, 0-9, _ и -")
+		}
+	default:
+		return nil, fmt.Errorf("неизвестный режим работы %q, ожидается polling или webhook", cfg.Mode)
+	}
+
+	if cfg.AdminAPIToken != "" && len(cfg.AdminAPIToken) < minAdminAPITokenLength {
+		return nil, fmt.Errorf("ADMIN_API_TOKEN должен содержать не меньше %d символов", minAdminAPITokenLength)
+	}
+
 	return &cfg, nil
 }
+
+// minAdminAPITokenLength - минимальная длина токена административного API.
+const minAdminAPITokenLength = 16
+
+// Secrets возвращает значения конфигурации, которые не должны попадать в логи.
+func (c *Config) Secrets() []string {
+	return []string{c.Token, c.GNewsAPIKey, c.NewsAPIKey, c.WebhookSecret, c.AdminAPIToken}
+}
+
+// getEnv возвращает значение переменной окружения или значение по умолчанию.
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
+// getEnvDuration читает длительность из переменной окружения, например "15m".
+func getEnvDuration(key string, fallback time.Duration) (time.Duration, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("некорректное значение %s: %w", key, err)
+	}
+	return d, nil
+}
+
+// getEnvInt читает целое число из переменной окружения.
+func getEnvInt(key string, fallback int) (int, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("некорректное значение %s: %w", key, err)
+	}
+	return n, nil
+}
+
+// getEnvBool читает логическое значение из переменной окружения.
+func getEnvBool(key string, fallback bool) (bool, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("некорректное значение %s: %w", key, err)
+	}
+	return b, nil
+}
+
+// validWebhookSecret проверяет секрет вебхука по правилам Telegram. Пустой секрет допустим:
+// в этом случае заголовок не проверяется.
+func validWebhookSecret(secret string) bool {
+	if len(secret) > 256 {
+		return false
+	}
+	for _, r := range secret {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// splitList разбивает строку со списком значений через запятую.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
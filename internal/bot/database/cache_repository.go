@@ -0,0 +1,69 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CachedResult хранит закэшированный ответ провайдеров новостей по запросу.
+type CachedResult struct {
+	gorm.Model
+	Key       string    `gorm:"size:512;uniqueIndex;not null"`
+	Payload   string    `gorm:"type:text;not null"`
+	FetchedAt time.Time `gorm:"not null"`
+}
+
+// cacheRepository реализует интерфейс CacheRepository.
+type cacheRepository struct {
+	db *gorm.DB
+}
+
+// NewCacheRepository создает новый репозиторий кэша результатов поиска.
+func NewCacheRepository(db *gorm.DB) CacheRepository {
+	return &cacheRepository{db: db}
+}
+
+// LoadCachedResult возвращает сохраненный ответ по ключу запроса.
+func (r *cacheRepository) LoadCachedResult(ctx context.Context, key string) ([]byte, time.Time, bool, error) {
+	var result CachedResult
+	if err := r.db.WithContext(ctx).Where("key = ?", key).First(&result).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, time.Time{}, false, nil
+		}
+		return nil, time.Time{}, false, fmt.Errorf("failed to load cached result: %w", err)
+	}
+	return []byte(result.Payload), result.FetchedAt, true, nil
+}
+
+// SaveCachedResult сохраняет ответ по ключу запроса, заменяя предыдущий.
+func (r *cacheRepository) SaveCachedResult(ctx context.Context, key string, payload []byte, fetchedAt time.Time) error {
+	result := CachedResult{
+		Key:       key,
+		Payload:   string(payload),
+		FetchedAt: fetchedAt,
+	}
+
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"payload", "fetched_at", "updated_at"}),
+	}).Create(&result).Error
+	if err != nil {
+		return fmt.Errorf("failed to save cached result: %w", err)
+	}
+	return nil
+}
+
+// DeleteCachedResultsBefore удаляет ответы, полученные раньше before.
+// Возвращает количество удаленных записей.
+func (r *cacheRepository) DeleteCachedResultsBefore(ctx context.Context, before time.Time) (int64, error) {
+	tx := r.db.WithContext(ctx).Unscoped().Where("fetched_at < ?", before).Delete(&CachedResult{})
+	if tx.Error != nil {
+		return 0, fmt.Errorf("failed to delete expired cached results: %w", tx.Error)
+	}
+	return tx.RowsAffected, nil
+}
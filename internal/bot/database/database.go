@@ -4,7 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -20,18 +20,46 @@ type database struct {
 	SubscriptionRepository
 	SentArticleRepository
 	FavoriteArticleRepository
+	CacheRepository
+	OutboxRepository
+	DigestRepository
+	TopicAliasRepository
+	ProviderQuotaRepository
+	StatsRepository
 	db *gorm.DB
 }
 
 // Ensure database implements Database interface.
 var _ Database = (*database)(nil)
 
+// ErrNotFound возвращается, если запрошенная запись не существует.
+// Методы оборачивают его, поэтому проверять нужно через errors.Is.
+var ErrNotFound = gorm.ErrRecordNotFound
+
+// ErrSubscriptionExists возвращается, если пользователь уже подписан на тему.
+var ErrSubscriptionExists = errors.New("подписка на эту тему уже существует")
+
 const (
 	MaxTopicLength    = 255
 	MaxUsernameLength = 64
 	MaxNameLength     = 64
 )
 
+const (
+	// DeliveryModeInstant - каждая новость отправляется отдельным сообщением.
+	DeliveryModeInstant = "instant"
+	// DeliveryModeDigest - новости накапливаются и отправляются одной сводкой.
+	DeliveryModeDigest = "digest"
+
+	// DigestPeriodDaily - сводка отправляется каждый день.
+	DigestPeriodDaily = "daily"
+	// DigestPeriodWeekly - сводка отправляется раз в неделю, по понедельникам.
+	DigestPeriodWeekly = "weekly"
+
+	// DefaultDigestTime - время отправки сводки по умолчанию.
+	DefaultDigestTime = "09:00"
+)
+
 // User представляет пользователя бота.
 type User struct {
 	gorm.Model
@@ -42,22 +70,42 @@ type User struct {
 	State                       string `gorm:"default:''"`
 	NotificationIntervalMinutes uint   `gorm:"default:60"`
 	LastNotifiedAt              *time.Time
-	NewsLimit                   uint           `gorm:"default:5"` // Количество новостей для получения, по умолчанию 5
+	NewsLimit                   uint   `gorm:"default:5"` // Количество новостей для получения, по умолчанию 5
+	DeliveryMode                string `gorm:"size:16;default:'instant'"`
+	DigestPeriod                string `gorm:"size:16;default:'daily'"`
+	DigestTime                  string `gorm:"size:5;default:'09:00'"` // Местное время отправки сводки в формате ЧЧ:ММ
+	LastDigestAt                *time.Time
+	TimeZone                    string         `gorm:"size:64;default:''"`  // Часовой пояс IANA; пустое значение - часовой пояс сервера
+	QuietHoursStart             string         `gorm:"size:5;default:''"`   // Начало тихих часов (ЧЧ:ММ); пустое значение отключает их
+	QuietHoursEnd               string         `gorm:"size:5;default:''"`   // Окончание тихих часов (ЧЧ:ММ)
+	Schedule                    string         `gorm:"size:128;default:''"` // Расписание в формате cron; если задано, заменяет интервал
 	Subscriptions               []Subscription `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
 }
 
 // Subscription представляет подписку пользователя на тему.
 type Subscription struct {
 	gorm.Model
-	UserID uint   `gorm:"index;not null"`
-	Topic  string `gorm:"size:255;not null"`
+	UserID               uint   `gorm:"index;not null"`
+	Topic                string `gorm:"size:255;not null"`
+	SubscriptionSettings `gorm:"embedded"`
+	LastNotifiedAt       *time.Time // Время последней обработки темы с собственным интервалом
+}
+
+// SubscriptionSettings - собственные настройки темы. Нулевые значения означают,
+// что используются общие настройки пользователя.
+type SubscriptionSettings struct {
+	IntervalMinutes uint   `gorm:"default:0"`              // Интервал проверки темы
+	MaxArticles     uint   `gorm:"default:0"`              // Максимум новостей по теме за раз
+	Language        string `gorm:"size:8;default:''"`      // Язык новостей
+	Country         string `gorm:"size:8;default:''"`      // Страна источников
+	Muted           bool   `gorm:"not null;default:false"` // Уведомления по теме выключены
 }
 
 // SentArticle отслеживает отправленные статьи.
 type SentArticle struct {
 	gorm.Model
 	UserID      uint   `gorm:"not null;index"`
-	ArticleHash string `gorm:"not null;index"`
+	ArticleHash string `gorm:"not null;index"` // Ключ статьи: SHA-256 канонического адреса
 	SentAt      time.Time
 }
 
@@ -65,6 +113,7 @@ type SentArticle struct {
 type FavoriteArticle struct {
 	gorm.Model
 	UserID      uint      `gorm:"not null;index"`
+	ArticleKey  string    `gorm:"size:64;index"` // SHA-256 канонического адреса статьи
 	ArticleURL  string    `gorm:"not null;index"`
 	Title       string    `gorm:"not null"`
 	Source      string    `gorm:"not null"`
@@ -78,13 +127,18 @@ func New(dbPath string) (Database, error) {
 		return nil, fmt.Errorf("failed to create database directory: %w", err)
 	}
 
+	// Запросы GORM пишутся в общий лог: все SQL-запросы - только на уровне debug,
+	// иначе медленные запросы и ошибки на уровне warn
+	gormLevel, slogLevel := logger.Warn, slog.LevelWarn
+	if slog.Default().Enabled(context.Background(), slog.LevelDebug) {
+		gormLevel, slogLevel = logger.Info, slog.LevelDebug
+	}
 	newLogger := logger.New(
-		log.New(os.Stdout, "\r\n", log.LstdFlags),
+		slog.NewLogLogger(slog.Default().Handler(), slogLevel),
 		logger.Config{
 			SlowThreshold:             time.Second,
-			LogLevel:                  logger.Info,
+			LogLevel:                  gormLevel,
 			IgnoreRecordNotFoundError: true,
-			Colorful:                  true,
 		},
 	)
 
@@ -105,17 +159,26 @@ func New(dbPath string) (Database, error) {
 	sqlDB.SetMaxOpenConns(100)
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
-	if err = db.AutoMigrate(&User{}, &Subscription{}, &SentArticle{}, &FavoriteArticle{}); err != nil {
+	if err = db.AutoMigrate(&User{}, &Subscription{}, &SentArticle{}, &FavoriteArticle{}, &CachedResult{}, &OutboxMessage{}, &DigestItem{}, &ProviderQuota{}); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
+	if err = migrateTopicAliases(db); err != nil {
+		return nil, fmt.Errorf("failed to migrate topic aliases: %w", err)
+	}
 
-	log.Println("Database connection and migration successful.")
+	slog.Info("Database connection and migration successful", "path", dbPath)
 
 	return &database{
 		UserRepository:            NewUserRepository(db),
 		SubscriptionRepository:    NewSubscriptionRepository(db),
 		SentArticleRepository:     NewSentArticleRepository(db),
 		FavoriteArticleRepository: NewFavoriteArticleRepository(db),
+		CacheRepository:           NewCacheRepository(db),
+		OutboxRepository:          NewOutboxRepository(db),
+		DigestRepository:          NewDigestRepository(db),
+		TopicAliasRepository:      NewTopicAliasRepository(db),
+		ProviderQuotaRepository:   NewProviderQuotaRepository(db),
+		StatsRepository:           NewStatsRepository(db),
 		db:                        db,
 	}, nil
 }
@@ -174,6 +237,69 @@ func (r *userRepository) GetAllUsers(ctx context.Context) ([]User, error) {
 	return users, nil
 }
 
+// GetUsersBatch возвращает не более limit пользователей с ID больше afterID в порядке
+// возрастания ID. Используется для постраничного обхода пользователей по курсору.
+func (r *userRepository) GetUsersBatch(ctx context.Context, afterID uint, limit int) ([]User, error) {
+	var users []User
+	if err := r.db.WithContext(ctx).Where("id > ?", afterID).Order("id").Limit(limit).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to get users batch: %w", err)
+	}
+	return users, nil
+}
+
+// GetUserByTelegramID возвращает пользователя по Telegram ID или ошибку ErrNotFound.
+func (r *userRepository) GetUserByTelegramID(ctx context.Context, telegramID int64) (*User, error) {
+	var user User
+	if err := r.db.WithContext(ctx).Where("telegram_id = ?", telegramID).First(&user).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return &user, nil
+}
+
+// likeEscaper экранирует спецсимволы шаблона LIKE.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// duplicatesCondition проверяет, входит ли ключ статьи в список Duplicates;
+// шаблон для него строит duplicatesPattern.
+const duplicatesCondition = `',' || duplicates || ',' LIKE ? ESCAPE '\'`
+
+// duplicatesPattern возвращает шаблон LIKE для поиска ключа в списке Duplicates.
+func duplicatesPattern(articleHash string) string {
+	return "%," + likeEscaper.Replace(articleHash) + ",%"
+}
+
+// JoinDuplicates собирает ключи похожих статей в значение поля Duplicates.
+func JoinDuplicates(keys []string) string {
+	return strings.Join(keys, ",")
+}
+
+// SplitDuplicates возвращает ключи похожих статей из значения поля Duplicates.
+func SplitDuplicates(duplicates string) []string {
+	if duplicates == "" {
+		return nil
+	}
+	return strings.Split(duplicates, ",")
+}
+
+// SearchUsers возвращает не более limit пользователей с ID больше afterID, у которых имя
+// пользователя, имя или фамилия содержат query либо Telegram ID совпадает с query.
+// Пустой query возвращает всех пользователей.
+func (r *userRepository) SearchUsers(ctx context.Context, query string, afterID uint, limit int) ([]User, error) {
+	tx := r.db.WithContext(ctx).Where("id > ?", afterID)
+	if query = strings.TrimSpace(query); query != "" {
+		// LIKE в SQLite не различает регистр только для латиницы
+		pattern := "%" + likeEscaper.Replace(query) + "%"
+		tx = tx.Where(`username LIKE ? ESCAPE '\' OR first_name LIKE ? ESCAPE '\' OR last_name LIKE ? ESCAPE '\' OR CAST(telegram_id AS TEXT) = ?`,
+			pattern, pattern, pattern, query)
+	}
+
+	var users []User
+	if err := tx.Order("id").Limit(limit).Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+	return users, nil
+}
+
 func (r *userRepository) UpdateUserLastNotifiedAt(ctx context.Context, userID uint, notifyTime time.Time) error {
 	return r.db.WithContext(ctx).Model(&User{}).Where("id = ?", userID).Update("last_notified_at", notifyTime).Error
 }
@@ -186,6 +312,48 @@ func (r *userRepository) UpdateUserNewsLimit(ctx context.Context, userID uint, n
 	return r.db.WithContext(ctx).Model(&User{}).Where("id = ?", userID).Update("news_limit", newsLimit).Error
 }
 
+// UpdateUserDeliveryMode меняет режим доставки новостей. При включении сводки отсчет
+// периода начинается с текущего момента, чтобы первая сводка не ушла сразу.
+func (r *userRepository) UpdateUserDeliveryMode(ctx context.Context, userID uint, mode, digestPeriod string) error {
+	updates := map[string]interface{}{"delivery_mode": mode}
+	if mode == DeliveryModeDigest {
+		updates["digest_period"] = digestPeriod
+		updates["last_digest_at"] = time.Now()
+	}
+	return r.db.WithContext(ctx).Model(&User{}).Where("id = ?", userID).Updates(updates).Error
+}
+
+// UpdateUserDigestTime меняет местное время отправки сводки (формат ЧЧ:ММ).
+func (r *userRepository) UpdateUserDigestTime(ctx context.Context, userID uint, digestTime string) error {
+	return r.db.WithContext(ctx).Model(&User{}).Where("id = ?", userID).Update("digest_time", digestTime).Error
+}
+
+// UpdateUserLastDigestAt фиксирует время отправки последней сводки.
+func (r *userRepository) UpdateUserLastDigestAt(ctx context.Context, userID uint, digestTime time.Time) error {
+	return r.db.WithContext(ctx).Model(&User{}).Where("id = ?", userID).Update("last_digest_at", digestTime).Error
+}
+
+// UpdateUserTimeZone меняет часовой пояс пользователя.
+func (r *userRepository) UpdateUserTimeZone(ctx context.Context, userID uint, timeZone string) error {
+	return r.db.WithContext(ctx).Model(&User{}).Where("id = ?", userID).Update("time_zone", timeZone).Error
+}
+
+// UpdateUserSchedule меняет расписание уведомлений. Пустое значение возвращает интервал.
+// Отсчет нового расписания начинается с текущего момента.
+func (r *userRepository) UpdateUserSchedule(ctx context.Context, userID uint, schedule string) error {
+	updates := map[string]interface{}{"schedule": schedule}
+	if schedule != "" {
+		updates["last_notified_at"] = time.Now()
+	}
+	return r.db.WithContext(ctx).Model(&User{}).Where("id = ?", userID).Updates(updates).Error
+}
+
+// UpdateUserQuietHours меняет окно тихих часов. Пустые значения отключают тихие часы.
+func (r *userRepository) UpdateUserQuietHours(ctx context.Context, userID uint, start, end string) error {
+	return r.db.WithContext(ctx).Model(&User{}).Where("id = ?", userID).
+		Updates(map[string]interface{}{"quiet_hours_start": start, "quiet_hours_end": end}).Error
+}
+
 func (r *userRepository) GetUserState(ctx context.Context, userID uint) (string, error) {
 	var user User
 	if err := r.db.WithContext(ctx).Select("state").First(&user, userID).Error; err != nil {
@@ -216,7 +384,7 @@ func (r *subscriptionRepository) AddSubscription(ctx context.Context, userID uin
 	var count int64
 	r.db.WithContext(ctx).Model(&Subscription{}).Where("user_id = ? AND topic = ?", userID, subscription.Topic).Count(&count)
 	if count > 0 {
-		return errors.New("подписка на эту тему уже существует")
+		return ErrSubscriptionExists
 	}
 
 	return r.db.WithContext(ctx).Create(&subscription).Error
@@ -242,6 +410,92 @@ func (r *subscriptionRepository) GetUserSubscriptions(ctx context.Context, userI
 	return topics, nil
 }
 
+// GetUserSubscriptionDetails возвращает подписки пользователя вместе с их настройками.
+func (r *subscriptionRepository) GetUserSubscriptionDetails(ctx context.Context, userID uint) ([]Subscription, error) {
+	var subscriptions []Subscription
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&subscriptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+// GetSubscriptionsForUsers возвращает подписки нескольких пользователей одним запросом.
+func (r *subscriptionRepository) GetSubscriptionsForUsers(ctx context.Context, userIDs []uint) ([]Subscription, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	var subscriptions []Subscription
+	if err := r.db.WithContext(ctx).Where("user_id IN ?", userIDs).Order("id").Find(&subscriptions).Error; err != nil {
+		return nil, fmt.Errorf("failed to get subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+// GetSubscription возвращает подписку пользователя по ее ID.
+func (r *subscriptionRepository) GetSubscription(ctx context.Context, userID, subscriptionID uint) (*Subscription, error) {
+	var subscription Subscription
+	if err := r.db.WithContext(ctx).Where("id = ? AND user_id = ?", subscriptionID, userID).First(&subscription).Error; err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	return &subscription, nil
+}
+
+// UpdateSubscriptionSettings сохраняет настройки подписки пользователя целиком.
+func (r *subscriptionRepository) UpdateSubscriptionSettings(ctx context.Context, userID, subscriptionID uint, settings SubscriptionSettings) error {
+	tx := r.db.WithContext(ctx).Model(&Subscription{}).
+		Where("id = ? AND user_id = ?", subscriptionID, userID).
+		Updates(map[string]interface{}{
+			"interval_minutes": settings.IntervalMinutes,
+			"max_articles":     settings.MaxArticles,
+			"language":         settings.Language,
+			"country":          settings.Country,
+			"muted":            settings.Muted,
+		})
+	if tx.Error != nil {
+		return fmt.Errorf("failed to update subscription settings: %w", tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		return errors.New("subscription not found")
+	}
+	return nil
+}
+
+// UpdateSubscriptionTopic переименовывает тему подписки, сохраняя ее настройки.
+// Если пользователь уже подписан на новую тему, возвращается ErrSubscriptionExists.
+func (r *subscriptionRepository) UpdateSubscriptionTopic(ctx context.Context, userID, subscriptionID uint, topic string) error {
+	topic = strings.ToLower(topic)
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&Subscription{}).Where("user_id = ? AND topic = ? AND id <> ?", userID, topic, subscriptionID).Count(&count).Error; err != nil {
+			return fmt.Errorf("failed to check subscription: %w", err)
+		}
+		if count > 0 {
+			return ErrSubscriptionExists
+		}
+
+		result := tx.Model(&Subscription{}).Where("id = ? AND user_id = ?", subscriptionID, userID).Update("topic", topic)
+		if result.Error != nil {
+			return fmt.Errorf("failed to update subscription topic: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("subscription not found")
+		}
+		return nil
+	})
+}
+
+// UpdateSubscriptionsLastNotifiedAt фиксирует время обработки подписок.
+func (r *subscriptionRepository) UpdateSubscriptionsLastNotifiedAt(ctx context.Context, subscriptionIDs []uint, notifyTime time.Time) error {
+	if len(subscriptionIDs) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).Model(&Subscription{}).Where("id IN ?", subscriptionIDs).Update("last_notified_at", notifyTime).Error
+	if err != nil {
+		return fmt.Errorf("failed to update subscriptions: %w", err)
+	}
+	return nil
+}
+
 func (r *subscriptionRepository) GetAllUniqueTopics(ctx context.Context) ([]string, error) {
 	var topics []string
 	err := r.db.WithContext(ctx).Model(&Subscription{}).Distinct().Pluck("topic", &topics).Error
@@ -303,7 +557,7 @@ func (r *sentArticleRepository) MarkArticleAsSent(ctx context.Context, userID ui
 // MigrateSubscriptionsToLower конвертирует все темы подписок в нижний регистр для обеспечения
 // регистронезависимого поиска и сравнения.
 func MigrateSubscriptionsToLower(db *gorm.DB) error {
-	log.Println("Запуск миграции подписок к нижнему регистру...")
+	slog.Debug("Запуск миграции подписок к нижнему регистру")
 
 	var subscriptions []Subscription
 	if err := db.Find(&subscriptions).Error; err != nil {
@@ -313,13 +567,54 @@ func MigrateSubscriptionsToLower(db *gorm.DB) error {
 	for _, sub := range subscriptions {
 		lowerTopic := strings.ToLower(sub.Topic)
 		if sub.Topic != lowerTopic {
-			log.Printf("Миграция подписки ID %d: '%s' -> '%s'", sub.ID, sub.Topic, lowerTopic)
+			slog.Info("Миграция подписки", "subscription_id", sub.ID, "from", sub.Topic, "to", lowerTopic)
 			if err := db.Model(&sub).Update("topic", lowerTopic).Error; err != nil {
 				return fmt.Errorf("failed to update subscription %d: %w", sub.ID, err)
 			}
 		}
 	}
 
-	log.Println("Миграция подписок завершена успешно.")
+	slog.Debug("Миграция подписок завершена успешно")
 	return nil
 }
+
+// MigrateArticleHashes заменяет адреса статей, которые раньше хранились в качестве
+// ключа, на ключи фиксированной длины. Функция key вычисляет ключ по адресу
+// (fetcher.ArticleKey); ее передает вызывающий код, чтобы пакет не зависел от fetcher.
+// Уже перенесенные записи не содержат "://" и пропускаются, поэтому миграцию
+// можно запускать при каждом старте.
+func MigrateArticleHashes(db *gorm.DB, key func(articleURL string) string) error {
+	for _, table := range []string{"sent_articles", "outbox_messages", "digest_items"} {
+		if err := migrateArticleColumn(db, table, "article_hash", "article_hash", "article_hash LIKE ?", "%://%", key); err != nil {
+			return err
+		}
+	}
+	return migrateArticleColumn(db, "favorite_articles", "article_url", "article_key", "article_key IS NULL OR article_key = ?", "", key)
+}
+
+// migrateArticleColumn вычисляет ключ по колонке source и записывает его в target
+// для записей таблицы, подходящих под условие where.
+func migrateArticleColumn(db *gorm.DB, table, source, target, where string, arg interface{}, key func(string) string) error {
+	type row struct {
+		ID    uint
+		Value string
+	}
+
+	var rows []row
+	if err := db.Table(table).Select("id, "+source+" AS value").Where(where, arg).Scan(&rows).Error; err != nil {
+		return fmt.Errorf("failed to load article hashes from %s: %w", table, err)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	slog.Info("Миграция ключей статей", "table", table, "rows", len(rows))
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, r := range rows {
+			if err := tx.Table(table).Where("id = ?", r.ID).UpdateColumn(target, key(r.Value)).Error; err != nil {
+				return fmt.Errorf("failed to migrate article hash in %s: %w", table, err)
+			}
+		}
+		return nil
+	})
+}
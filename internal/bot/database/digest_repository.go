@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// DigestItem представляет новость, отложенную до отправки сводки.
+type DigestItem struct {
+	gorm.Model
+	UserID      uint   `gorm:"not null;index"`
+	Topic       string `gorm:"size:255;not null"`
+	ArticleHash string `gorm:"not null;index"`
+	Payload     string `gorm:"type:text;not null"`
+	Duplicates  string `gorm:"type:text"` // Ключи объединенных с этой статей через запятую, см. JoinDuplicates
+}
+
+// digestRepository реализует интерфейс DigestRepository.
+type digestRepository struct {
+	db *gorm.DB
+}
+
+// NewDigestRepository создает новый репозиторий новостей для сводок.
+func NewDigestRepository(db *gorm.DB) DigestRepository {
+	return &digestRepository{db: db}
+}
+
+// AddDigestItem откладывает новость до следующей сводки. Если новость уже отложена
+// для пользователя, новая запись не создается и возвращается false.
+func (r *digestRepository) AddDigestItem(ctx context.Context, item *DigestItem) (bool, error) {
+	exists, err := r.IsArticleInDigest(ctx, item.UserID, item.ArticleHash)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+
+	if err := r.db.WithContext(ctx).Create(item).Error; err != nil {
+		return false, fmt.Errorf("failed to add digest item: %w", err)
+	}
+	return true, nil
+}
+
+// IsArticleInDigest проверяет, отложена ли новость для сводки пользователя сама
+// или как похожая на другую отложенную новость.
+func (r *digestRepository) IsArticleInDigest(ctx context.Context, userID uint, articleHash string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&DigestItem{}).
+		Where("user_id = ? AND (article_hash = ? OR "+duplicatesCondition+")", userID, articleHash, duplicatesPattern(articleHash)).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check digest items: %w", err)
+	}
+	return count > 0, nil
+}
+
+// GetDigestItems возвращает отложенные новости пользователя в порядке добавления.
+func (r *digestRepository) GetDigestItems(ctx context.Context, userID uint) ([]DigestItem, error) {
+	var items []DigestItem
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to get digest items: %w", err)
+	}
+	return items, nil
+}
+
+// DeleteDigestItems удаляет отправленные или устаревшие новости сводки.
+func (r *digestRepository) DeleteDigestItems(ctx context.Context, ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := r.db.WithContext(ctx).Unscoped().Delete(&DigestItem{}, ids).Error; err != nil {
+		return fmt.Errorf("failed to delete digest items: %w", err)
+	}
+	return nil
+}
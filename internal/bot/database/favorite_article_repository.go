@@ -20,11 +20,11 @@ func NewFavoriteArticleRepository(db *gorm.DB) FavoriteArticleRepository {
 }
 
 // AddFavoriteArticle добавляет статью в избранное пользователя.
-func (r *favoriteArticleRepository) AddFavoriteArticle(ctx context.Context, userID uint, articleURL string, title string, source string, publishedAt time.Time) error {
+func (r *favoriteArticleRepository) AddFavoriteArticle(ctx context.Context, userID uint, articleKey, articleURL string, title string, source string, publishedAt time.Time) error {
 	// Проверяем, не добавлена ли уже эта статья в избранное
 	var count int64
 	if err := r.db.WithContext(ctx).Model(&FavoriteArticle{}).
-		Where("user_id = ? AND article_url = ?", userID, articleURL).
+		Where("user_id = ? AND article_key = ?", userID, articleKey).
 		Count(&count).Error; err != nil {
 		return fmt.Errorf("failed to check if article is already in favorites: %w", err)
 	}
@@ -36,6 +36,7 @@ func (r *favoriteArticleRepository) AddFavoriteArticle(ctx context.Context, user
 	// Добавляем статью в избранное
 	favoriteArticle := FavoriteArticle{
 		UserID:      userID,
+		ArticleKey:  articleKey,
 		ArticleURL:  articleURL,
 		Title:       title,
 		Source:      source,
@@ -51,9 +52,9 @@ func (r *favoriteArticleRepository) AddFavoriteArticle(ctx context.Context, user
 }
 
 // RemoveFavoriteArticle удаляет статью из избранного пользователя.
-func (r *favoriteArticleRepository) RemoveFavoriteArticle(ctx context.Context, userID uint, articleURL string) error {
+func (r *favoriteArticleRepository) RemoveFavoriteArticle(ctx context.Context, userID uint, articleKey string) error {
 	result := r.db.WithContext(ctx).
-		Where("user_id = ? AND article_url = ?", userID, articleURL).
+		Where("user_id = ? AND article_key = ?", userID, articleKey).
 		Delete(&FavoriteArticle{})
 
 	if result.Error != nil {
@@ -81,10 +82,10 @@ func (r *favoriteArticleRepository) GetUserFavoriteArticles(ctx context.Context,
 }
 
 // IsFavoriteArticle проверяет, добавлена ли статья в избранное пользователя.
-func (r *favoriteArticleRepository) IsFavoriteArticle(ctx context.Context, userID uint, articleURL string) (bool, error) {
+func (r *favoriteArticleRepository) IsFavoriteArticle(ctx context.Context, userID uint, articleKey string) (bool, error) {
 	var count int64
 	if err := r.db.WithContext(ctx).Model(&FavoriteArticle{}).
-		Where("user_id = ? AND article_url = ?", userID, articleURL).
+		Where("user_id = ? AND article_key = ?", userID, articleKey).
 		Count(&count).Error; err != nil {
 		return false, fmt.Errorf("failed to check if article is in favorites: %w", err)
 	}
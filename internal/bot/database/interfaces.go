@@ -14,6 +14,12 @@ type Database interface {
 	SubscriptionRepository
 	SentArticleRepository
 	FavoriteArticleRepository
+	CacheRepository
+	OutboxRepository
+	DigestRepository
+	TopicAliasRepository
+	ProviderQuotaRepository
+	StatsRepository
 	Close() error
 	GetDB() *gorm.DB
 }
@@ -22,11 +28,20 @@ type Database interface {
 type UserRepository interface {
 	FindOrCreateUser(ctx context.Context, telegramID int64, username, firstName, lastName string) (*User, error)
 	GetAllUsers(ctx context.Context) ([]User, error)
+	GetUsersBatch(ctx context.Context, afterID uint, limit int) ([]User, error)
+	GetUserByTelegramID(ctx context.Context, telegramID int64) (*User, error)
+	SearchUsers(ctx context.Context, query string, afterID uint, limit int) ([]User, error)
 	SetUserState(ctx context.Context, userID uint, state string) error
 	GetUserState(ctx context.Context, userID uint) (string, error)
 	UpdateUserLastNotifiedAt(ctx context.Context, userID uint, notifyTime time.Time) error
 	UpdateUserNotificationInterval(ctx context.Context, userID uint, intervalMinutes uint) error
 	UpdateUserNewsLimit(ctx context.Context, userID uint, newsLimit uint) error
+	UpdateUserDeliveryMode(ctx context.Context, userID uint, mode, digestPeriod string) error
+	UpdateUserDigestTime(ctx context.Context, userID uint, digestTime string) error
+	UpdateUserLastDigestAt(ctx context.Context, userID uint, digestTime time.Time) error
+	UpdateUserTimeZone(ctx context.Context, userID uint, timeZone string) error
+	UpdateUserQuietHours(ctx context.Context, userID uint, start, end string) error
+	UpdateUserSchedule(ctx context.Context, userID uint, schedule string) error
 }
 
 // SubscriptionRepository определяет операции для работы с подписками.
@@ -34,6 +49,12 @@ type SubscriptionRepository interface {
 	AddSubscription(ctx context.Context, userID uint, topic string) error
 	RemoveSubscription(ctx context.Context, userID uint, topic string) error
 	GetUserSubscriptions(ctx context.Context, userID uint) ([]string, error)
+	GetUserSubscriptionDetails(ctx context.Context, userID uint) ([]Subscription, error)
+	GetSubscriptionsForUsers(ctx context.Context, userIDs []uint) ([]Subscription, error)
+	GetSubscription(ctx context.Context, userID, subscriptionID uint) (*Subscription, error)
+	UpdateSubscriptionSettings(ctx context.Context, userID, subscriptionID uint, settings SubscriptionSettings) error
+	UpdateSubscriptionTopic(ctx context.Context, userID, subscriptionID uint, topic string) error
+	UpdateSubscriptionsLastNotifiedAt(ctx context.Context, subscriptionIDs []uint, notifyTime time.Time) error
 	GetAllUniqueTopics(ctx context.Context) ([]string, error)
 	GetSubscribersForTopic(ctx context.Context, topic string) ([]int64, error)
 }
@@ -47,8 +68,57 @@ type SentArticleRepository interface {
 
 // FavoriteArticleRepository определяет операции для работы с избранными статьями.
 type FavoriteArticleRepository interface {
-	AddFavoriteArticle(ctx context.Context, userID uint, articleURL string, title string, source string, publishedAt time.Time) error
-	RemoveFavoriteArticle(ctx context.Context, userID uint, articleURL string) error
+	AddFavoriteArticle(ctx context.Context, userID uint, articleKey, articleURL string, title string, source string, publishedAt time.Time) error
+	RemoveFavoriteArticle(ctx context.Context, userID uint, articleKey string) error
 	GetUserFavoriteArticles(ctx context.Context, userID uint) ([]FavoriteArticle, error)
-	IsFavoriteArticle(ctx context.Context, userID uint, articleURL string) (bool, error)
+	IsFavoriteArticle(ctx context.Context, userID uint, articleKey string) (bool, error)
+}
+
+// CacheRepository определяет операции для постоянного хранения кэша результатов поиска.
+type CacheRepository interface {
+	LoadCachedResult(ctx context.Context, key string) (payload []byte, fetchedAt time.Time, found bool, err error)
+	SaveCachedResult(ctx context.Context, key string, payload []byte, fetchedAt time.Time) error
+	DeleteCachedResultsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// OutboxRepository определяет операции с очередью исходящих сообщений.
+type OutboxRepository interface {
+	EnqueueOutboxMessage(ctx context.Context, msg *OutboxMessage) (bool, error)
+	IsArticleQueued(ctx context.Context, userID uint, articleHash string) (bool, error)
+	GetDueOutboxMessages(ctx context.Context, userID uint, now time.Time, limit int) ([]OutboxMessage, error)
+	ClaimOutboxMessage(ctx context.Context, id uint, now, leaseUntil time.Time) (bool, error)
+	MarkOutboxMessageDelivered(ctx context.Context, id uint) error
+	MarkOutboxMessageFailed(ctx context.Context, id uint, lastError string, nextAttemptAt time.Time, giveUp bool) error
+	PruneFailedOutboxMessages(ctx context.Context, before time.Time) (int64, error)
+	CountPendingOutboxMessages(ctx context.Context) (int64, error)
+	ListOutboxMessages(ctx context.Context, filter OutboxFilter, limit int) ([]OutboxMessage, error)
+}
+
+// DigestRepository определяет операции с новостями, накопленными для сводки.
+type DigestRepository interface {
+	AddDigestItem(ctx context.Context, item *DigestItem) (bool, error)
+	IsArticleInDigest(ctx context.Context, userID uint, articleHash string) (bool, error)
+	GetDigestItems(ctx context.Context, userID uint) ([]DigestItem, error)
+	DeleteDigestItems(ctx context.Context, ids []uint) error
+}
+
+// TopicAliasRepository определяет операции с исправлениями и синонимами тем.
+type TopicAliasRepository interface {
+	AddTopicAlias(ctx context.Context, alias, topic, kind string) error
+	RemoveTopicAlias(ctx context.Context, alias string) error
+	GetTopicAliases(ctx context.Context) ([]TopicAlias, error)
+	LoadTopicAliases(ctx context.Context) (corrections map[string]string, synonyms map[string][]string, err error)
+}
+
+// ProviderQuotaRepository определяет операции для хранения дневных квот провайдеров новостей.
+type ProviderQuotaRepository interface {
+	LoadProviderQuota(ctx context.Context, provider, day string) (used int, exhausted bool, err error)
+	SaveProviderQuota(ctx context.Context, provider, day string, used int, exhausted bool) error
+}
+
+// StatsRepository определяет запросы статистики для администраторов.
+type StatsRepository interface {
+	GetUserStats(ctx context.Context, since time.Time) (UserStats, error)
+	GetTopTopics(ctx context.Context, limit int) ([]TopicStats, error)
+	CountSentArticlesByDay(ctx context.Context, since time.Time) ([]DailyCount, error)
 }
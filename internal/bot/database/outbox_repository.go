@@ -0,0 +1,183 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// OutboxStatusPending - сообщение ожидает доставки или повторной попытки.
+	OutboxStatusPending = "pending"
+	// OutboxStatusFailed - попытки доставки исчерпаны.
+	OutboxStatusFailed = "failed"
+)
+
+// OutboxMessage представляет сообщение, ожидающее доставки в Telegram.
+type OutboxMessage struct {
+	gorm.Model
+	UserID        uint      `gorm:"not null;index"`
+	ChatID        int64     `gorm:"not null"`
+	ArticleHash   string    `gorm:"not null;index"`
+	Topic         string    `gorm:"size:255"` // Тема подписки, по которой найдена статья
+	Payload       string    `gorm:"type:text;not null"`
+	Duplicates    string    `gorm:"type:text"` // Ключи объединенных с этой статей через запятую, см. JoinDuplicates
+	Status        string    `gorm:"size:16;not null;default:'pending';index"`
+	Attempts      uint      `gorm:"not null;default:0"`
+	NextAttemptAt time.Time `gorm:"not null;index"`
+	LastError     string    `gorm:"size:1024"`
+}
+
+// OutboxFilter ограничивает выборку сообщений очереди. Нулевые поля не фильтруют.
+type OutboxFilter struct {
+	Status string // OutboxStatusPending или OutboxStatusFailed
+	UserID uint
+}
+
+// outboxRepository реализует интерфейс OutboxRepository.
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository создает новый репозиторий очереди исходящих сообщений.
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// EnqueueOutboxMessage добавляет сообщение в очередь. Если для пользователя уже есть
+// сообщение с той же статьей, ожидающее или недоставленное окончательно, новое не
+// создается и возвращается false.
+func (r *outboxRepository) EnqueueOutboxMessage(ctx context.Context, msg *OutboxMessage) (bool, error) {
+	queued, err := r.IsArticleQueued(ctx, msg.UserID, msg.ArticleHash)
+	if err != nil {
+		return false, err
+	}
+	if queued {
+		return false, nil
+	}
+
+	msg.Status = OutboxStatusPending
+	if msg.NextAttemptAt.IsZero() {
+		msg.NextAttemptAt = time.Now()
+	}
+	if err := r.db.WithContext(ctx).Create(msg).Error; err != nil {
+		return false, fmt.Errorf("failed to enqueue outbox message: %w", err)
+	}
+	return true, nil
+}
+
+// IsArticleQueued проверяет, есть ли статья в очереди пользователя: ожидает доставки
+// или доставка не удалась окончательно. Такие статьи повторно в очередь не ставятся.
+// Статья, объединенная с ожидающим сообщением как похожая, тоже считается в очереди;
+// если доставить сообщение не удалось, она может быть отправлена отдельно.
+func (r *outboxRepository) IsArticleQueued(ctx context.Context, userID uint, articleHash string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&OutboxMessage{}).
+		Where("user_id = ?", userID).
+		Where(r.db.Where("article_hash = ? AND status IN ?", articleHash, []string{OutboxStatusPending, OutboxStatusFailed}).
+			Or("status = ? AND "+duplicatesCondition, OutboxStatusPending, duplicatesPattern(articleHash))).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check outbox: %w", err)
+	}
+	return count > 0, nil
+}
+
+// GetDueOutboxMessages возвращает ожидающие сообщения, время доставки которых наступило.
+// Если userID не равен 0, выборка ограничивается одним пользователем.
+func (r *outboxRepository) GetDueOutboxMessages(ctx context.Context, userID uint, now time.Time, limit int) ([]OutboxMessage, error) {
+	query := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", OutboxStatusPending, now)
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	var messages []OutboxMessage
+	if err := query.Order("next_attempt_at, id").Limit(limit).Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to get due outbox messages: %w", err)
+	}
+	return messages, nil
+}
+
+// ClaimOutboxMessage резервирует сообщение за текущим обработчиком до leaseUntil.
+// Возвращает false, если сообщение уже взято другим обработчиком.
+func (r *outboxRepository) ClaimOutboxMessage(ctx context.Context, id uint, now, leaseUntil time.Time) (bool, error) {
+	tx := r.db.WithContext(ctx).Model(&OutboxMessage{}).
+		Where("id = ? AND status = ? AND next_attempt_at <= ?", id, OutboxStatusPending, now).
+		Update("next_attempt_at", leaseUntil)
+	if tx.Error != nil {
+		return false, fmt.Errorf("failed to claim outbox message: %w", tx.Error)
+	}
+	return tx.RowsAffected == 1, nil
+}
+
+// MarkOutboxMessageDelivered удаляет доставленное сообщение из очереди.
+func (r *outboxRepository) MarkOutboxMessageDelivered(ctx context.Context, id uint) error {
+	if err := r.db.WithContext(ctx).Unscoped().Delete(&OutboxMessage{}, id).Error; err != nil {
+		return fmt.Errorf("failed to delete delivered outbox message: %w", err)
+	}
+	return nil
+}
+
+// MarkOutboxMessageFailed фиксирует неудачную попытку доставки. Если giveUp равен true,
+// сообщение переводится в статус failed и больше не отправляется.
+func (r *outboxRepository) MarkOutboxMessageFailed(ctx context.Context, id uint, lastError string, nextAttemptAt time.Time, giveUp bool) error {
+	if len(lastError) > 1024 {
+		lastError = lastError[:1024]
+	}
+
+	updates := map[string]interface{}{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"last_error":      lastError,
+		"next_attempt_at": nextAttemptAt,
+	}
+	if giveUp {
+		updates["status"] = OutboxStatusFailed
+	}
+
+	if err := r.db.WithContext(ctx).Model(&OutboxMessage{}).Where("id = ?", id).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to update outbox message: %w", err)
+	}
+	return nil
+}
+
+// PruneFailedOutboxMessages удаляет сообщения, доставка которых окончательно не удалась
+// раньше before. Доставленные сообщения удаляются сразу и здесь не учитываются.
+// Возвращает количество удаленных сообщений.
+func (r *outboxRepository) PruneFailedOutboxMessages(ctx context.Context, before time.Time) (int64, error) {
+	tx := r.db.WithContext(ctx).Unscoped().
+		Where("status = ? AND updated_at < ?", OutboxStatusFailed, before).
+		Delete(&OutboxMessage{})
+	if tx.Error != nil {
+		return 0, fmt.Errorf("failed to prune failed outbox messages: %w", tx.Error)
+	}
+	return tx.RowsAffected, nil
+}
+
+// CountPendingOutboxMessages возвращает количество сообщений, ожидающих доставки.
+func (r *outboxRepository) CountPendingOutboxMessages(ctx context.Context) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&OutboxMessage{}).Where("status = ?", OutboxStatusPending).Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count pending outbox messages: %w", err)
+	}
+	return count, nil
+}
+
+// ListOutboxMessages возвращает не более limit сообщений очереди, начиная с самых старых.
+func (r *outboxRepository) ListOutboxMessages(ctx context.Context, filter OutboxFilter, limit int) ([]OutboxMessage, error) {
+	query := r.db.WithContext(ctx)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+
+	var messages []OutboxMessage
+	if err := query.Order("id").Limit(limit).Find(&messages).Error; err != nil {
+		return nil, fmt.Errorf("failed to list outbox messages: %w", err)
+	}
+	return messages, nil
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProviderQuota хранит расход дневной квоты запросов к провайдеру новостей.
+type ProviderQuota struct {
+	gorm.Model
+	Provider  string `gorm:"size:64;not null;uniqueIndex:idx_provider_quota_day"`
+	Day       string `gorm:"size:10;not null;uniqueIndex:idx_provider_quota_day"` // День по UTC в формате 2006-01-02
+	Used      int    `gorm:"not null;default:0"`
+	Exhausted bool   `gorm:"not null;default:false"` // Провайдер сам сообщил об исчерпании лимита
+}
+
+// providerQuotaRepository реализует интерфейс ProviderQuotaRepository.
+type providerQuotaRepository struct {
+	db *gorm.DB
+}
+
+// NewProviderQuotaRepository создает новый репозиторий квот провайдеров.
+func NewProviderQuotaRepository(db *gorm.DB) ProviderQuotaRepository {
+	return &providerQuotaRepository{db: db}
+}
+
+// LoadProviderQuota возвращает расход квоты провайдера за день.
+func (r *providerQuotaRepository) LoadProviderQuota(ctx context.Context, provider, day string) (int, bool, error) {
+	var quota ProviderQuota
+	if err := r.db.WithContext(ctx).Where("provider = ? AND day = ?", provider, day).First(&quota).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, false, nil
+		}
+		return 0, false, fmt.Errorf("failed to load provider quota: %w", err)
+	}
+	return quota.Used, quota.Exhausted, nil
+}
+
+// SaveProviderQuota сохраняет расход квоты провайдера за день и удаляет записи прошлых дней.
+func (r *providerQuotaRepository) SaveProviderQuota(ctx context.Context, provider, day string, used int, exhausted bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		quota := ProviderQuota{Provider: provider, Day: day, Used: used, Exhausted: exhausted}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "provider"}, {Name: "day"}},
+			DoUpdates: clause.AssignmentColumns([]string{"used", "exhausted", "updated_at"}),
+		}).Create(&quota).Error
+		if err != nil {
+			return fmt.Errorf("failed to save provider quota: %w", err)
+		}
+		if err := tx.Unscoped().Where("provider = ? AND day < ?", provider, day).Delete(&ProviderQuota{}).Error; err != nil {
+			return fmt.Errorf("failed to delete old provider quotas: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserStats - сводка по пользователям бота.
+type UserStats struct {
+	Total      int64 // Все пользователи
+	Subscribed int64 // Пользователи хотя бы с одной подпиской
+	Active     int64 // Пользователи, получавшие новости за период
+	New        int64 // Пользователи, впервые написавшие боту за период
+}
+
+// TopicStats - тема и количество ее подписчиков.
+type TopicStats struct {
+	Topic       string
+	Subscribers int64
+}
+
+// DailyCount - количество событий за день.
+type DailyCount struct {
+	Day   string // Дата в формате 2006-01-02
+	Count int64
+}
+
+// statsRepository реализует интерфейс StatsRepository.
+type statsRepository struct {
+	db *gorm.DB
+}
+
+// NewStatsRepository создает новый репозиторий статистики.
+func NewStatsRepository(db *gorm.DB) StatsRepository {
+	return &statsRepository{db: db}
+}
+
+// GetUserStats возвращает количество пользователей; активные и новые считаются начиная с since.
+// Сброс истории не уменьшает число активных: удаленные записи отправленных статей тоже учитываются.
+func (r *statsRepository) GetUserStats(ctx context.Context, since time.Time) (UserStats, error) {
+	var stats UserStats
+	db := r.db.WithContext(ctx)
+
+	if err := db.Model(&User{}).Count(&stats.Total).Error; err != nil {
+		return UserStats{}, fmt.Errorf("failed to count users: %w", err)
+	}
+	if err := db.Model(&Subscription{}).Distinct("user_id").Count(&stats.Subscribed).Error; err != nil {
+		return UserStats{}, fmt.Errorf("failed to count subscribed users: %w", err)
+	}
+	if err := db.Unscoped().Model(&SentArticle{}).Where("sent_at >= ?", since).Distinct("user_id").Count(&stats.Active).Error; err != nil {
+		return UserStats{}, fmt.Errorf("failed to count active users: %w", err)
+	}
+	if err := db.Model(&User{}).Where("created_at >= ?", since).Count(&stats.New).Error; err != nil {
+		return UserStats{}, fmt.Errorf("failed to count new users: %w", err)
+	}
+	return stats, nil
+}
+
+// GetTopTopics возвращает до limit тем с наибольшим числом подписчиков.
+func (r *statsRepository) GetTopTopics(ctx context.Context, limit int) ([]TopicStats, error) {
+	var topics []TopicStats
+	err := r.db.WithContext(ctx).Model(&Subscription{}).
+		Select("topic, COUNT(DISTINCT user_id) AS subscribers").
+		Group("topic").
+		Order("subscribers DESC, topic").
+		Limit(limit).
+		Scan(&topics).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to get top topics: %w", err)
+	}
+	return topics, nil
+}
+
+// CountSentArticlesByDay возвращает количество отправленных статей по дням начиная с since.
+// Дни без отправок в результат не попадают.
+func (r *statsRepository) CountSentArticlesByDay(ctx context.Context, since time.Time) ([]DailyCount, error) {
+	var counts []DailyCount
+	err := r.db.WithContext(ctx).Unscoped().Model(&SentArticle{}).
+		Select("substr(sent_at, 1, 10) AS day, COUNT(*) AS count").
+		Where("sent_at >= ?", since).
+		Group("day").
+		Order("day").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count sent articles by day: %w", err)
+	}
+	return counts, nil
+}
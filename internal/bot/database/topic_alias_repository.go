@@ -0,0 +1,131 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// TopicAliasCorrection - исправление: вариант написания заменяется темой.
+	TopicAliasCorrection = "correction"
+	// TopicAliasSynonym - синоним: тема и вариант ищутся вместе через OR.
+	TopicAliasSynonym = "synonym"
+)
+
+// TopicAlias связывает вариант написания темы с темой, по которой ищутся новости.
+type TopicAlias struct {
+	gorm.Model
+	Alias string `gorm:"size:255;not null;uniqueIndex:idx_topic_alias"`
+	Topic string `gorm:"size:255;not null;uniqueIndex:idx_topic_alias"`
+	Kind  string `gorm:"size:16;not null;default:'correction'"`
+}
+
+// defaultTopicAliases заполняют таблицу при ее создании.
+var defaultTopicAliases = []TopicAlias{
+	{Alias: "искусственный интелент", Topic: "искусственный интеллект", Kind: TopicAliasCorrection},
+	{Alias: "новости москвы", Topic: "москва новости", Kind: TopicAliasCorrection},
+}
+
+// topicAliasRepository реализует интерфейс TopicAliasRepository.
+type topicAliasRepository struct {
+	db *gorm.DB
+}
+
+// NewTopicAliasRepository создает новый репозиторий синонимов тем.
+func NewTopicAliasRepository(db *gorm.DB) TopicAliasRepository {
+	return &topicAliasRepository{db: db}
+}
+
+// migrateTopicAliases создает таблицу синонимов и при первом создании заполняет ее
+// исправлениями, которые раньше были зашиты в код провайдеров.
+func migrateTopicAliases(db *gorm.DB) error {
+	created := !db.Migrator().HasTable(&TopicAlias{})
+	if err := db.AutoMigrate(&TopicAlias{}); err != nil {
+		return err
+	}
+	if !created {
+		return nil
+	}
+	aliases := make([]TopicAlias, len(defaultTopicAliases))
+	copy(aliases, defaultTopicAliases)
+	return db.Create(&aliases).Error
+}
+
+// AddTopicAlias добавляет исправление или синоним. У варианта может быть только
+// одно исправление, поэтому новое исправление заменяет прежнее.
+func (r *topicAliasRepository) AddTopicAlias(ctx context.Context, alias, topic, kind string) error {
+	alias, topic = normalizeAlias(alias), normalizeAlias(topic)
+	if alias == "" || topic == "" || alias == topic {
+		return errors.New("alias and topic must be non-empty and different")
+	}
+	if kind != TopicAliasCorrection && kind != TopicAliasSynonym {
+		return fmt.Errorf("unknown topic alias kind %q", kind)
+	}
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if kind == TopicAliasCorrection {
+			if err := tx.Unscoped().Where("alias = ? AND kind = ?", alias, TopicAliasCorrection).Delete(&TopicAlias{}).Error; err != nil {
+				return fmt.Errorf("failed to replace topic alias: %w", err)
+			}
+		}
+		if err := tx.Unscoped().Where("alias = ? AND topic = ?", alias, topic).Delete(&TopicAlias{}).Error; err != nil {
+			return fmt.Errorf("failed to replace topic alias: %w", err)
+		}
+		if err := tx.Create(&TopicAlias{Alias: alias, Topic: topic, Kind: kind}).Error; err != nil {
+			return fmt.Errorf("failed to add topic alias: %w", err)
+		}
+		return nil
+	})
+}
+
+// RemoveTopicAlias удаляет все исправления и синонимы варианта.
+func (r *topicAliasRepository) RemoveTopicAlias(ctx context.Context, alias string) error {
+	tx := r.db.WithContext(ctx).Unscoped().Where("alias = ?", normalizeAlias(alias)).Delete(&TopicAlias{})
+	if tx.Error != nil {
+		return fmt.Errorf("failed to remove topic alias: %w", tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		return errors.New("topic alias not found")
+	}
+	return nil
+}
+
+// GetTopicAliases возвращает все записи, отсортированные по теме и варианту.
+func (r *topicAliasRepository) GetTopicAliases(ctx context.Context) ([]TopicAlias, error) {
+	var aliases []TopicAlias
+	if err := r.db.WithContext(ctx).Order("topic, alias").Find(&aliases).Error; err != nil {
+		return nil, fmt.Errorf("failed to get topic aliases: %w", err)
+	}
+	return aliases, nil
+}
+
+// LoadTopicAliases возвращает исправления (вариант → тема) и синонимы.
+// Синонимы симметричны: каждая из двух тем возвращает другую в списке своих синонимов.
+func (r *topicAliasRepository) LoadTopicAliases(ctx context.Context) (map[string]string, map[string][]string, error) {
+	aliases, err := r.GetTopicAliases(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	corrections := make(map[string]string)
+	synonyms := make(map[string][]string)
+	for _, alias := range aliases {
+		switch alias.Kind {
+		case TopicAliasSynonym:
+			synonyms[alias.Topic] = append(synonyms[alias.Topic], alias.Alias)
+			synonyms[alias.Alias] = append(synonyms[alias.Alias], alias.Topic)
+		default:
+			corrections[alias.Alias] = alias.Topic
+		}
+	}
+	return corrections, synonyms, nil
+}
+
+// normalizeAlias приводит тему к нижнему регистру и убирает лишние пробелы.
+func normalizeAlias(value string) string {
+	return strings.Join(strings.Fields(strings.ToLower(value)), " ")
+}
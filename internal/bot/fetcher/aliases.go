@@ -0,0 +1,129 @@
+package fetcher
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/query"
+)
+
+// AliasStore определяет постоянное хранилище исправлений и синонимов тем.
+type AliasStore interface {
+	LoadTopicAliases(ctx context.Context) (corrections map[string]string, synonyms map[string][]string, err error)
+}
+
+// Aliases подставляет в запросы исправления и синонимы тем. Таблица целиком
+// хранится в памяти и перечитывается из хранилища методом Reload.
+type Aliases struct {
+	store AliasStore
+
+	mu          sync.RWMutex
+	corrections map[string]string   // Вариант написания → тема
+	synonyms    map[string][]string // Тема → синонимы
+}
+
+// NewAliases создает пустую таблицу синонимов поверх хранилища.
+func NewAliases(store AliasStore) *Aliases {
+	return &Aliases{
+		store:       store,
+		corrections: make(map[string]string),
+		synonyms:    make(map[string][]string),
+	}
+}
+
+// Reload перечитывает таблицу из хранилища.
+func (a *Aliases) Reload(ctx context.Context) error {
+	corrections, synonyms, err := a.store.LoadTopicAliases(ctx)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	a.corrections, a.synonyms = corrections, synonyms
+	a.mu.Unlock()
+	return nil
+}
+
+// Expand подставляет исправления и синонимы в разобранный запрос raw.
+// Запрос целиком и отдельные слова или фразы с исправлением заменяются темой,
+// а темы с синонимами превращаются в выражение "тема OR синоним".
+func (a *Aliases) Expand(raw string, expr query.Node) query.Node {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	whole := NormalizeQuery(raw)
+	if corrected, ok := a.corrections[whole]; ok {
+		if parsed, err := query.Parse(corrected); err == nil {
+			expr, whole = parsed, corrected
+		}
+	}
+
+	expr = query.Rewrite(expr, func(term *query.Term) query.Node {
+		value := term.Value
+		var replacement query.Node
+		if corrected, ok := a.corrections[value]; ok {
+			value = corrected
+			replacement = aliasNode(corrected, false)
+		}
+		if synonyms := a.synonyms[value]; len(synonyms) > 0 {
+			if replacement == nil {
+				replacement = term
+			}
+			return withSynonyms(replacement, synonyms)
+		}
+		return replacement
+	})
+
+	// Синонимы темы из нескольких слов применяются к запросу целиком;
+	// синонимы отдельных слов уже подставлены выше
+	if synonyms := a.synonyms[whole]; len(synonyms) > 0 && strings.Contains(whole, " ") {
+		expr = withSynonyms(expr, synonyms)
+	}
+	return expr
+}
+
+// Suggest возвращает исправленный вариант темы для подсказки «возможно, вы имели в виду».
+// Если исправлений нет, возвращается пустая строка.
+func (a *Aliases) Suggest(topic string) string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	topic = NormalizeQuery(topic)
+	if corrected, ok := a.corrections[topic]; ok {
+		return corrected
+	}
+
+	words := strings.Fields(topic)
+	changed := false
+	for i, word := range words {
+		if corrected, ok := a.corrections[word]; ok {
+			words[i] = corrected
+			changed = true
+		}
+	}
+	if !changed {
+		return ""
+	}
+	return strings.Join(words, " ")
+}
+
+// withSynonyms объединяет выражение с синонимами через OR.
+func withSynonyms(node query.Node, synonyms []string) query.Node {
+	nodes := []query.Node{node}
+	for _, synonym := range synonyms {
+		nodes = append(nodes, aliasNode(synonym, true))
+	}
+	return &query.Or{Nodes: nodes}
+}
+
+// aliasNode превращает значение из таблицы в узел запроса. Синоним из нескольких
+// слов ищется как фраза, исправление разбирается как обычный запрос.
+func aliasNode(value string, phrase bool) query.Node {
+	if !phrase {
+		if parsed, err := query.Parse(value); err == nil {
+			return parsed
+		}
+	}
+	return &query.Term{Value: value, Phrase: strings.Contains(value, " ")}
+}
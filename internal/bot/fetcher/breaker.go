@@ -0,0 +1,141 @@
+package fetcher
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerState - состояние автоматического выключателя провайдера.
+type BreakerState int
+
+const (
+	// BreakerClosed - провайдер работает, запросы проходят.
+	BreakerClosed BreakerState = iota
+	// BreakerOpen - после серии ошибок запросы к провайдеру не отправляются до истечения паузы.
+	BreakerOpen
+	// BreakerHalfOpen - пауза истекла, пропускается один пробный запрос.
+	BreakerHalfOpen
+)
+
+// String возвращает название состояния для логов и статистики.
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// BreakerConfig задает параметры выключателей провайдеров.
+type BreakerConfig struct {
+	FailureThreshold int           // Сколько ошибок подряд размыкают выключатель
+	Cooldown         time.Duration // Сколько выключатель остается разомкнутым перед пробным запросом
+}
+
+// DefaultBreakerConfig возвращает параметры выключателей по умолчанию.
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		FailureThreshold: 3,
+		Cooldown:         5 * time.Minute,
+	}
+}
+
+// CircuitBreaker не дает повторять запросы к провайдеру, который подряд возвращает ошибки.
+// Разомкнутый выключатель через Cooldown переходит в полуоткрытое состояние и пропускает
+// один пробный запрос: успех замыкает выключатель, ошибка снова размыкает его.
+type CircuitBreaker struct {
+	config BreakerConfig
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool // Пробный запрос в полуоткрытом состоянии уже выполняется
+}
+
+// NewCircuitBreaker создает замкнутый выключатель.
+func NewCircuitBreaker(config BreakerConfig) *CircuitBreaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 1
+	}
+	return &CircuitBreaker{config: config}
+}
+
+// Allow сообщает, можно ли сейчас отправить запрос провайдеру.
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.currentState() {
+	case BreakerOpen:
+		return false
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return true
+	}
+	return true
+}
+
+// Success отмечает успешный запрос и замыкает выключатель.
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probing = false
+}
+
+// Failure отмечает ошибку запроса. Выключатель размыкается после FailureThreshold
+// ошибок подряд или сразу, если ошибкой завершился пробный запрос.
+func (b *CircuitBreaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures++
+	if b.state == BreakerHalfOpen || b.failures >= b.config.FailureThreshold {
+		b.open()
+	}
+}
+
+// Abort отмечает запрос, прерванный вызывающим кодом: состояние не меняется,
+// но полуоткрытый выключатель снова готов пропустить пробный запрос.
+func (b *CircuitBreaker) Abort() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// Trip сразу размыкает выключатель, например когда провайдер сообщил о превышении лимита.
+func (b *CircuitBreaker) Trip() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.open()
+}
+
+// State возвращает текущее состояние выключателя.
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.currentState()
+}
+
+// currentState учитывает истечение паузы разомкнутого выключателя. Вызывается под mu.
+func (b *CircuitBreaker) currentState() BreakerState {
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.config.Cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// open размыкает выключатель. Вызывается под mu.
+func (b *CircuitBreaker) open() {
+	b.state = BreakerOpen
+	b.openedAt = time.Now()
+	b.probing = false
+}
@@ -0,0 +1,201 @@
+package fetcher
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// CacheStore определяет постоянное хранилище результатов поиска,
+// позволяющее кэшу пережить перезапуск бота.
+type CacheStore interface {
+	LoadCachedResult(ctx context.Context, key string) (payload []byte, fetchedAt time.Time, found bool, err error)
+	SaveCachedResult(ctx context.Context, key string, payload []byte, fetchedAt time.Time) error
+	DeleteCachedResultsBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// CacheStats содержит счетчики использования кэша.
+type CacheStats struct {
+	Hits      uint64 // Ответы из памяти
+	StoreHits uint64 // Ответы из постоянного хранилища
+	Misses    uint64 // Запросы, ушедшие к провайдерам
+	Entries   int    // Текущее количество записей в памяти
+}
+
+// cacheEntry - запись LRU-кэша.
+type cacheEntry struct {
+	key       string
+	articles  []Article
+	fetchedAt time.Time
+}
+
+// Cache кэширует результаты поиска по нормализованному запросу с ограниченным
+// временем жизни. В памяти хранится не более capacity записей (LRU), при наличии
+// store записи дополнительно сохраняются в базе данных.
+type Cache struct {
+	ttl      time.Duration
+	capacity int
+	store    CacheStore
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+
+	hits      atomic.Uint64
+	storeHits atomic.Uint64
+	misses    atomic.Uint64
+	prunedAt  atomic.Int64 // Время последней очистки хранилища, Unix-наносекунды
+}
+
+// NewCache создает кэш с заданным временем жизни и размером.
+// store может быть nil, если постоянное хранение не нужно.
+func NewCache(ttl time.Duration, capacity int, store CacheStore) *Cache {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &Cache{
+		ttl:      ttl,
+		capacity: capacity,
+		store:    store,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// NormalizeQuery приводит запрос к ключу кэша: нижний регистр и единичные пробелы.
+func NormalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// Get возвращает закэшированные статьи, если запись существует и не устарела.
+func (c *Cache) Get(ctx context.Context, key string) ([]Article, bool) {
+	now := time.Now()
+
+	c.mu.Lock()
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		if now.Sub(entry.fetchedAt) < c.ttl {
+			c.ll.MoveToFront(elem)
+			c.mu.Unlock()
+			c.hits.Add(1)
+			return entry.articles, true
+		}
+		c.removeElement(elem)
+	}
+	c.mu.Unlock()
+
+	if articles, fetchedAt, ok := c.loadFromStore(ctx, key); ok && now.Sub(fetchedAt) < c.ttl {
+		c.put(key, articles, fetchedAt)
+		c.storeHits.Add(1)
+		return articles, true
+	}
+
+	c.misses.Add(1)
+	return nil, false
+}
+
+// Set сохраняет результат поиска в кэше. Запись в хранилище не прерывается отменой ctx:
+// ответ провайдера уже получен и пригодится следующим запросам.
+func (c *Cache) Set(ctx context.Context, key string, articles []Article) {
+	fetchedAt := time.Now()
+	c.put(key, articles, fetchedAt)
+	c.saveToStore(context.WithoutCancel(ctx), key, articles, fetchedAt)
+}
+
+// Stats возвращает текущие счетчики кэша.
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	entries := c.ll.Len()
+	c.mu.Unlock()
+
+	return CacheStats{
+		Hits:      c.hits.Load(),
+		StoreHits: c.storeHits.Load(),
+		Misses:    c.misses.Load(),
+		Entries:   entries,
+	}
+}
+
+func (c *Cache) put(key string, articles []Article, fetchedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.articles = articles
+		entry.fetchedAt = fetchedAt
+		c.ll.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.ll.PushFront(&cacheEntry{key: key, articles: articles, fetchedAt: fetchedAt})
+	for c.ll.Len() > c.capacity {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+func (c *Cache) removeElement(elem *list.Element) {
+	c.ll.Remove(elem)
+	delete(c.items, elem.Value.(*cacheEntry).key)
+}
+
+func (c *Cache) loadFromStore(ctx context.Context, key string) ([]Article, time.Time, bool) {
+	if c.store == nil {
+		return nil, time.Time{}, false
+	}
+
+	payload, fetchedAt, found, err := c.store.LoadCachedResult(ctx, key)
+	if err != nil {
+		slog.Error("Ошибка чтения кэша новостей из БД", "key", key, "error", err)
+		return nil, time.Time{}, false
+	}
+	if !found {
+		return nil, time.Time{}, false
+	}
+
+	var articles []Article
+	if err := json.Unmarshal(payload, &articles); err != nil {
+		slog.Error("Ошибка декодирования кэша новостей", "key", key, "error", err)
+		return nil, time.Time{}, false
+	}
+	return articles, fetchedAt, true
+}
+
+func (c *Cache) saveToStore(ctx context.Context, key string, articles []Article, fetchedAt time.Time) {
+	if c.store == nil {
+		return
+	}
+
+	payload, err := json.Marshal(articles)
+	if err != nil {
+		slog.Error("Ошибка кодирования кэша новостей", "key", key, "error", err)
+		return
+	}
+	if err := c.store.SaveCachedResult(ctx, key, payload, fetchedAt); err != nil {
+		slog.Error("Ошибка сохранения кэша новостей в БД", "key", key, "error", err)
+	}
+	c.pruneStore(ctx, fetchedAt)
+}
+
+// pruneStore удаляет из хранилища устаревшие записи. Каждый новый поисковый запрос
+// добавляет запись, поэтому очистка выполняется при записи, но не чаще раза за ttl.
+func (c *Cache) pruneStore(ctx context.Context, now time.Time) {
+	last := c.prunedAt.Load()
+	if now.UnixNano()-last < int64(c.ttl) || !c.prunedAt.CompareAndSwap(last, now.UnixNano()) {
+		return
+	}
+
+	deleted, err := c.store.DeleteCachedResultsBefore(ctx, now.Add(-c.ttl))
+	if err != nil {
+		slog.Error("Ошибка очистки кэша новостей в БД", "error", err)
+		return
+	}
+	if deleted > 0 {
+		slog.Debug("Удалены устаревшие записи кэша новостей", "count", deleted)
+	}
+}
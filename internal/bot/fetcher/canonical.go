@@ -0,0 +1,104 @@
+package fetcher
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// trackingParams - параметры запроса, которые не влияют на содержимое страницы.
+// Параметры с префиксом utm_ удаляются всегда.
+var trackingParams = map[string]bool{
+	"fbclid":     true,
+	"gclid":      true,
+	"yclid":      true,
+	"mc_cid":     true,
+	"mc_eid":     true,
+	"igshid":     true,
+	"ref":        true,
+	"ref_src":    true,
+	"amp":        true,
+	"outputtype": true, // outputType=amp
+}
+
+// CanonicalURL приводит адрес статьи к каноническому виду, чтобы одна и та же
+// новость от разных провайдеров давала один адрес: схема заменяется на https,
+// хост приводится к нижнему регистру без www., m. и amp., удаляются порт по умолчанию,
+// фрагмент, параметры отслеживания (utm_* и др.) и AMP-суффиксы пути.
+// Оставшиеся параметры сортируются. Строка, которая не разбирается как URL,
+// возвращается без изменений, кроме обрезки пробелов.
+func CanonicalURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+
+	u.Scheme = "https"
+	u.User = nil
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	host := strings.ToLower(u.Hostname())
+	for _, prefix := range []string{"www.", "m.", "amp."} {
+		host = strings.TrimPrefix(host, prefix)
+	}
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+	u.Host = host
+
+	u.Path = canonicalPath(u.Path)
+	u.RawPath = ""
+
+	query := u.Query()
+	for name := range query {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "utm_") || trackingParams[lower] {
+			query.Del(name)
+		}
+	}
+	u.RawQuery = encodeSorted(query)
+
+	return u.String()
+}
+
+// ArticleKey возвращает ключ статьи фиксированной длины (64 символа) -
+// SHA-256 от канонического адреса. Используется для истории отправки и избранного.
+func ArticleKey(articleURL string) string {
+	sum := sha256.Sum256([]byte(CanonicalURL(articleURL)))
+	return hex.EncodeToString(sum[:])
+}
+
+// canonicalPath убирает AMP-суффиксы и завершающий слэш пути.
+func canonicalPath(path string) string {
+	path = strings.TrimSuffix(path, "/")
+	for _, suffix := range []string{"/amp", ".amp", "/amp.html"} {
+		if strings.HasSuffix(path, suffix) {
+			path = strings.TrimSuffix(path, suffix)
+			break
+		}
+	}
+	if strings.HasSuffix(path, ".amp.html") {
+		path = strings.TrimSuffix(path, ".amp.html") + ".html"
+	}
+	// Путь вида /amp/news/123 - AMP-версия /news/123
+	if strings.HasPrefix(path, "/amp/") {
+		path = strings.TrimPrefix(path, "/amp")
+	}
+	return strings.TrimSuffix(path, "/")
+}
+
+// encodeSorted кодирует параметры запроса в порядке сортировки имен и значений.
+func encodeSorted(query url.Values) string {
+	if len(query) == 0 {
+		return ""
+	}
+	for _, values := range query {
+		sort.Strings(values)
+	}
+	// url.Values.Encode сортирует параметры по имени
+	return query.Encode()
+}
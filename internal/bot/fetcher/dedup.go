@@ -0,0 +1,157 @@
+package fetcher
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// NearDuplicateDistance - максимальное расстояние Хэмминга между отпечатками
+	// статей, при котором они считаются одной новостью. У несвязанных текстов
+	// отпечатки в среднем различаются в 32 битах из 64.
+	NearDuplicateDistance = 16
+
+	// titleWeight - вес слов заголовка относительно слов описания: описания у разных
+	// изданий различаются сильнее, чем заголовки.
+	titleWeight = 3
+
+	// stemLength - до скольких символов усекаются слова: грубая замена стемминга,
+	// чтобы "ставку" и "ставки" давали один признак.
+	stemLength = 5
+)
+
+// Fingerprint вычисляет 64-битный SimHash заголовка и описания статьи. У близких
+// по тексту статей отпечатки различаются в небольшом числе битов. Признаки - слова
+// без учета регистра, пунктуации и окончаний; название источника в конце
+// заголовка ("Заголовок - Reuters") не учитывается.
+func Fingerprint(article Article) uint64 {
+	var weights [64]int
+	addFeatures(&weights, trimSourceSuffix(article.Title, article.Source.Name), titleWeight)
+	addFeatures(&weights, article.Description, 1)
+
+	var fingerprint uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			fingerprint |= 1 << uint(bit)
+		}
+	}
+	return fingerprint
+}
+
+// FingerprintDistance возвращает количество различающихся битов двух отпечатков.
+func FingerprintDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// ClusterNearDuplicates группирует статьи, пересказывающие одну новость: отпечатки
+// различаются не более чем на NearDuplicateDistance битов, а время публикации -
+// не более чем на window. Возвращает группы индексов статей; первым в группе идет
+// представитель - статья, встретившаяся в articles раньше остальных.
+func ClusterNearDuplicates(articles []Article, window time.Duration) [][]int {
+	type cluster struct {
+		fingerprint uint64
+		publishedAt time.Time
+		members     []int
+	}
+
+	var clusters []*cluster
+	for i, article := range articles {
+		fingerprint := Fingerprint(article)
+
+		var found *cluster
+		for _, c := range clusters {
+			if FingerprintDistance(c.fingerprint, fingerprint) <= NearDuplicateDistance &&
+				withinWindow(c.publishedAt, article.PublishedAt, window) {
+				found = c
+				break
+			}
+		}
+		if found == nil {
+			clusters = append(clusters, &cluster{fingerprint: fingerprint, publishedAt: article.PublishedAt, members: []int{i}})
+			continue
+		}
+		found.members = append(found.members, i)
+	}
+
+	groups := make([][]int, len(clusters))
+	for i, c := range clusters {
+		groups[i] = c.members
+	}
+	return groups
+}
+
+// MergeDuplicates возвращает представителя группы с заполненным AlsoReportedBy:
+// уникальными названиями источников остальных статей группы, кроме его собственного.
+func MergeDuplicates(representative Article, duplicates []Article) Article {
+	seen := map[string]bool{strings.ToLower(representative.Source.Name): true}
+	var sources []string
+	for _, duplicate := range duplicates {
+		name := strings.TrimSpace(duplicate.Source.Name)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		sources = append(sources, name)
+	}
+	representative.AlsoReportedBy = sources
+	return representative
+}
+
+// withinWindow проверяет, что моменты публикации отличаются не более чем на window.
+// Статьи без даты публикации сравниваются только по тексту.
+func withinWindow(a, b time.Time, window time.Duration) bool {
+	if a.IsZero() || b.IsZero() {
+		return true
+	}
+	diff := a.Sub(b)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= window
+}
+
+// addFeatures добавляет к весам битов признаки текста - усеченные слова.
+func addFeatures(weights *[64]int, text string, weight int) {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for _, word := range words {
+		if r := []rune(word); len(r) > stemLength {
+			word = string(r[:stemLength])
+		}
+		addFeature(weights, word, weight)
+	}
+}
+
+// addFeature добавляет вес признака к битам, установленным в его хеше, и вычитает из остальных.
+func addFeature(weights *[64]int, feature string, weight int) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+	for bit := range weights {
+		if sum&(1<<uint(bit)) != 0 {
+			weights[bit] += weight
+		} else {
+			weights[bit] -= weight
+		}
+	}
+}
+
+// trimSourceSuffix убирает из заголовка название источника, которое агрегаторы
+// добавляют через дефис или вертикальную черту.
+func trimSourceSuffix(title, source string) string {
+	title = strings.TrimSpace(title)
+	if source == "" {
+		return title
+	}
+	for _, separator := range []string{" - ", " | ", " — "} {
+		if trimmed, ok := strings.CutSuffix(title, separator+source); ok {
+			return trimmed
+		}
+	}
+	return title
+}
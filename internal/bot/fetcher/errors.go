@@ -0,0 +1,96 @@
+package fetcher
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Категории ошибок провайдеров. Провайдеры переводят в них HTTP-ответы своих API,
+// а обработчики и планировщик различают их через errors.Is и errors.As.
+var (
+	// ErrRateLimited - провайдер отказал из-за лимита запросов. Ошибки этой категории
+	// имеют тип *RateLimitError или *QuotaExhaustedError и содержат время обновления лимита.
+	ErrRateLimited = errors.New("превышен лимит запросов")
+	// ErrUnauthorized - ключ API не настроен, неверен или отозван.
+	ErrUnauthorized = errors.New("ключ API не принят")
+	// ErrUpstreamUnavailable - провайдер недоступен: сетевая ошибка, ошибка сервера или некорректный ответ.
+	ErrUpstreamUnavailable = errors.New("провайдер недоступен")
+	// ErrBadQuery - провайдер или парсер запросов отклонили запрос.
+	ErrBadQuery = errors.New("некорректный запрос")
+)
+
+// ErrCircuitOpen возвращается вместо запроса к провайдеру, выключатель которого разомкнут.
+var ErrCircuitOpen = fmt.Errorf("%w: выключатель разомкнут после серии ошибок", ErrUpstreamUnavailable)
+
+// RateLimitError - провайдер ограничил запросы до ResetAt.
+type RateLimitError struct {
+	Provider string
+	ResetAt  time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s: %s до %s UTC", e.Provider, ErrRateLimited, e.ResetAt.UTC().Format("15:04"))
+}
+
+// Is относит ошибку к категории ErrRateLimited.
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// ProviderError - ошибка ответа API провайдера. Тело ответа в ошибку не попадает:
+// оно может содержать ключ API или слишком длинный текст.
+type ProviderError struct {
+	Provider   string // Название API для сообщения, например "GNews API"
+	StatusCode int
+	Err        error // Категория: ErrUnauthorized, ErrUpstreamUnavailable или ErrBadQuery
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("%s вернул ошибку %d %s: %s", e.Provider, e.StatusCode, http.StatusText(e.StatusCode), e.Err)
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// classifyStatus переводит код ответа в категорию ошибки по общим правилам HTTP.
+// Провайдеры уточняют ее по кодам своих API.
+func classifyStatus(provider string, resp *http.Response) error {
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return &RateLimitError{Provider: provider, ResetAt: retryAfter(resp, time.Now(), time.Minute)}
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return &ProviderError{Provider: provider, StatusCode: resp.StatusCode, Err: ErrUnauthorized}
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return &ProviderError{Provider: provider, StatusCode: resp.StatusCode, Err: ErrBadQuery}
+	default:
+		return &ProviderError{Provider: provider, StatusCode: resp.StatusCode, Err: ErrUpstreamUnavailable}
+	}
+}
+
+// retryAfter возвращает момент из заголовка Retry-After (в секундах или датой)
+// либо now+fallback, если заголовка нет.
+func retryAfter(resp *http.Response, now time.Time, fallback time.Duration) time.Time {
+	value := resp.Header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return now.Add(time.Duration(seconds) * time.Second)
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return at
+	}
+	return now.Add(fallback)
+}
+
+// transportError оборачивает сетевую ошибку в ErrUpstreamUnavailable. Адрес запроса
+// из *url.Error отбрасывается, так как в нем передается ключ API.
+func transportError(provider string, err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	return fmt.Errorf("ошибка выполнения запроса к %s: %w: %w", provider, ErrUpstreamUnavailable, err)
+}
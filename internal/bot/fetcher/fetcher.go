@@ -1,16 +1,19 @@
 package fetcher
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
-	"log"
+	"log/slog"
 	"net/http"
-	"net/url"
+	"strings"
+	"sync"
 	"time"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/query"
 )
 
-// Article представляет одну новостную статью из ответа GNews.
+// Article представляет одну новостную статью.
 type Article struct {
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
@@ -19,6 +22,9 @@ type Article struct {
 	Image       string    `json:"image"`
 	PublishedAt time.Time `json:"publishedAt"`
 	Source      Source    `json:"source"`
+	// AlsoReportedBy - другие источники, опубликовавшие ту же новость.
+	// Заполняется при объединении похожих статей (MergeDuplicates).
+	AlsoReportedBy []string `json:"alsoReportedBy,omitempty"`
 }
 
 // Source представляет источник новости.
@@ -27,227 +33,327 @@ type Source struct {
 	URL  string `json:"url"`
 }
 
-// GNewsResponse представляет полный ответ от API GNews.
-type GNewsResponse struct {
-	TotalArticles int       `json:"totalArticles"`
-	Articles      []Article `json:"articles"`
-}
+const (
+	// DefaultLanguage - язык новостей по умолчанию.
+	DefaultLanguage = "ru"
+	// DefaultCountry - страна источников по умолчанию.
+	DefaultCountry = "ru"
+)
 
-// Fetcher предоставляет методы для получения новостей из различных источников.
+// Fetcher получает новости, последовательно опрашивая провайдеров из реестра.
 type Fetcher struct {
-	GNewsAPIKey string
-	NewsAPIKey  string
-	HTTPClient  *http.Client
-	LastAPIUsed string // Запоминаем последний использованный API
+	registry *Registry
+	cache    *Cache
+	aliases  *Aliases
+	quota    *Quota
+
+	mu            sync.Mutex
+	lastAPIUsed   string // Запоминаем последний успешно использованный провайдер
+	breakerConfig BreakerConfig
+	breakers      map[string]*CircuitBreaker // Выключатели по имени провайдера
+}
+
+// ProviderHealth описывает состояние провайдера: выключатель и расход квоты.
+type ProviderHealth struct {
+	Name    string
+	Breaker BreakerState
+	Quota   QuotaUsage // Заполняется, если настроен учет квот
 }
 
-// NewFetcher создает новый экземпляр Fetcher.
-func NewFetcher(gNewsAPIKey string, newsAPIKey string) *Fetcher {
+// NewFetcher создает новый экземпляр Fetcher поверх реестра провайдеров.
+// У каждого провайдера есть выключатель с параметрами DefaultBreakerConfig.
+func NewFetcher(registry *Registry) *Fetcher {
 	return &Fetcher{
-		GNewsAPIKey: gNewsAPIKey,
-		NewsAPIKey:  newsAPIKey,
-		HTTPClient: &http.Client{
-			Timeout: 10 * time.Second, // Устанавливаем таймаут для запросов
-		},
-		LastAPIUsed: "",
-	}
-}
-
-// NewsAPIResponse представляет ответ от News API
-type NewsAPIResponse struct {
-	Status       string `json:"status"`
-	TotalResults int    `json:"totalResults"`
-	Articles     []struct {
-		Source struct {
-			ID   string `json:"id"`
-			Name string `json:"name"`
-		} `json:"source"`
-		Author      string    `json:"author"`
-		Title       string    `json:"title"`
-		Description string    `json:"description"`
-		URL         string    `json:"url"`
-		URLToImage  string    `json:"urlToImage"`
-		PublishedAt time.Time `json:"publishedAt"`
-		Content     string    `json:"content"`
-	} `json:"articles"`
-}
-
-// FetchNewsFromNewsAPI выполняет запрос к News API для получения новостей по теме
-func (f *Fetcher) FetchNewsFromNewsAPI(topic string) ([]Article, error) {
-	if f.NewsAPIKey == "" {
-		return nil, fmt.Errorf("ключ News API не настроен")
-	}
-
-	log.Printf("Запрашиваю новости из News API по теме: '%s'", topic)
-
-	// Не кодируем тему здесь, так как будем использовать модифицированный запрос
-
-	// Расширяем запрос для получения большего количества результатов
-
-	// Добавляем синонимы и исправления для популярных тем
-	var searchQuery string
-	switch topic {
-	case "искусственный интелент":
-		searchQuery = "искусственный интеллект"
-	case "программирование":
-		searchQuery = "программирование"
-	case "политика":
-		searchQuery = "политика"
-	case "новости москвы":
-		searchQuery = "москва новости"
-	default:
-		searchQuery = topic
-	}
-
-	// Кодируем запрос для URL
-	query := url.QueryEscape(searchQuery)
-
-	// Формируем URL для запроса с расширенными параметрами
-	apiURL := fmt.Sprintf("https://newsapi.org/v2/everything?q=%s&language=ru&sortBy=publishedAt&pageSize=10&apiKey=%s", query, f.NewsAPIKey)
-	log.Printf("Запрос к News API: %s", apiURL)
-
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка создания запроса к News API: %w", err)
+		registry:      registry,
+		breakerConfig: DefaultBreakerConfig(),
+		breakers:      make(map[string]*CircuitBreaker),
 	}
+}
 
-	resp, err := f.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка выполнения запроса к News API: %w", err)
+// NewHTTPClient создает HTTP-клиент с таймаутом, общий для провайдеров.
+func NewHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout: 10 * time.Second, // Устанавливаем таймаут для запросов
 	}
-	defer resp.Body.Close()
+}
 
-	log.Printf("Ответ от News API: статус %d %s", resp.StatusCode, resp.Status)
+// SetCache включает кэширование результатов FetchNews.
+func (f *Fetcher) SetCache(cache *Cache) {
+	f.cache = cache
+}
 
-	if resp.StatusCode != http.StatusOK {
-		// Читаем тело ответа для получения дополнительной информации об ошибке
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("News API вернул ошибку: %s, тело: %s", resp.Status, string(body))
-	}
+// SetAliases включает расширение запросов исправлениями и синонимами тем.
+func (f *Fetcher) SetAliases(aliases *Aliases) {
+	f.aliases = aliases
+}
 
-	var newsAPIResponse NewsAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&newsAPIResponse); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования JSON от News API: %w", err)
-	}
+// SetQuota включает учет дневной квоты запросов к провайдерам.
+func (f *Fetcher) SetQuota(quota *Quota) {
+	f.quota = quota
+}
 
-	log.Printf("Получено %d статей из News API по теме '%s'", newsAPIResponse.TotalResults, topic)
+// SetBreakerConfig задает параметры выключателей провайдеров. Уже созданные
+// выключатели сбрасываются.
+func (f *Fetcher) SetBreakerConfig(config BreakerConfig) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.breakerConfig = config
+	f.breakers = make(map[string]*CircuitBreaker)
+}
 
-	// Преобразуем в наш формат статей
-	articles := make([]Article, 0, len(newsAPIResponse.Articles))
-	for _, a := range newsAPIResponse.Articles {
-		articles = append(articles, Article{
-			Title:       a.Title,
-			Description: a.Description,
-			Content:     a.Content,
-			URL:         a.URL,
-			Image:       a.URLToImage,
-			PublishedAt: a.PublishedAt,
-			Source: Source{
-				Name: a.Source.Name,
-				URL:  "", // News API не предоставляет URL источника
-			},
-		})
+// ProviderHealth возвращает состояние включенных провайдеров в порядке опроса.
+func (f *Fetcher) ProviderHealth() []ProviderHealth {
+	providers := f.registry.Enabled()
+	health := make([]ProviderHealth, 0, len(providers))
+	for _, provider := range providers {
+		item := ProviderHealth{Name: provider.Name(), Breaker: f.breaker(provider.Name()).State()}
+		if f.quota != nil {
+			item.Quota = f.quota.Usage(provider.Name())
+		}
+		health = append(health, item)
 	}
+	return health
+}
 
-	if len(articles) > 0 {
-		log.Printf("Первая статья из News API: '%s', опубликована: %s",
-			articles[0].Title,
-			articles[0].PublishedAt.Format("2006-01-02 15:04:05"))
+// HealthCheck возвращает ошибку, если нет включенных провайдеров или выключатели
+// всех провайдеров разомкнуты.
+func (f *Fetcher) HealthCheck(ctx context.Context) error {
+	health := f.ProviderHealth()
+	if len(health) == 0 {
+		return errors.New("нет включенных провайдеров новостей")
+	}
+	for _, provider := range health {
+		if provider.Breaker != BreakerOpen {
+			return nil
+		}
 	}
+	return fmt.Errorf("выключатели всех провайдеров разомкнуты: %w", ErrCircuitOpen)
+}
 
-	f.LastAPIUsed = "NewsAPI"
-	return articles, nil
+// breaker возвращает выключатель провайдера, создавая его при первом обращении.
+func (f *Fetcher) breaker(name string) *CircuitBreaker {
+	name = normalizeProviderName(name)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	b, ok := f.breakers[name]
+	if !ok {
+		b = NewCircuitBreaker(f.breakerConfig)
+		f.breakers[name] = b
+	}
+	return b
 }
 
-// FetchNewsFromGNews выполняет запрос к GNews API для получения новостей по теме
-func (f *Fetcher) FetchNewsFromGNews(topic string) ([]Article, error) {
-	if f.GNewsAPIKey == "" {
-		return nil, fmt.Errorf("ключ GNews API не настроен")
+// CacheStats возвращает счетчики кэша; ok равен false, если кэш не настроен.
+func (f *Fetcher) CacheStats() (stats CacheStats, ok bool) {
+	if f.cache == nil {
+		return CacheStats{}, false
 	}
+	return f.cache.Stats(), true
+}
 
-	log.Printf("Запрашиваю новости из GNews API по теме: '%s'", topic)
+// Registry возвращает реестр провайдеров.
+func (f *Fetcher) Registry() *Registry {
+	return f.registry
+}
 
-	// Расширяем запрос для получения большего количества результатов
-	modifiedTopic := topic
+// LastAPIUsed возвращает имя последнего провайдера, вернувшего новости.
+func (f *Fetcher) LastAPIUsed() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lastAPIUsed
+}
 
-	// Добавляем синонимы и исправления для популярных тем
-	switch topic {
-	case "искусственный интелент":
-		modifiedTopic = "искусственный интеллект"
-	}
+// FetchNews получает новости по теме на языке и для страны по умолчанию.
+func (f *Fetcher) FetchNews(ctx context.Context, topic string) ([]Article, error) {
+	return f.Search(ctx, SearchRequest{Query: topic})
+}
 
-	// Увеличиваем количество результатов до 20
-	log.Printf("Использую модифицированную тему для GNews API: '%s'", modifiedTopic)
+// Search получает новости по запросу из кэша или из доступных источников.
+// Запрос разбирается пакетом query; некорректный запрос возвращает ошибку.
+// Пустые язык и страна заменяются значениями по умолчанию.
+// Успешные ответы сохраняются в кэше по нормализованному запросу.
+// Отмена ctx прерывает запросы к провайдерам, и Search возвращает ошибку ctx.
+func (f *Fetcher) Search(ctx context.Context, req SearchRequest) ([]Article, error) {
+	// Проверяем, не пустая ли тема
+	if req.Query == "" {
+		return nil, fmt.Errorf("%w: тема не может быть пустой", ErrBadQuery)
+	}
+	expr, err := parseRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	if f.aliases != nil {
+		expr = f.aliases.Expand(req.Query, expr)
+	}
+	req.Expr = expr
+	if req.Language == "" {
+		req.Language = DefaultLanguage
+	}
+	if req.Country == "" {
+		req.Country = DefaultCountry
+	}
 
-	// Кодируем модифицированную тему для URL
-	encodedTopic := url.QueryEscape(modifiedTopic)
+	if f.cache == nil {
+		return f.fetchFromProviders(ctx, req)
+	}
 
-	// Формируем URL для запроса с увеличенным количеством результатов
-	apiURL := fmt.Sprintf("https://gnews.io/api/v4/search?q=%s&country=ru&lang=ru&sortby=publishedAt&max=20&token=%s", encodedTopic, f.GNewsAPIKey)
-	log.Printf("Запрос к GNews API: %s", apiURL)
+	key := RequestKey(req)
+	if articles, ok := f.cache.Get(ctx, key); ok {
+		slog.Debug("Новости взяты из кэша", "topic", req.Query)
+		return articles, nil
+	}
 
-	req, err := http.NewRequest("GET", apiURL, nil)
+	articles, err := f.fetchFromProviders(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка создания запроса к GNews: %w", err)
+		return nil, err
+	}
+	f.cache.Set(ctx, key, articles)
+	return articles, nil
+}
+
+// RequestKey возвращает ключ кэша для запроса. Для языка и страны по умолчанию
+// ключ совпадает с нормализованным запросом.
+func RequestKey(req SearchRequest) string {
+	key := NormalizeQuery(req.Query)
+	language, country := strings.ToLower(req.Language), strings.ToLower(req.Country)
+	if (language == "" || language == DefaultLanguage) && (country == "" || country == DefaultCountry) {
+		return key
 	}
+	return key + "|" + language + "|" + country
+}
 
-	resp, err := f.HTTPClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка выполнения запроса к GNews: %w", err)
+// fetchFromProviders опрашивает провайдеров в порядке, заданном в реестре,
+// пока один из них не вернет непустой результат. Провайдеры с разомкнутым выключателем
+// или исчерпанной квотой пропускаются. Если запрос использует операторы,
+// а провайдер их не поддерживает, ему передаются ключевые слова запроса,
+// а ответ дополнительно фильтруется по полному запросу.
+func (f *Fetcher) fetchFromProviders(ctx context.Context, req SearchRequest) ([]Article, error) {
+	providers := f.registry.Enabled()
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("нет включенных провайдеров новостей")
 	}
-	defer resp.Body.Close()
+	topic := req.Query
+
+	var (
+		lastErr      error
+		succeeded    bool
+		quotaResetAt time.Time // Ближайшее обновление квоты среди провайдеров, упершихся в лимит
+		otherErrors  bool      // Были ли ошибки, не связанные с квотой
+	)
+	for _, provider := range providers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		name := provider.Name()
+		if f.quota != nil {
+			if ok, resetAt := f.quota.Allow(name); !ok {
+				slog.Info("Провайдер пропущен: дневная квота исчерпана", "provider", name, "reset_at", resetAt.UTC())
+				quotaResetAt = earliest(quotaResetAt, resetAt)
+				continue
+			}
+		}
+		breaker := f.breaker(name)
+		if !breaker.Allow() {
+			slog.Info("Провайдер пропущен: выключатель разомкнут после ошибок", "provider", name)
+			lastErr, otherErrors = fmt.Errorf("%s: %w", name, ErrCircuitOpen), true
+			continue
+		}
 
-	log.Printf("Ответ от GNews API: статус %d %s", resp.StatusCode, resp.Status)
+		postFilter := !provider.Capabilities().BooleanQueries && !query.IsSimple(req.Expr)
+		providerReq := req
+		if postFilter {
+			providerReq.Query = strings.Join(query.Keywords(req.Expr), " ")
+		}
 
-	if resp.StatusCode != http.StatusOK {
-		// Читаем тело ответа для получения дополнительной информации об ошибке
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("GNews API вернул ошибку: %s, тело: %s", resp.Status, string(body))
+		result, err := provider.Search(ctx, providerReq)
+		if err != nil && ctx.Err() != nil {
+			// Запрос отменен вызывающим кодом, а не из-за сбоя провайдера.
+			// Ответа провайдера не было, поэтому квота не расходуется
+			breaker.Abort()
+			return nil, ctx.Err()
+		}
+		if f.quota != nil {
+			f.quota.Record(name)
+		}
+		if err != nil {
+			slog.Warn("Не удалось получить новости из провайдера", "provider", name, "topic", topic, "error", err)
+			providerErrorsTotal.Inc(name, errorStatus(err))
+			lastErr = err
+
+			var rateErr *RateLimitError
+			switch {
+			case errors.As(err, &rateErr):
+				// Лимит не восстановится от повторных запросов: размыкаем выключатель сразу,
+				// а исчерпанную до конца дня квоту запоминаем
+				breaker.Trip()
+				if f.quota != nil && !rateErr.ResetAt.Before(nextQuotaReset(time.Now())) {
+					f.quota.Exhaust(name)
+					quotaResetAt = earliest(quotaResetAt, rateErr.ResetAt)
+					continue
+				}
+			case errors.Is(err, ErrUnauthorized):
+				// Неверный ключ сам не исправится
+				breaker.Trip()
+			case errors.Is(err, ErrBadQuery):
+				// Провайдер доступен, ошибка в запросе
+				breaker.Success()
+			default:
+				breaker.Failure()
+			}
+			otherErrors = true
+			continue
+		}
+		breaker.Success()
+		if postFilter {
+			result = filterArticles(result, req.Expr)
+		}
+
+		succeeded = true
+		if len(result) > 0 {
+			f.mu.Lock()
+			f.lastAPIUsed = name
+			f.mu.Unlock()
+			return result, nil
+		}
+		slog.Debug("Провайдер не вернул новостей, пробую следующий", "provider", name, "topic", topic)
 	}
 
-	var gnewsResponse GNewsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&gnewsResponse); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования JSON от GNews: %w", err)
+	// Если ответить не смог ни один провайдер только из-за квот, сообщаем время их обновления
+	if !succeeded && !otherErrors && !quotaResetAt.IsZero() {
+		return nil, &QuotaExhaustedError{ResetAt: quotaResetAt}
 	}
 
-	log.Printf("Получено %d статей из GNews API по теме '%s'", len(gnewsResponse.Articles), topic)
-	if len(gnewsResponse.Articles) > 0 {
-		log.Printf("Первая статья из GNews: '%s', опубликована: %s",
-			gnewsResponse.Articles[0].Title,
-			gnewsResponse.Articles[0].PublishedAt.Format("2006-01-02 15:04:05"))
+	// Если ни один провайдер не ответил успешно, возвращаем последнюю ошибку
+	if !succeeded && lastErr != nil {
+		return nil, fmt.Errorf("не удалось получить новости из доступных источников: %w", lastErr)
 	}
 
-	f.LastAPIUsed = "GNews"
-	return gnewsResponse.Articles, nil
+	return []Article{}, nil
 }
 
-// FetchNews получает новости по теме из доступных источников.
-func (f *Fetcher) FetchNews(topic string) ([]Article, error) {
-	// Проверяем, не пустая ли тема
-	if topic == "" {
-		return nil, fmt.Errorf("тема не может быть пустой")
-	}
-
-	// Сначала пробуем GNews API
-	articles, err := f.FetchNewsFromGNews(topic)
-	if err == nil && len(articles) > 0 {
-		return articles, nil
+// earliest возвращает более раннее из двух времен, считая нулевое время отсутствующим.
+func earliest(current, candidate time.Time) time.Time {
+	if current.IsZero() || candidate.Before(current) {
+		return candidate
 	}
+	return current
+}
 
-	// Если GNews не удалось или нет результатов, пробуем News API
-	if f.NewsAPIKey != "" {
-		log.Printf("Не удалось получить новости из GNews API: %v. Пробую News API...", err)
-		articles, err2 := f.FetchNewsFromNewsAPI(topic)
-		if err2 == nil {
-			return articles, nil
+// filterArticles оставляет статьи, подходящие под запрос.
+func filterArticles(articles []Article, expr query.Node) []Article {
+	filtered := articles[:0:0]
+	for _, article := range articles {
+		if matchesQuery(article, expr) {
+			filtered = append(filtered, article)
 		}
-		log.Printf("Не удалось получить новости из News API: %v", err2)
 	}
+	return filtered
+}
 
-	// Если не удалось получить новости ни из одного API
-	if err != nil {
-		return nil, fmt.Errorf("не удалось получить новости из доступных источников: %v", err)
+// limitResults возвращает количество запрашиваемых статей с учетом ограничения провайдера.
+func limitResults(requested, max int) int {
+	if requested <= 0 || requested > max {
+		return max
 	}
-
-	return articles, nil
+	return requested
 }
@@ -0,0 +1,117 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// GNewsResponse представляет полный ответ от API GNews.
+type GNewsResponse struct {
+	TotalArticles int       `json:"totalArticles"`
+	Articles      []Article `json:"articles"`
+}
+
+// GNewsProvider получает новости из GNews API.
+type GNewsProvider struct {
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+// NewGNewsProvider создает провайдера GNews.
+func NewGNewsProvider(apiKey string, client *http.Client) *GNewsProvider {
+	return &GNewsProvider{
+		APIKey:     apiKey,
+		HTTPClient: client,
+	}
+}
+
+// Name возвращает имя провайдера.
+func (p *GNewsProvider) Name() string {
+	return "gnews"
+}
+
+// Capabilities возвращает возможности GNews API.
+func (p *GNewsProvider) Capabilities() Capabilities {
+	return Capabilities{
+		RequiresAPIKey:   true,
+		SupportsLanguage: true,
+		SupportsCountry:  true,
+		MaxResults:       20,
+		BooleanQueries:   true,
+	}
+}
+
+// Search выполняет запрос к GNews API для получения новостей по теме
+func (p *GNewsProvider) Search(ctx context.Context, req SearchRequest) ([]Article, error) {
+	if p.APIKey == "" {
+		return nil, fmt.Errorf("ключ GNews API не настроен: %w", ErrUnauthorized)
+	}
+
+	topic := req.Query
+
+	expr, err := parseRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// GNews поддерживает AND, OR, NOT, фразы в кавычках и скобки.
+	// Исправления и синонимы тем уже подставлены в выражение Fetcher.
+	searchQuery := expr.String()
+
+	params := url.Values{}
+	params.Set("q", searchQuery)
+	if req.Country != "" {
+		params.Set("country", req.Country)
+	}
+	if req.Language != "" {
+		params.Set("lang", req.Language)
+	}
+	params.Set("sortby", "publishedAt")
+	params.Set("max", fmt.Sprintf("%d", limitResults(req.Limit, p.Capabilities().MaxResults)))
+	params.Set("token", p.APIKey)
+
+	// Формируем URL для запроса. Адрес содержит токен, поэтому в лог пишется только запрос
+	apiURL := "https://gnews.io/api/v4/search?" + params.Encode()
+	slog.Debug("Запрос к GNews API", "topic", topic, "query", searchQuery)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса к GNews: %w", err)
+	}
+
+	resp, err := p.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, transportError("GNews", err)
+	}
+	defer resp.Body.Close()
+
+	slog.Debug("Ответ от GNews API", "status", resp.StatusCode)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, gnewsError(resp)
+	}
+
+	var gnewsResponse GNewsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&gnewsResponse); err != nil {
+		return nil, fmt.Errorf("ошибка декодирования JSON от GNews: %w: %w", ErrUpstreamUnavailable, err)
+	}
+
+	slog.Debug("Получены статьи из GNews API", "topic", topic, "count", len(gnewsResponse.Articles))
+
+	return gnewsResponse.Articles, nil
+}
+
+// gnewsError переводит ответ GNews API с ошибкой в категорию ошибки.
+// GNews отвечает 403 при исчерпании дневной квоты, которая обновляется в полночь по UTC,
+// и 429 при превышении числа запросов в секунду.
+func gnewsError(resp *http.Response) error {
+	if resp.StatusCode == http.StatusForbidden {
+		return &RateLimitError{Provider: "GNews API", ResetAt: nextQuotaReset(time.Now())}
+	}
+	return classifyStatus("GNews API", resp)
+}
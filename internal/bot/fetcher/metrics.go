@@ -0,0 +1,31 @@
+package fetcher
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/pkg/metrics"
+)
+
+var providerErrorsTotal = metrics.NewCounter("newsbot_provider_errors_total",
+	"Ошибки запросов к провайдерам новостей. status - HTTP-код ответа, если провайдер ответил, иначе категория ошибки.",
+	"provider", "status")
+
+// errorStatus возвращает значение метки status для ошибки провайдера.
+func errorStatus(err error) string {
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) && providerErr.StatusCode > 0 {
+		return strconv.Itoa(providerErr.StatusCode)
+	}
+	switch {
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, ErrUnauthorized):
+		return "unauthorized"
+	case errors.Is(err, ErrBadQuery):
+		return "bad_query"
+	case errors.Is(err, ErrUpstreamUnavailable):
+		return "unavailable"
+	}
+	return "error"
+}
@@ -0,0 +1,161 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// NewsAPIResponse представляет ответ от News API
+type NewsAPIResponse struct {
+	Status       string `json:"status"`
+	TotalResults int    `json:"totalResults"`
+	Articles     []struct {
+		Source struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"source"`
+		Author      string    `json:"author"`
+		Title       string    `json:"title"`
+		Description string    `json:"description"`
+		URL         string    `json:"url"`
+		URLToImage  string    `json:"urlToImage"`
+		PublishedAt time.Time `json:"publishedAt"`
+		Content     string    `json:"content"`
+	} `json:"articles"`
+}
+
+// NewsAPIProvider получает новости из News API.
+type NewsAPIProvider struct {
+	APIKey     string
+	HTTPClient *http.Client
+}
+
+// NewNewsAPIProvider создает провайдера News API.
+func NewNewsAPIProvider(apiKey string, client *http.Client) *NewsAPIProvider {
+	return &NewsAPIProvider{
+		APIKey:     apiKey,
+		HTTPClient: client,
+	}
+}
+
+// Name возвращает имя провайдера.
+func (p *NewsAPIProvider) Name() string {
+	return "newsapi"
+}
+
+// Capabilities возвращает возможности News API.
+// Эндпоинт /v2/everything не поддерживает фильтр по стране.
+func (p *NewsAPIProvider) Capabilities() Capabilities {
+	return Capabilities{
+		RequiresAPIKey:   true,
+		SupportsLanguage: true,
+		SupportsCountry:  false,
+		MaxResults:       10,
+		BooleanQueries:   true,
+	}
+}
+
+// Search выполняет запрос к News API для получения новостей по теме
+func (p *NewsAPIProvider) Search(ctx context.Context, req SearchRequest) ([]Article, error) {
+	if p.APIKey == "" {
+		return nil, fmt.Errorf("ключ News API не настроен: %w", ErrUnauthorized)
+	}
+
+	topic := req.Query
+
+	expr, err := parseRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// News API поддерживает AND, OR, NOT, фразы в кавычках и скобки.
+	// Исправления и синонимы тем уже подставлены в выражение Fetcher.
+	searchQuery := expr.String()
+
+	params := url.Values{}
+	params.Set("q", searchQuery)
+	if req.Language != "" {
+		params.Set("language", req.Language)
+	}
+	params.Set("sortBy", "publishedAt")
+	params.Set("pageSize", fmt.Sprintf("%d", limitResults(req.Limit, p.Capabilities().MaxResults)))
+	params.Set("apiKey", p.APIKey)
+
+	// Формируем URL для запроса. Адрес содержит ключ, поэтому в лог пишется только запрос
+	apiURL := "https://newsapi.org/v2/everything?" + params.Encode()
+	slog.Debug("Запрос к News API", "topic", topic, "query", searchQuery)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса к News API: %w", err)
+	}
+
+	resp, err := p.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, transportError("News API", err)
+	}
+	defer resp.Body.Close()
+
+	slog.Debug("Ответ от News API", "status", resp.StatusCode)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newsAPIError(resp)
+	}
+
+	var newsAPIResponse NewsAPIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&newsAPIResponse); err != nil {
+		return nil, fmt.Errorf("ошибка декодирования JSON от News API: %w: %w", ErrUpstreamUnavailable, err)
+	}
+
+	slog.Debug("Получены статьи из News API", "topic", topic, "count", len(newsAPIResponse.Articles), "total", newsAPIResponse.TotalResults)
+
+	// Преобразуем в наш формат статей
+	articles := make([]Article, 0, len(newsAPIResponse.Articles))
+	for _, a := range newsAPIResponse.Articles {
+		articles = append(articles, Article{
+			Title:       a.Title,
+			Description: a.Description,
+			Content:     a.Content,
+			URL:         a.URL,
+			Image:       a.URLToImage,
+			PublishedAt: a.PublishedAt,
+			Source: Source{
+				Name: a.Source.Name,
+				URL:  "", // News API не предоставляет URL источника
+			},
+		})
+	}
+
+	return articles, nil
+}
+
+// newsAPIError переводит ответ News API с ошибкой в категорию ошибки по полю code
+// из тела ответа (https://newsapi.org/docs/errors). Само тело в ошибку не попадает.
+func newsAPIError(resp *http.Response) error {
+	var body struct {
+		Code string `json:"code"`
+	}
+	// Тело ограничено: для ошибки достаточно кода, а ответ может быть произвольным
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&body)
+
+	provider := "News API"
+	switch body.Code {
+	case "apiKeyExhausted":
+		return &RateLimitError{Provider: provider, ResetAt: nextQuotaReset(time.Now())}
+	case "rateLimited":
+		return &RateLimitError{Provider: provider, ResetAt: retryAfter(resp, time.Now(), time.Hour)}
+	case "apiKeyDisabled", "apiKeyInvalid", "apiKeyMissing":
+		return &ProviderError{Provider: provider, StatusCode: resp.StatusCode, Err: ErrUnauthorized}
+	case "parameterInvalid", "parametersMissing":
+		return &ProviderError{Provider: provider, StatusCode: resp.StatusCode, Err: ErrBadQuery}
+	case "unexpectedError":
+		return &ProviderError{Provider: provider, StatusCode: resp.StatusCode, Err: ErrUpstreamUnavailable}
+	}
+	return classifyStatus(provider, resp)
+}
@@ -0,0 +1,187 @@
+package fetcher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/query"
+)
+
+// SearchRequest описывает параметры поиска новостей у провайдера.
+type SearchRequest struct {
+	Query    string // Поисковый запрос (тема подписки или произвольный запрос)
+	Language string // Язык новостей, например "ru"
+	Country  string // Страна источников, например "ru"
+	Limit    int    // Желаемое количество статей
+	// Expr - разобранный Query. Заполняется Fetcher; провайдеры, которых вызывают
+	// напрямую, разбирают Query сами через parseRequest.
+	Expr query.Node
+}
+
+// Capabilities описывает возможности провайдера новостей.
+type Capabilities struct {
+	RequiresAPIKey   bool // Провайдеру нужен ключ API
+	SupportsLanguage bool // Провайдер умеет фильтровать по языку
+	SupportsCountry  bool // Провайдер умеет фильтровать по стране
+	MaxResults       int  // Максимальное количество статей за один запрос
+	// BooleanQueries - провайдер понимает AND, OR, NOT, фразы и скобки.
+	// Остальным передаются только ключевые слова, а результат фильтруется локально.
+	BooleanQueries bool
+}
+
+// NewsProvider определяет источник новостей, который может использовать Fetcher.
+type NewsProvider interface {
+	// Name возвращает уникальное имя провайдера, используемое в конфигурации.
+	Name() string
+	// Search выполняет поиск статей по запросу. Отмена ctx прерывает HTTP-запросы.
+	Search(ctx context.Context, req SearchRequest) ([]Article, error)
+	// Capabilities возвращает возможности провайдера.
+	Capabilities() Capabilities
+}
+
+// Registry хранит зарегистрированных провайдеров, их порядок и состояние.
+// Порядок определяет цепочку запасных источников в FetchNews.
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]NewsProvider
+	order     []string
+	disabled  map[string]bool
+}
+
+// NewRegistry создает пустой реестр провайдеров.
+func NewRegistry() *Registry {
+	return &Registry{
+		providers: make(map[string]NewsProvider),
+		disabled:  make(map[string]bool),
+	}
+}
+
+// Register добавляет провайдера в конец цепочки.
+func (r *Registry) Register(p NewsProvider) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := normalizeProviderName(p.Name())
+	if _, exists := r.providers[name]; exists {
+		return fmt.Errorf("провайдер '%s' уже зарегистрирован", name)
+	}
+
+	r.providers[name] = p
+	r.order = append(r.order, name)
+	return nil
+}
+
+// Configure задает порядок провайдеров по списку имен из конфигурации.
+// Провайдеры, не указанные в списке, отключаются. Пустой список оставляет
+// порядок регистрации и включает всех провайдеров.
+func (r *Registry) Configure(names []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(names) == 0 {
+		r.disabled = make(map[string]bool)
+		return nil
+	}
+
+	order := make([]string, 0, len(r.providers))
+	enabled := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = normalizeProviderName(name)
+		if name == "" || enabled[name] {
+			continue
+		}
+		if _, ok := r.providers[name]; !ok {
+			return fmt.Errorf("неизвестный провайдер новостей: '%s'", name)
+		}
+		enabled[name] = true
+		order = append(order, name)
+	}
+
+	// Отключенные провайдеры остаются в реестре, чтобы их можно было включить позже
+	disabled := make(map[string]bool)
+	for _, name := range r.order {
+		if !enabled[name] {
+			order = append(order, name)
+			disabled[name] = true
+		}
+	}
+
+	r.order = order
+	r.disabled = disabled
+	return nil
+}
+
+// SetEnabled включает или отключает провайдера по имени.
+func (r *Registry) SetEnabled(name string, enabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name = normalizeProviderName(name)
+	if _, ok := r.providers[name]; !ok {
+		return fmt.Errorf("неизвестный провайдер новостей: '%s'", name)
+	}
+
+	if enabled {
+		delete(r.disabled, name)
+	} else {
+		r.disabled[name] = true
+	}
+	return nil
+}
+
+// IsEnabled сообщает, включен ли провайдер.
+func (r *Registry) IsEnabled(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	name = normalizeProviderName(name)
+	_, ok := r.providers[name]
+	return ok && !r.disabled[name]
+}
+
+// Names возвращает имена всех зарегистрированных провайдеров в порядке цепочки.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, len(r.order))
+	copy(names, r.order)
+	return names
+}
+
+// Enabled возвращает включенных провайдеров в порядке цепочки.
+func (r *Registry) Enabled() []NewsProvider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	providers := make([]NewsProvider, 0, len(r.order))
+	for _, name := range r.order {
+		if !r.disabled[name] {
+			providers = append(providers, r.providers[name])
+		}
+	}
+	return providers
+}
+
+// parseRequest возвращает разобранный запрос, при необходимости разбирая Query.
+func parseRequest(req SearchRequest) (query.Node, error) {
+	if req.Expr != nil {
+		return req.Expr, nil
+	}
+	expr, err := query.Parse(req.Query)
+	if err != nil {
+		return nil, fmt.Errorf("%w '%s': %w", ErrBadQuery, req.Query, err)
+	}
+	return expr, nil
+}
+
+// matchesQuery проверяет, что заголовок или описание статьи подходят под запрос.
+func matchesQuery(article Article, expr query.Node) bool {
+	return query.Match(expr, article.Title+" "+article.Description)
+}
+
+func normalizeProviderName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
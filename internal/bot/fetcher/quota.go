@@ -0,0 +1,221 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// quotaDayLayout - формат дня, за который считаются запросы к провайдеру.
+const quotaDayLayout = "2006-01-02"
+
+// quotaSaveInterval - как часто расход квоты сохраняется в хранилище. Между сохранениями
+// запросы учитываются только в памяти; остаток сохраняет Flush при остановке бота.
+const quotaSaveInterval = 30 * time.Second
+
+// QuotaStore определяет постоянное хранилище счетчиков дневной квоты,
+// чтобы перезапуск бота не обнулял израсходованные запросы.
+type QuotaStore interface {
+	LoadProviderQuota(ctx context.Context, provider, day string) (used int, exhausted bool, err error)
+	SaveProviderQuota(ctx context.Context, provider, day string, used int, exhausted bool) error
+}
+
+// QuotaExhaustedError возвращается, когда все провайдеры, которые могли бы ответить,
+// израсходовали дневную квоту.
+type QuotaExhaustedError struct {
+	ResetAt time.Time // Когда квота обновится
+}
+
+func (e *QuotaExhaustedError) Error() string {
+	return fmt.Sprintf("дневная квота запросов к провайдерам новостей исчерпана до %s UTC", e.ResetAt.UTC().Format("15:04"))
+}
+
+// Is относит ошибку к категории ErrRateLimited.
+func (e *QuotaExhaustedError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// QuotaUsage - расход квоты провайдера за текущий день.
+type QuotaUsage struct {
+	Used      int       // Выполнено запросов
+	Limit     int       // Дневной лимит; 0 - без ограничения
+	Exhausted bool      // Провайдер сам сообщил, что лимит исчерпан
+	ResetAt   time.Time // Когда счетчик обнулится
+}
+
+// quotaCounter - счетчик запросов к провайдеру за день day.
+type quotaCounter struct {
+	day       string
+	used      int
+	exhausted bool
+	dirty     bool // Изменения еще не сохранены в хранилище
+}
+
+// Quota считает запросы к провайдерам за сутки по UTC (так сбрасывают лимиты GNews
+// и NewsAPI) и не пропускает запросы к провайдеру, израсходовавшему дневной лимит.
+// Расход сохраняется в хранилище не чаще раза в quotaSaveInterval, исчерпание квоты -
+// сразу.
+type Quota struct {
+	limits map[string]int
+	store  QuotaStore
+
+	mu       sync.Mutex
+	counters map[string]*quotaCounter
+	savedAt  time.Time // Время последнего сохранения в хранилище
+
+	flushMu sync.Mutex // Сохранения выполняются по очереди, чтобы старый снимок не перезаписал новый
+}
+
+// NewQuota создает счетчик квот. limits задает дневной лимит по имени провайдера;
+// провайдеры без лимита только учитываются. store может быть nil.
+func NewQuota(limits map[string]int, store QuotaStore) *Quota {
+	normalized := make(map[string]int, len(limits))
+	for name, limit := range limits {
+		normalized[normalizeProviderName(name)] = limit
+	}
+	return &Quota{
+		limits:   normalized,
+		store:    store,
+		counters: make(map[string]*quotaCounter),
+	}
+}
+
+// Allow сообщает, можно ли отправить запрос провайдеру. Если нельзя,
+// возвращает время обновления квоты.
+func (q *Quota) Allow(provider string) (bool, time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	name := normalizeProviderName(provider)
+	counter := q.counter(name)
+	limit := q.limits[name]
+	if counter.exhausted || (limit > 0 && counter.used >= limit) {
+		return false, nextQuotaReset(time.Now())
+	}
+	return true, time.Time{}
+}
+
+// Record учитывает запрос, на который провайдер ответил.
+func (q *Quota) Record(provider string) {
+	q.mu.Lock()
+	counter := q.counter(normalizeProviderName(provider))
+	counter.used++
+	counter.dirty = true
+	due := time.Since(q.savedAt) >= quotaSaveInterval
+	q.mu.Unlock()
+
+	if due {
+		q.save()
+	}
+}
+
+// Exhaust помечает квоту провайдера исчерпанной до конца дня, например после ответа 429.
+// Исчерпание сохраняется сразу: после перезапуска провайдер не должен получать запросы.
+func (q *Quota) Exhaust(provider string) {
+	q.mu.Lock()
+	counter := q.counter(normalizeProviderName(provider))
+	counter.exhausted = true
+	counter.dirty = true
+	q.mu.Unlock()
+
+	q.save()
+}
+
+// Flush сохраняет в хранилище расход, накопленный с последнего сохранения.
+// Вызывается при остановке бота.
+func (q *Quota) Flush(ctx context.Context) error {
+	if q.store == nil {
+		return nil
+	}
+
+	q.flushMu.Lock()
+	defer q.flushMu.Unlock()
+
+	type pending struct {
+		name    string
+		counter quotaCounter
+	}
+	q.mu.Lock()
+	q.savedAt = time.Now()
+	var changed []pending
+	for name, counter := range q.counters {
+		if counter.dirty {
+			counter.dirty = false
+			changed = append(changed, pending{name: name, counter: *counter})
+		}
+	}
+	q.mu.Unlock()
+
+	var errs []error
+	for _, p := range changed {
+		if err := q.store.SaveProviderQuota(ctx, p.name, p.counter.day, p.counter.used, p.counter.exhausted); err != nil {
+			// Счетчик сохранится при следующей попытке, если день еще не сменился
+			q.mu.Lock()
+			if counter, ok := q.counters[p.name]; ok && counter.day == p.counter.day {
+				counter.dirty = true
+			}
+			q.mu.Unlock()
+			errs = append(errs, fmt.Errorf("не удалось сохранить квоту провайдера %s: %w", p.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Usage возвращает расход квоты провайдера за текущий день.
+func (q *Quota) Usage(provider string) QuotaUsage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	name := normalizeProviderName(provider)
+	counter := q.counter(name)
+	return QuotaUsage{
+		Used:      counter.used,
+		Limit:     q.limits[name],
+		Exhausted: counter.exhausted,
+		ResetAt:   nextQuotaReset(time.Now()),
+	}
+}
+
+// save сохраняет изменившиеся счетчики, записывая ошибки в лог.
+func (q *Quota) save() {
+	if err := q.Flush(context.Background()); err != nil {
+		slog.Error("Не удалось сохранить квоту провайдера", "error", err)
+	}
+}
+
+// counter возвращает счетчик провайдера за текущий день, при смене дня начиная новый.
+// Счетчик впервые за день читается из хранилища. Вызывается под mu.
+func (q *Quota) counter(name string) *quotaCounter {
+	day := time.Now().UTC().Format(quotaDayLayout)
+	counter, ok := q.counters[name]
+	if ok && counter.day == day {
+		return counter
+	}
+	if ok && counter.dirty && q.store != nil {
+		// Расход за прошедший день сохраняется до того, как счетчик будет заменен
+		if err := q.store.SaveProviderQuota(context.Background(), name, counter.day, counter.used, counter.exhausted); err != nil {
+			slog.Error("Не удалось сохранить квоту провайдера", "provider", name, "error", err)
+		}
+	}
+
+	counter = &quotaCounter{day: day}
+	if q.store != nil {
+		used, exhausted, err := q.store.LoadProviderQuota(context.Background(), name, day)
+		if err != nil {
+			slog.Error("Не удалось загрузить квоту провайдера", "provider", name, "error", err)
+		} else {
+			counter.used, counter.exhausted = used, exhausted
+		}
+	}
+	q.counters[name] = counter
+	return counter
+}
+
+// nextQuotaReset возвращает ближайшую полночь по UTC.
+func nextQuotaReset(now time.Time) time.Time {
+	year, month, day := now.UTC().Date()
+	return time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC)
+}
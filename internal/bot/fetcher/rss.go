@@ -0,0 +1,356 @@
+package fetcher
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// rssDocument описывает ленту RSS 2.0 или Atom. Корневой элемент
+// определяет формат: <rss> с <channel> или <feed> с <entry>.
+type rssDocument struct {
+	XMLName xml.Name
+	Channel struct {
+		Title string    `xml:"title"`
+		Links rssLinks  `xml:"link"`
+		Items []rssItem `xml:"item"`
+	} `xml:"channel"`
+	Title   string      `xml:"title"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Description string   `xml:"description"`
+	Links       rssLinks `xml:"link"`
+	GUID        string   `xml:"guid"`
+	PubDate     string   `xml:"pubDate"`
+	Source      struct {
+		Name string `xml:",chardata"`
+		URL  string `xml:"url,attr"`
+	} `xml:"source"`
+	Enclosures []struct {
+		URL  string `xml:"url,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"enclosure"`
+	MediaContent []struct {
+		URL    string `xml:"url,attr"`
+		Medium string `xml:"medium,attr"`
+		Type   string `xml:"type,attr"`
+	} `xml:"http://search.yahoo.com/mrss/ content"`
+	MediaThumbnail struct {
+		URL string `xml:"url,attr"`
+	} `xml:"http://search.yahoo.com/mrss/ thumbnail"`
+}
+
+// rssLinks - элементы <link> канала или записи RSS. Тег link совпадает и с
+// <atom:link rel="self" href="..."/>, который добавляют многие ленты RSS 2.0,
+// поэтому собираются все элементы, а ссылкой считается элемент без пространства имен.
+type rssLinks []struct {
+	XMLName xml.Name
+	Value   string `xml:",chardata"`
+}
+
+// url возвращает адрес из элемента <link> без пространства имен.
+func (links rssLinks) url() string {
+	for _, link := range links {
+		if link.XMLName.Space == "" {
+			return strings.TrimSpace(link.Value)
+		}
+	}
+	return ""
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type atomEntry struct {
+	Title     string     `xml:"title"`
+	Summary   string     `xml:"summary"`
+	Content   string     `xml:"content"`
+	Links     []atomLink `xml:"link"`
+	ID        string     `xml:"id"`
+	Published string     `xml:"published"`
+	Updated   string     `xml:"updated"`
+	Source    struct {
+		Title string `xml:"title"`
+	} `xml:"source"`
+}
+
+// rssDateLayouts - форматы дат, встречающиеся в RSS и Atom лентах.
+var rssDateLayouts = []string{
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC3339,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+}
+
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// RSSProvider получает новости из RSS 2.0 и Atom лент и отбирает записи,
+// в которых встречаются все ключевые слова запроса.
+type RSSProvider struct {
+	FeedURLs   []string
+	HTTPClient *http.Client
+}
+
+// NewRSSProvider создает провайдера для указанных лент.
+func NewRSSProvider(feedURLs []string, client *http.Client) *RSSProvider {
+	return &RSSProvider{
+		FeedURLs:   feedURLs,
+		HTTPClient: client,
+	}
+}
+
+// Name возвращает имя провайдера.
+func (p *RSSProvider) Name() string {
+	return "rss"
+}
+
+// Capabilities возвращает возможности RSS-провайдера. Ленты не поддерживают
+// фильтрацию на стороне источника, поэтому язык и страна игнорируются.
+func (p *RSSProvider) Capabilities() Capabilities {
+	return Capabilities{
+		RequiresAPIKey:   false,
+		SupportsLanguage: false,
+		SupportsCountry:  false,
+		MaxResults:       50,
+		BooleanQueries:   true, // Запрос проверяется локально по заголовку и описанию
+	}
+}
+
+// Search загружает все ленты и возвращает записи, подходящие под запрос,
+// отсортированные от новых к старым.
+func (p *RSSProvider) Search(ctx context.Context, req SearchRequest) ([]Article, error) {
+	if len(p.FeedURLs) == 0 {
+		return nil, fmt.Errorf("RSS-ленты не настроены")
+	}
+
+	expr, err := parseRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		articles []Article
+		errs     []error
+	)
+	for _, feedURL := range p.FeedURLs {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		feedArticles, err := p.fetchFeed(ctx, feedURL)
+		if err != nil {
+			slog.Warn("Ошибка загрузки RSS-ленты", "feed", feedURL, "error", err)
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, article := range feedArticles {
+			if matchesQuery(article, expr) {
+				articles = append(articles, article)
+			}
+		}
+	}
+
+	// Ошибка возвращается, только если не удалось загрузить ни одной ленты
+	if len(errs) == len(p.FeedURLs) {
+		return nil, fmt.Errorf("не удалось загрузить RSS-ленты: %w", errors.Join(errs...))
+	}
+
+	sort.SliceStable(articles, func(i, j int) bool {
+		return articles[i].PublishedAt.After(articles[j].PublishedAt)
+	})
+
+	limit := limitResults(req.Limit, p.Capabilities().MaxResults)
+	if len(articles) > limit {
+		articles = articles[:limit]
+	}
+
+	slog.Debug("Найдены статьи в RSS-лентах", "topic", req.Query, "count", len(articles))
+	return articles, nil
+}
+
+// fetchFeed загружает и разбирает одну ленту.
+func (p *RSSProvider) fetchFeed(ctx context.Context, feedURL string) ([]Article, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса к RSS-ленте: %w", err)
+	}
+	httpReq.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml, text/xml")
+
+	resp, err := p.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка выполнения запроса к RSS-ленте: %w: %w", ErrUpstreamUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// Для ленты любой код, кроме 200, означает, что она сейчас недоступна
+		return nil, &ProviderError{Provider: "RSS-лента", StatusCode: resp.StatusCode, Err: ErrUpstreamUnavailable}
+	}
+
+	articles, err := ParseFeed(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrUpstreamUnavailable, err)
+	}
+	return articles, nil
+}
+
+// ParseFeed разбирает ленту RSS 2.0 или Atom и преобразует записи в статьи.
+func ParseFeed(r io.Reader) ([]Article, error) {
+	decoder := xml.NewDecoder(r)
+	// Ленты нередко объявляют кодировку windows-1251, koi8-r и т.п.;
+	// содержимое перекодируется в UTF-8 по объявленной кодировке.
+	decoder.CharsetReader = charsetReader
+
+	var doc rssDocument
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("ошибка разбора RSS-ленты: %w", err)
+	}
+
+	switch doc.XMLName.Local {
+	case "rss":
+		return doc.rssArticles(), nil
+	case "feed":
+		return doc.atomArticles(), nil
+	default:
+		return nil, fmt.Errorf("неизвестный формат ленты: <%s>", doc.XMLName.Local)
+	}
+}
+
+// charsetReader перекодирует содержимое ленты из объявленной кодировки в UTF-8.
+// Названия кодировок распознаются так же, как в браузерах.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	enc, err := htmlindex.Get(charset)
+	if err != nil {
+		return nil, fmt.Errorf("неподдерживаемая кодировка ленты %q: %w", charset, err)
+	}
+	return enc.NewDecoder().Reader(input), nil
+}
+
+func (doc *rssDocument) rssArticles() []Article {
+	articles := make([]Article, 0, len(doc.Channel.Items))
+	for _, item := range doc.Channel.Items {
+		link := item.Links.url()
+		if link == "" && strings.HasPrefix(item.GUID, "http") {
+			link = strings.TrimSpace(item.GUID)
+		}
+
+		source := Source{Name: strings.TrimSpace(doc.Channel.Title), URL: doc.Channel.Links.url()}
+		if name := strings.TrimSpace(item.Source.Name); name != "" {
+			source = Source{Name: name, URL: item.Source.URL}
+		}
+
+		articles = append(articles, Article{
+			Title:       cleanFeedText(item.Title),
+			Description: cleanFeedText(item.Description),
+			URL:         link,
+			Image:       item.imageURL(),
+			PublishedAt: parseFeedDate(item.PubDate),
+			Source:      source,
+		})
+	}
+	return articles
+}
+
+func (item *rssItem) imageURL() string {
+	for _, enclosure := range item.Enclosures {
+		if strings.HasPrefix(enclosure.Type, "image/") {
+			return enclosure.URL
+		}
+	}
+	for _, media := range item.MediaContent {
+		if media.Medium == "image" || strings.HasPrefix(media.Type, "image/") {
+			return media.URL
+		}
+	}
+	return item.MediaThumbnail.URL
+}
+
+func (doc *rssDocument) atomArticles() []Article {
+	feedLink := alternateLink(doc.Links)
+
+	articles := make([]Article, 0, len(doc.Entries))
+	for _, entry := range doc.Entries {
+		description := entry.Summary
+		if strings.TrimSpace(description) == "" {
+			description = entry.Content
+		}
+
+		published := entry.Published
+		if published == "" {
+			published = entry.Updated
+		}
+
+		sourceName := strings.TrimSpace(doc.Title)
+		if name := strings.TrimSpace(entry.Source.Title); name != "" {
+			sourceName = name
+		}
+
+		var image string
+		for _, link := range entry.Links {
+			if link.Rel == "enclosure" && strings.HasPrefix(link.Type, "image/") {
+				image = link.Href
+				break
+			}
+		}
+
+		articles = append(articles, Article{
+			Title:       cleanFeedText(entry.Title),
+			Description: cleanFeedText(description),
+			URL:         alternateLink(entry.Links),
+			Image:       image,
+			PublishedAt: parseFeedDate(published),
+			Source:      Source{Name: sourceName, URL: feedLink},
+		})
+	}
+	return articles
+}
+
+// alternateLink возвращает основную ссылку Atom-элемента.
+func alternateLink(links []atomLink) string {
+	for _, link := range links {
+		if link.Rel == "" || link.Rel == "alternate" {
+			return strings.TrimSpace(link.Href)
+		}
+	}
+	return ""
+}
+
+// parseFeedDate разбирает дату публикации в одном из распространенных форматов.
+func parseFeedDate(value string) time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range rssDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// cleanFeedText убирает HTML-разметку и лишние пробелы из текста ленты.
+func cleanFeedText(value string) string {
+	value = htmlTagPattern.ReplaceAllString(value, " ")
+	value = html.UnescapeString(value)
+	return strings.Join(strings.Fields(value), " ")
+}
@@ -0,0 +1,248 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+)
+
+const (
+	statsTopTopics        = 10               // Сколько популярных тем показывает /stats
+	statsDays             = 7                // За сколько дней /stats показывает отправленные статьи
+	broadcastBatchSize    = 100              // Сколько пользователей рассылка читает из базы за раз
+	broadcastProgressEach = 10 * time.Second // Как часто обновляется отчет о ходе рассылки
+	broadcastConfirmTTL   = 5 * time.Minute  // Сколько рассылка ждет подтверждения
+)
+
+// pendingBroadcast - рассылка, ожидающая подтверждения администратора.
+type pendingBroadcast struct {
+	text      string
+	createdAt time.Time
+}
+
+// adminHelp описывает команды администратора.
+const adminHelp = "*Команды администратора:*\n" +
+	"`/users` - количество пользователей и активность\n" +
+	"`/stats` - популярные темы и отправленные статьи по дням\n" +
+	"`/broadcast текст` - разослать сообщение всем пользователям после подтверждения\n" +
+	"`/alias` - исправления и синонимы тем"
+
+// isAdmin проверяет, входит ли пользователь в список администраторов из конфигурации.
+func (h *Handler) isAdmin(telegramID int64) bool {
+	return h.admins[telegramID]
+}
+
+// handleUsersCommand отправляет администратору количество пользователей и их активность.
+func (h *Handler) handleUsersCommand(ctx context.Context, chatID int64) {
+	now := time.Now()
+	periods := []struct {
+		title string
+		since time.Time
+	}{
+		{"за сутки", now.Add(-24 * time.Hour)},
+		{"за 7 дней", now.AddDate(0, 0, -7)},
+		{"за 30 дней", now.AddDate(0, 0, -30)},
+	}
+
+	var builder strings.Builder
+	for i, period := range periods {
+		stats, err := h.statsRepo.GetUserStats(ctx, period.since)
+		if err != nil {
+			slog.Error("Ошибка получения статистики пользователей", "error", err)
+			h.sendMsg(chatID, "Не удалось получить статистику пользователей.")
+			return
+		}
+		if i == 0 {
+			builder.WriteString("👥 *Пользователи*\n\n")
+			builder.WriteString(fmt.Sprintf("Всего: %d\n", stats.Total))
+			builder.WriteString(fmt.Sprintf("С подписками: %d\n\n", stats.Subscribed))
+			builder.WriteString("*Получали новости / новые:*\n")
+		}
+		builder.WriteString(fmt.Sprintf("• %s: %d / %d\n", period.title, stats.Active, stats.New))
+	}
+	h.sendMsg(chatID, builder.String())
+}
+
+// handleStatsCommand отправляет администратору популярные темы и количество отправленных статей по дням.
+func (h *Handler) handleStatsCommand(ctx context.Context, chatID int64) {
+	topics, err := h.statsRepo.GetTopTopics(ctx, statsTopTopics)
+	if err != nil {
+		slog.Error("Ошибка получения популярных тем", "error", err)
+		h.sendMsg(chatID, "Не удалось получить статистику.")
+		return
+	}
+	since := time.Now().AddDate(0, 0, -(statsDays - 1))
+	days, err := h.statsRepo.CountSentArticlesByDay(ctx, time.Date(since.Year(), since.Month(), since.Day(), 0, 0, 0, 0, since.Location()))
+	if err != nil {
+		slog.Error("Ошибка получения статистики отправок", "error", err)
+		h.sendMsg(chatID, "Не удалось получить статистику.")
+		return
+	}
+
+	var builder strings.Builder
+	builder.WriteString("🔥 *Популярные темы:*\n")
+	if len(topics) == 0 {
+		builder.WriteString("Подписок пока нет.\n")
+	}
+	for i, topic := range topics {
+		builder.WriteString(fmt.Sprintf("%d. %s - %d\n", i+1, tgbotapi.EscapeText(tgbotapi.ModeMarkdown, topic.Topic), topic.Subscribers))
+	}
+
+	builder.WriteString(fmt.Sprintf("\n📨 *Отправлено статей за %d дней:*\n", statsDays))
+	if len(days) == 0 {
+		builder.WriteString("Статьи не отправлялись.\n")
+	}
+	var total int64
+	for _, day := range days {
+		builder.WriteString(fmt.Sprintf("%s - %d\n", day.Day, day.Count))
+		total += day.Count
+	}
+	if len(days) > 0 {
+		builder.WriteString(fmt.Sprintf("Всего: %d\n", total))
+	}
+	h.sendMsg(chatID, builder.String())
+}
+
+// handleBroadcastCommand запоминает текст рассылки и просит администратора подтвердить ее.
+// Текст рассылается как есть, без разметки, поэтому предпросмотр тоже отправляется без нее.
+func (h *Handler) handleBroadcastCommand(ctx context.Context, user *database.User, text string, chatID int64) {
+	if text == "" {
+		h.sendMsg(chatID, adminHelp)
+		return
+	}
+	stats, err := h.statsRepo.GetUserStats(ctx, time.Now())
+	if err != nil {
+		slog.Error("Ошибка получения статистики пользователей", "error", err)
+		h.sendMsg(chatID, "Не удалось подготовить рассылку.")
+		return
+	}
+
+	h.broadcastMu.Lock()
+	h.pendingBroadcasts[user.TelegramID] = pendingBroadcast{text: text, createdAt: time.Now()}
+	h.broadcastMu.Unlock()
+
+	msg := tgbotapi.NewMessage(chatID, fmt.Sprintf("📣 Рассылка для %d пользователей:\n\n%s\n\nОтправить?", stats.Total, text))
+	msg.ReplyMarkup = tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("✅ Отправить", "broadcast_confirm"),
+		tgbotapi.NewInlineKeyboardButtonData("❌ Отмена", "broadcast_cancel"),
+	))
+	if _, err := h.sender.Send(ctx, msg); err != nil {
+		slog.Warn("Ошибка при отправке сообщения", "error", err)
+	}
+}
+
+// Обработчик кнопок подтверждения и отмены рассылки
+func (h *Handler) handleBroadcastCallback(callback *tgbotapi.CallbackQuery) {
+	if !h.isAdmin(callback.From.ID) {
+		h.answerCallback(callback, "Недостаточно прав.")
+		return
+	}
+
+	h.broadcastMu.Lock()
+	pending, ok := h.pendingBroadcasts[callback.From.ID]
+	delete(h.pendingBroadcasts, callback.From.ID)
+	h.broadcastMu.Unlock()
+
+	chatID, messageID := callback.Message.Chat.ID, callback.Message.MessageID
+	switch {
+	case callback.Data == "broadcast_cancel":
+		h.answerCallback(callback, "Рассылка отменена")
+		h.editBroadcastReport(chatID, messageID, "❌ Рассылка отменена.")
+	case !ok || time.Since(pending.createdAt) > broadcastConfirmTTL:
+		// Подтверждение, нажатое спустя долгое время, скорее всего случайно
+		h.answerCallback(callback, "Рассылка устарела. Отправьте /broadcast еще раз.")
+		if ok {
+			h.editBroadcastReport(chatID, messageID, "⌛ Рассылка не подтверждена вовремя.")
+		}
+	case !h.broadcasting.CompareAndSwap(false, true):
+		h.answerCallback(callback, "Предыдущая рассылка еще не завершена.")
+	case !h.startBackground(func(ctx context.Context) {
+		defer h.broadcasting.Store(false)
+		h.editBroadcastReport(chatID, messageID, "📣 Рассылка запущена...")
+		h.runBroadcast(ctx, pending.text, chatID, messageID)
+	}):
+		h.broadcasting.Store(false)
+		h.answerCallback(callback, "Бот останавливается, рассылка не запущена.")
+	default:
+		h.answerCallback(callback, "Рассылка запущена")
+	}
+}
+
+// startBackground запускает fn в отдельной горутине, которую дожидается Stop.
+// Возвращает false, если обработчик уже остановлен.
+func (h *Handler) startBackground(fn func(ctx context.Context)) bool {
+	h.broadcastMu.Lock()
+	defer h.broadcastMu.Unlock()
+	if h.ctx.Err() != nil {
+		return false
+	}
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		fn(h.ctx)
+	}()
+	return true
+}
+
+// runBroadcast отправляет текст всем пользователям через общий отправитель, который
+// соблюдает лимиты Telegram, и периодически обновляет отчет о ходе рассылки.
+func (h *Handler) runBroadcast(ctx context.Context, text string, chatID int64, messageID int) {
+	stats, err := h.statsRepo.GetUserStats(ctx, time.Now())
+	if err != nil {
+		slog.Error("Ошибка получения статистики пользователей", "error", err)
+	}
+
+	var sent, failed int
+	lastReport := time.Now()
+	var afterID uint
+	for {
+		if ctx.Err() != nil {
+			// Бот останавливается: оставшиеся пользователи рассылку не получат
+			slog.Info("Рассылка прервана остановкой бота", "sent", sent, "failed", failed)
+			h.editBroadcastReport(chatID, messageID, fmt.Sprintf("⚠️ Рассылка прервана остановкой бота. Отправлено: %d, ошибок: %d.", sent, failed))
+			return
+		}
+		users, err := h.userRepo.GetUsersBatch(ctx, afterID, broadcastBatchSize)
+		if err != nil {
+			slog.Error("Рассылка: ошибка получения пользователей", "after_id", afterID, "error", err)
+			h.editBroadcastReport(chatID, messageID, fmt.Sprintf("⚠️ Рассылка прервана: не удалось получить пользователей. Отправлено: %d, ошибок: %d.", sent, failed))
+			return
+		}
+		if len(users) == 0 {
+			break
+		}
+		afterID = users[len(users)-1].ID
+
+		for _, user := range users {
+			if ctx.Err() != nil {
+				break
+			}
+			if _, err := h.sender.Send(ctx, tgbotapi.NewMessage(user.TelegramID, text)); err != nil {
+				// Пользователи, заблокировавшие бота, не прерывают рассылку
+				slog.Debug("Рассылка: сообщение не доставлено", "telegram_id", user.TelegramID, "error", err)
+				failed++
+			} else {
+				sent++
+			}
+			if time.Since(lastReport) >= broadcastProgressEach {
+				lastReport = time.Now()
+				h.editBroadcastReport(chatID, messageID, fmt.Sprintf("📣 Рассылка: отправлено %d из %d, ошибок: %d...", sent+failed, stats.Total, failed))
+			}
+		}
+	}
+
+	slog.Info("Рассылка завершена", "sent", sent, "failed", failed)
+	h.editBroadcastReport(chatID, messageID, fmt.Sprintf("✅ Рассылка завершена: доставлено %d, ошибок %d.", sent, failed))
+}
+
+// editBroadcastReport заменяет текст сообщения с подтверждением рассылки и убирает кнопки.
+func (h *Handler) editBroadcastReport(chatID int64, messageID int, text string) {
+	if _, err := h.sender.Send(context.Background(), tgbotapi.NewEditMessageText(chatID, messageID, text)); err != nil {
+		slog.Warn("Ошибка редактирования сообщения", "error", err)
+	}
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+)
+
+// aliasHelp описывает команды управления исправлениями и синонимами тем.
+const aliasHelp = "*Исправления и синонимы тем:*\n" +
+	"`/alias` - показать таблицу\n" +
+	"`/alias вариант = тема` - заменять вариант написания темой\n" +
+	"`/synonym тема = синоним` - искать тему и синоним вместе\n" +
+	"`/unalias вариант` - удалить исправления и синонимы варианта"
+
+// handleAliasCommand обрабатывает команды администратора /alias, /synonym и /unalias.
+func (h *Handler) handleAliasCommand(ctx context.Context, command, args string, chatID int64) {
+	if h.aliasRepo == nil {
+		h.sendMsg(chatID, "Таблица синонимов недоступна.")
+		return
+	}
+
+	switch {
+	case command == "unalias":
+		if args == "" {
+			h.sendMsg(chatID, aliasHelp)
+			return
+		}
+		if err := h.aliasRepo.RemoveTopicAlias(ctx, args); err != nil {
+			slog.Error("Ошибка удаления синонима", "alias", args, "error", err)
+			h.sendMsg(chatID, fmt.Sprintf("⚠️ Не удалось удалить «%s»: такого варианта нет в таблице.", args))
+			return
+		}
+		h.reloadAliases(ctx)
+		h.sendMsg(chatID, fmt.Sprintf("🗑 Вариант «%s» удален.", args))
+	case args == "":
+		h.sendAliasList(ctx, chatID)
+	default:
+		left, right, ok := strings.Cut(args, "=")
+		left, right = strings.TrimSpace(left), strings.TrimSpace(right)
+		if !ok || left == "" || right == "" {
+			h.sendMsg(chatID, aliasHelp)
+			return
+		}
+
+		// /alias вариант = тема, /synonym тема = синоним
+		alias, topic, kind := left, right, database.TopicAliasCorrection
+		if command == "synonym" {
+			alias, topic, kind = right, left, database.TopicAliasSynonym
+		}
+		if problem := describeQueryError(topic); problem != "" {
+			h.sendMsg(chatID, problem)
+			return
+		}
+
+		if err := h.aliasRepo.AddTopicAlias(ctx, alias, topic, kind); err != nil {
+			slog.Error("Ошибка добавления синонима", "alias", alias, "topic", topic, "error", err)
+			h.sendMsg(chatID, "⚠️ Не удалось сохранить запись. Вариант и тема должны различаться.")
+			return
+		}
+		h.reloadAliases(ctx)
+
+		if kind == database.TopicAliasSynonym {
+			h.sendMsg(chatID, fmt.Sprintf("✅ «%s» и «%s» теперь ищутся вместе.", topic, alias))
+		} else {
+			h.sendMsg(chatID, fmt.Sprintf("✅ «%s» теперь заменяется на «%s».", alias, topic))
+		}
+	}
+}
+
+// sendAliasList отправляет администратору содержимое таблицы синонимов.
+func (h *Handler) sendAliasList(ctx context.Context, chatID int64) {
+	aliases, err := h.aliasRepo.GetTopicAliases(ctx)
+	if err != nil {
+		slog.Error("Ошибка получения таблицы синонимов", "error", err)
+		h.sendMsg(chatID, "Не удалось получить таблицу синонимов.")
+		return
+	}
+	if len(aliases) == 0 {
+		h.sendMsg(chatID, "Таблица синонимов пуста.\n\n"+aliasHelp)
+		return
+	}
+
+	var builder strings.Builder
+	builder.WriteString("📚 *Исправления и синонимы тем:*\n\n")
+	for _, alias := range aliases {
+		if alias.Kind == database.TopicAliasSynonym {
+			builder.WriteString(fmt.Sprintf("• %s ⇄ %s\n", alias.Topic, alias.Alias))
+		} else {
+			builder.WriteString(fmt.Sprintf("• %s → %s\n", alias.Alias, alias.Topic))
+		}
+	}
+	builder.WriteString("\n" + aliasHelp)
+	h.sendMsg(chatID, builder.String())
+}
+
+// reloadAliases перечитывает таблицу синонимов, чтобы изменения сразу применялись к поиску.
+func (h *Handler) reloadAliases(ctx context.Context) {
+	if h.aliases == nil {
+		return
+	}
+	if err := h.aliases.Reload(ctx); err != nil {
+		slog.Error("Ошибка обновления таблицы синонимов", "error", err)
+	}
+}
+
+// suggestTopicCorrection предлагает исправленную тему, если для нее есть исправление в таблице.
+// Новости по исходной теме и так ищутся с учетом исправления, кнопка лишь переименовывает подписку.
+func (h *Handler) suggestTopicCorrection(ctx context.Context, user *database.User, topic string, chatID int64) {
+	if h.aliases == nil {
+		return
+	}
+	suggestion := h.aliases.Suggest(topic)
+	if suggestion == "" || suggestion == topic {
+		return
+	}
+
+	subscriptions, err := h.subRepo.GetUserSubscriptionDetails(ctx, user.ID)
+	if err != nil {
+		slog.Error("Ошибка при получении подписок", "error", err)
+		return
+	}
+	for _, sub := range subscriptions {
+		if sub.Topic != topic {
+			continue
+		}
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✏️ Исправить на «"+suggestion+"»", fmt.Sprintf("alias_fix_%d", sub.ID)),
+		))
+		h.sendMsg(chatID, fmt.Sprintf("💡 Возможно, вы имели в виду «%s»?", suggestion), keyboard)
+		return
+	}
+}
+
+// Обработчик кнопки исправления темы подписки
+func (h *Handler) handleAliasFixCallback(callback *tgbotapi.CallbackQuery) {
+	ctx := context.Background()
+	id, err := strconv.ParseUint(strings.TrimPrefix(callback.Data, "alias_fix_"), 10, 64)
+	if err != nil {
+		h.answerCallback(callback, "Некорректная подписка.")
+		return
+	}
+
+	sub, ok := h.callbackSubscription(callback, uint(id))
+	if !ok {
+		return
+	}
+	suggestion := ""
+	if h.aliases != nil {
+		suggestion = h.aliases.Suggest(sub.Topic)
+	}
+	if suggestion == "" {
+		h.answerCallback(callback, "Исправление больше не актуально.")
+		return
+	}
+
+	// Тема переименовывается на месте, чтобы сохранить ее настройки
+	if err := h.subRepo.UpdateSubscriptionTopic(ctx, sub.UserID, sub.ID, suggestion); err != nil {
+		if errors.Is(err, database.ErrSubscriptionExists) {
+			h.answerCallback(callback, "Вы уже подписаны на «"+suggestion+"».")
+			return
+		}
+		slog.Error("Ошибка при исправлении темы подписки", "subscription_id", sub.ID, "error", err)
+		h.answerCallback(callback, "Не удалось исправить тему.")
+		return
+	}
+
+	text := fmt.Sprintf("✅ Тема исправлена: «%s» → «%s».", sub.Topic, suggestion)
+	h.answerCallback(callback, "Тема исправлена")
+	editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, text)
+	if _, err := h.sender.Send(ctx, editMsg); err != nil {
+		slog.Warn("Ошибка редактирования сообщения", "error", err)
+	}
+}
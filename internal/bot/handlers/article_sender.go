@@ -2,7 +2,7 @@ package handlers
 
 import (
 	"context"
-	"log"
+	"log/slog"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
@@ -17,7 +17,7 @@ func (h *Handler) sendArticleWithFavoriteButton(ctx context.Context, chatID int6
 	// Проверяем, находится ли статья в избранном
 	isFavorite, err := h.scheduler.IsFavoriteArticle(ctx, userID, article.URL)
 	if err != nil {
-		log.Printf("Ошибка проверки избранной статьи: %v", err)
+		slog.Error("Ошибка проверки избранной статьи", "user_id", userID, "error", err)
 		// Продолжаем выполнение, даже если произошла ошибка
 	}
 
@@ -49,8 +49,8 @@ func (h *Handler) sendArticleWithFavoriteButton(ctx context.Context, chatID int6
 	msg.DisableWebPagePreview = false
 	msg.ReplyMarkup = keyboard
 
-	if _, err := h.bot.Send(msg); err != nil {
-		log.Printf("Ошибка отправки новости: %v", err)
+	if _, err := h.sender.Send(ctx, msg); err != nil {
+		slog.Warn("Ошибка отправки новости", "chat_id", chatID, "error", err)
 		return err
 	}
 
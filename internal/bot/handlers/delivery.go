@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/scheduling"
+)
+
+// digestTimeOptions - варианты времени отправки сводки в меню настроек.
+var digestTimeOptions = []string{"07:00", "08:00", "09:00", "12:00", "18:00", "21:00"}
+
+// timeZoneOptions - часовые пояса, предлагаемые в меню настроек.
+var timeZoneOptions = []struct {
+	Name  string
+	Label string
+}{
+	{"Europe/Kaliningrad", "Калининград (UTC+2)"},
+	{"Europe/Moscow", "Москва (UTC+3)"},
+	{"Europe/Samara", "Самара (UTC+4)"},
+	{"Asia/Yekaterinburg", "Екатеринбург (UTC+5)"},
+	{"Asia/Omsk", "Омск (UTC+6)"},
+	{"Asia/Novosibirsk", "Новосибирск (UTC+7)"},
+	{"Asia/Irkutsk", "Иркутск (UTC+8)"},
+	{"Asia/Yakutsk", "Якутск (UTC+9)"},
+	{"Asia/Vladivostok", "Владивосток (UTC+10)"},
+	{"Asia/Magadan", "Магадан (UTC+11)"},
+	{"Asia/Kamchatka", "Камчатка (UTC+12)"},
+	{"UTC", "UTC"},
+}
+
+// quietHoursOptions - варианты окна тихих часов в меню настроек.
+var quietHoursOptions = []struct {
+	Start string
+	End   string
+}{
+	{"22:00", "08:00"},
+	{"23:00", "07:00"},
+	{"00:00", "09:00"},
+	{"21:00", "09:00"},
+}
+
+// handleDeliverySettings показывает меню выбора режима доставки новостей.
+func (h *Handler) handleDeliverySettings(callback *tgbotapi.CallbackQuery) {
+	user, err := h.getOrCreateUser(callback.From)
+	if err != nil {
+		slog.Error("Ошибка поиска пользователя", "telegram_id", callback.From.ID, "error", err)
+		h.answerCallback(callback, "Произошла ошибка.")
+		return
+	}
+
+	text := fmt.Sprintf("Текущий режим: %s\n\nВыберите, как получать новости:", describeDeliveryMode(user))
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("⚡ Каждую новость сразу", "delivery_instant"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("📅 Сводка раз в день", "delivery_daily"),
+			tgbotapi.NewInlineKeyboardButtonData("🗓 Сводка раз в неделю", "delivery_weekly"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🕘 Время сводки", "settings_digest_time"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Назад", "settings_back"),
+		),
+	)
+
+	h.editSettingsMessage(callback, text, keyboard)
+}
+
+// handleDigestTimeSettings показывает меню выбора времени отправки сводки.
+func (h *Handler) handleDigestTimeSettings(callback *tgbotapi.CallbackQuery) {
+	text := "Выберите, в какое время присылать сводку:"
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var currentRow []tgbotapi.InlineKeyboardButton
+	for i, option := range digestTimeOptions {
+		currentRow = append(currentRow, tgbotapi.NewInlineKeyboardButtonData(option, "digest_time_"+option))
+		if len(currentRow) == 3 || i == len(digestTimeOptions)-1 {
+			rows = append(rows, currentRow)
+			currentRow = nil
+		}
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Назад", "settings_delivery"),
+	))
+
+	h.editSettingsMessage(callback, text, tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+// Обработчик выбора режима доставки
+func (h *Handler) handleDeliveryModeCallback(callback *tgbotapi.CallbackQuery) {
+	ctx := context.Background()
+	user, err := h.getOrCreateUser(callback.From)
+	if err != nil {
+		slog.Error("Ошибка поиска пользователя", "telegram_id", callback.From.ID, "error", err)
+		h.answerCallback(callback, "Произошла ошибка.")
+		return
+	}
+
+	mode, period := database.DeliveryModeInstant, user.DigestPeriod
+	switch strings.TrimPrefix(callback.Data, "delivery_") {
+	case "instant":
+	case "daily":
+		mode, period = database.DeliveryModeDigest, database.DigestPeriodDaily
+	case "weekly":
+		mode, period = database.DeliveryModeDigest, database.DigestPeriodWeekly
+	default:
+		h.answerCallback(callback, "Неизвестный режим доставки.")
+		return
+	}
+
+	if err := h.userRepo.UpdateUserDeliveryMode(ctx, user.ID, mode, period); err != nil {
+		slog.Error("Ошибка обновления настроек пользователя", "user_id", user.ID, "error", err)
+		h.answerCallback(callback, "Не удалось обновить настройки.")
+		return
+	}
+
+	user.DeliveryMode, user.DigestPeriod = mode, period
+	h.answerCallback(callback, "Режим доставки: "+describeDeliveryMode(user))
+
+	// Возвращаемся в меню настроек
+	h.handleSettings(callback.Message.Chat.ID)
+}
+
+// Обработчик выбора времени сводки
+func (h *Handler) handleDigestTimeCallback(callback *tgbotapi.CallbackQuery) {
+	ctx := context.Background()
+	user, err := h.getOrCreateUser(callback.From)
+	if err != nil {
+		slog.Error("Ошибка поиска пользователя", "telegram_id", callback.From.ID, "error", err)
+		h.answerCallback(callback, "Произошла ошибка.")
+		return
+	}
+
+	digestTime := strings.TrimPrefix(callback.Data, "digest_time_")
+	if !validClock(digestTime) {
+		h.answerCallback(callback, "Некорректное время.")
+		return
+	}
+
+	if err := h.userRepo.UpdateUserDigestTime(ctx, user.ID, digestTime); err != nil {
+		slog.Error("Ошибка обновления настроек пользователя", "user_id", user.ID, "error", err)
+		h.answerCallback(callback, "Не удалось обновить настройки.")
+		return
+	}
+
+	h.answerCallback(callback, fmt.Sprintf("Сводка будет приходить в %s.", digestTime))
+
+	// Возвращаемся в меню настроек
+	h.handleSettings(callback.Message.Chat.ID)
+}
+
+// editSettingsMessage заменяет текст и клавиатуру сообщения с меню настроек.
+func (h *Handler) editSettingsMessage(callback *tgbotapi.CallbackQuery, text string, keyboard tgbotapi.InlineKeyboardMarkup) {
+	editMsg := tgbotapi.NewEditMessageTextAndMarkup(
+		callback.Message.Chat.ID,
+		callback.Message.MessageID,
+		text,
+		keyboard,
+	)
+
+	if _, err := h.sender.Send(context.Background(), editMsg); err != nil {
+		slog.Warn("Ошибка редактирования сообщения", "error", err)
+	}
+	h.answerCallback(callback, "")
+}
+
+// describeDeliveryMode возвращает описание режима доставки пользователя.
+func describeDeliveryMode(user *database.User) string {
+	if user.DeliveryMode != database.DeliveryModeDigest {
+		return "каждая новость сразу"
+	}
+
+	digestTime := user.DigestTime
+	if digestTime == "" {
+		digestTime = database.DefaultDigestTime
+	}
+	if user.DigestPeriod == database.DigestPeriodWeekly {
+		return fmt.Sprintf("сводка по понедельникам в %s", digestTime)
+	}
+	return fmt.Sprintf("ежедневная сводка в %s", digestTime)
+}
+
+// handleTimeZoneSettings показывает меню выбора часового пояса.
+func (h *Handler) handleTimeZoneSettings(callback *tgbotapi.CallbackQuery) {
+	user, err := h.getOrCreateUser(callback.From)
+	if err != nil {
+		slog.Error("Ошибка поиска пользователя", "telegram_id", callback.From.ID, "error", err)
+		h.answerCallback(callback, "Произошла ошибка.")
+		return
+	}
+
+	current := user.TimeZone
+	if current == "" {
+		current = "часовой пояс сервера"
+	}
+	text := fmt.Sprintf("Текущий часовой пояс: %s\n\nВыберите ваш часовой пояс:", current)
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var currentRow []tgbotapi.InlineKeyboardButton
+	for i, option := range timeZoneOptions {
+		currentRow = append(currentRow, tgbotapi.NewInlineKeyboardButtonData(option.Label, "tz_"+option.Name))
+		if len(currentRow) == 2 || i == len(timeZoneOptions)-1 {
+			rows = append(rows, currentRow)
+			currentRow = nil
+		}
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Назад", "settings_back"),
+	))
+
+	h.editSettingsMessage(callback, text, tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+// handleQuietHoursSettings показывает меню выбора тихих часов.
+func (h *Handler) handleQuietHoursSettings(callback *tgbotapi.CallbackQuery) {
+	user, err := h.getOrCreateUser(callback.From)
+	if err != nil {
+		slog.Error("Ошибка поиска пользователя", "telegram_id", callback.From.ID, "error", err)
+		h.answerCallback(callback, "Произошла ошибка.")
+		return
+	}
+
+	text := fmt.Sprintf("Тихие часы: %s\n\nВ это время новости не присылаются, а после его окончания приходят одной пачкой.", describeQuietHours(user))
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var currentRow []tgbotapi.InlineKeyboardButton
+	for i, option := range quietHoursOptions {
+		label := fmt.Sprintf("%s–%s", option.Start, option.End)
+		currentRow = append(currentRow, tgbotapi.NewInlineKeyboardButtonData(label, fmt.Sprintf("quiet_%s-%s", option.Start, option.End)))
+		if len(currentRow) == 2 || i == len(quietHoursOptions)-1 {
+			rows = append(rows, currentRow)
+			currentRow = nil
+		}
+	}
+	rows = append(rows,
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("🔔 Выключить", "quiet_off")),
+		tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("Назад", "settings_back")),
+	)
+
+	h.editSettingsMessage(callback, text, tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+// Обработчик выбора часового пояса
+func (h *Handler) handleTimeZoneCallback(callback *tgbotapi.CallbackQuery) {
+	ctx := context.Background()
+	user, err := h.getOrCreateUser(callback.From)
+	if err != nil {
+		slog.Error("Ошибка поиска пользователя", "telegram_id", callback.From.ID, "error", err)
+		h.answerCallback(callback, "Произошла ошибка.")
+		return
+	}
+
+	timeZone := strings.TrimPrefix(callback.Data, "tz_")
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		h.answerCallback(callback, "Неизвестный часовой пояс.")
+		return
+	}
+
+	if err := h.userRepo.UpdateUserTimeZone(ctx, user.ID, timeZone); err != nil {
+		slog.Error("Ошибка обновления настроек пользователя", "user_id", user.ID, "error", err)
+		h.answerCallback(callback, "Не удалось обновить настройки.")
+		return
+	}
+
+	h.answerCallback(callback, fmt.Sprintf("Часовой пояс: %s, сейчас %s.", timeZone, time.Now().In(loc).Format("15:04")))
+
+	// Возвращаемся в меню настроек
+	h.handleSettings(callback.Message.Chat.ID)
+}
+
+// Обработчик выбора тихих часов
+func (h *Handler) handleQuietHoursCallback(callback *tgbotapi.CallbackQuery) {
+	ctx := context.Background()
+	user, err := h.getOrCreateUser(callback.From)
+	if err != nil {
+		slog.Error("Ошибка поиска пользователя", "telegram_id", callback.From.ID, "error", err)
+		h.answerCallback(callback, "Произошла ошибка.")
+		return
+	}
+
+	var start, end string
+	if value := strings.TrimPrefix(callback.Data, "quiet_"); value != "off" {
+		var ok bool
+		start, end, ok = strings.Cut(value, "-")
+		if !ok || !validClock(start) || !validClock(end) {
+			h.answerCallback(callback, "Некорректное время.")
+			return
+		}
+	}
+
+	if err := h.userRepo.UpdateUserQuietHours(ctx, user.ID, start, end); err != nil {
+		slog.Error("Ошибка обновления настроек пользователя", "user_id", user.ID, "error", err)
+		h.answerCallback(callback, "Не удалось обновить настройки.")
+		return
+	}
+
+	user.QuietHoursStart, user.QuietHoursEnd = start, end
+	h.answerCallback(callback, "Тихие часы: "+describeQuietHours(user))
+
+	// Возвращаемся в меню настроек
+	h.handleSettings(callback.Message.Chat.ID)
+}
+
+// describeQuietHours возвращает описание тихих часов пользователя.
+func describeQuietHours(user *database.User) string {
+	if user.QuietHoursStart == "" || user.QuietHoursEnd == "" {
+		return "выключены"
+	}
+	return fmt.Sprintf("с %s до %s", user.QuietHoursStart, user.QuietHoursEnd)
+}
+
+// validClock проверяет время в формате ЧЧ:ММ.
+func validClock(value string) bool {
+	_, _, err := scheduling.ParseClock(value)
+	return err == nil
+}
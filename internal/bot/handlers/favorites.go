@@ -3,7 +3,7 @@ package handlers
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
 	"time"
 
@@ -20,7 +20,7 @@ func (h *Handler) handleFavorites(ctx context.Context, user *database.User, chat
 	// Получаем избранные новости пользователя
 	favorites, err := h.scheduler.GetUserFavoriteArticles(ctx, user.ID)
 	if err != nil {
-		log.Printf("Ошибка получения избранных новостей: %v", err)
+		slog.Error("Ошибка получения избранных новостей", "error", err)
 		h.sendMsg(chatID, "❌ Произошла ошибка при получении избранных новостей. Пожалуйста, попробуйте позже.")
 		return
 	}
@@ -75,8 +75,8 @@ func (h *Handler) handleFavorites(ctx context.Context, user *database.User, chat
 		msg.DisableWebPagePreview = false
 		msg.ReplyMarkup = keyboard
 
-		if _, err := h.bot.Send(msg); err != nil {
-			log.Printf("Ошибка отправки избранной новости: %v", err)
+		if _, err := h.sender.Send(ctx, msg); err != nil {
+			slog.Warn("Ошибка отправки избранной новости", "error", err)
 		}
 	}
 }
@@ -100,7 +100,7 @@ func (h *Handler) handleAddToFavorites(ctx context.Context, callback *tgbotapi.C
 		}
 
 		if articleURL == "" {
-			log.Printf("Не удалось найти полный URL для короткого идентификатора: %s", shortID)
+			slog.Warn("Не удалось найти полный URL для короткого идентификатора", "short_id", shortID)
 			h.answerCallback(callback, "Произошла ошибка при добавлении в избранное.")
 			return
 		}
@@ -112,7 +112,7 @@ func (h *Handler) handleAddToFavorites(ctx context.Context, callback *tgbotapi.C
 	// Получаем пользователя
 	user, err := h.getOrCreateUser(callback.From)
 	if err != nil {
-		log.Printf("Ошибка поиска пользователя: %v", err)
+		slog.Error("Ошибка поиска пользователя", "error", err)
 		h.answerCallback(callback, "Произошла ошибка.")
 		return
 	}
@@ -120,7 +120,7 @@ func (h *Handler) handleAddToFavorites(ctx context.Context, callback *tgbotapi.C
 	// Проверяем, добавлена ли уже статья в избранное
 	isFavorite, err := h.scheduler.IsFavoriteArticle(ctx, user.ID, articleURL)
 	if err != nil {
-		log.Printf("Ошибка проверки избранной статьи: %v", err)
+		slog.Error("Ошибка проверки избранной статьи", "error", err)
 		h.answerCallback(callback, "Произошла ошибка.")
 		return
 	}
@@ -158,7 +158,7 @@ func (h *Handler) handleAddToFavorites(ctx context.Context, callback *tgbotapi.C
 	}
 
 	if err := h.scheduler.AddFavoriteArticle(ctx, user.ID, article); err != nil {
-		log.Printf("Ошибка добавления статьи в избранное: %v", err)
+		slog.Error("Ошибка добавления статьи в избранное", "error", err)
 		h.answerCallback(callback, "Произошла ошибка при добавлении в избранное.")
 		return
 	}
@@ -179,8 +179,8 @@ func (h *Handler) handleAddToFavorites(ctx context.Context, callback *tgbotapi.C
 		keyboard,
 	)
 
-	if _, err := h.bot.Send(editMsg); err != nil {
-		log.Printf("Ошибка обновления клавиатуры: %v", err)
+	if _, err := h.sender.Send(ctx, editMsg); err != nil {
+		slog.Warn("Ошибка обновления клавиатуры", "error", err)
 	}
 
 	h.answerCallback(callback, "✅ Статья добавлена в избранное!")
@@ -200,7 +200,7 @@ func (h *Handler) handleRemoveFromFavorites(ctx context.Context, callback *tgbot
 		articleID = callback.Data[len("remove_favorite_"):]
 	} else {
 		// Неизвестный формат
-		log.Printf("Неизвестный формат данных callback: %s", callback.Data)
+		slog.Warn("Неизвестный формат данных callback", "data", callback.Data)
 		h.answerCallback(callback, "Произошла ошибка.")
 		return
 	}
@@ -208,7 +208,7 @@ func (h *Handler) handleRemoveFromFavorites(ctx context.Context, callback *tgbot
 	// Получаем пользователя
 	user, err := h.getOrCreateUser(callback.From)
 	if err != nil {
-		log.Printf("Ошибка поиска пользователя: %v", err)
+		slog.Error("Ошибка поиска пользователя", "error", err)
 		h.answerCallback(callback, "Произошла ошибка.")
 		return
 	}
@@ -218,7 +218,7 @@ func (h *Handler) handleRemoveFromFavorites(ctx context.Context, callback *tgbot
 		// Получаем список всех избранных статей пользователя
 		favorites, err := h.scheduler.GetUserFavoriteArticles(ctx, user.ID)
 		if err != nil {
-			log.Printf("Ошибка получения избранных статей: %v", err)
+			slog.Error("Ошибка получения избранных статей", "error", err)
 			h.answerCallback(callback, "Произошла ошибка при удалении из избранного.")
 			return
 		}
@@ -241,7 +241,7 @@ func (h *Handler) handleRemoveFromFavorites(ctx context.Context, callback *tgbot
 		}
 
 		if !found {
-			log.Printf("Не удалось найти статью по короткому идентификатору: %s", articleID)
+			slog.Warn("Не удалось найти статью по короткому идентификатору", "short_id", articleID)
 			h.answerCallback(callback, "Произошла ошибка при удалении из избранного.")
 			return
 		}
@@ -249,7 +249,7 @@ func (h *Handler) handleRemoveFromFavorites(ctx context.Context, callback *tgbot
 
 	// Удаляем статью из избранного
 	if err := h.scheduler.RemoveFavoriteArticle(ctx, user.ID, articleID); err != nil {
-		log.Printf("Ошибка удаления статьи из избранного: %v", err)
+		slog.Error("Ошибка удаления статьи из избранного", "error", err)
 		h.answerCallback(callback, "Произошла ошибка при удалении из избранного.")
 		return
 	}
@@ -259,8 +259,8 @@ func (h *Handler) handleRemoveFromFavorites(ctx context.Context, callback *tgbot
 		data := callback.Message.ReplyMarkup.InlineKeyboard[0][0].CallbackData
 		if data != nil && len(*data) > len("remove_favorite_") && (*data)[:len("remove_favorite_")] == "remove_favorite_" {
 			deleteMsg := tgbotapi.NewDeleteMessage(callback.Message.Chat.ID, callback.Message.MessageID)
-			if _, err := h.bot.Send(deleteMsg); err != nil {
-				log.Printf("Ошибка удаления сообщения: %v", err)
+			if _, err := h.sender.Request(ctx, deleteMsg); err != nil {
+				slog.Warn("Ошибка удаления сообщения", "error", err)
 			}
 			h.answerCallback(callback, "✅ Статья удалена из избранного!")
 			return
@@ -280,8 +280,8 @@ func (h *Handler) handleRemoveFromFavorites(ctx context.Context, callback *tgbot
 		keyboard,
 	)
 
-	if _, err := h.bot.Send(editMsg); err != nil {
-		log.Printf("Ошибка обновления клавиатуры: %v", err)
+	if _, err := h.sender.Send(ctx, editMsg); err != nil {
+		slog.Warn("Ошибка обновления клавиатуры", "error", err)
 	}
 
 	h.answerCallback(callback, "✅ Статья удалена из избранного!")
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
+)
+
+// describeFetchError возвращает текст для пользователя по категории ошибки получения
+// новостей. Для ошибок без категории возвращается fallback.
+func describeFetchError(user *database.User, err error, fallback string) string {
+	var quotaErr *fetcher.QuotaExhaustedError
+	var rateErr *fetcher.RateLimitError
+	switch {
+	case errors.As(err, &quotaErr):
+		return fmt.Sprintf("❗ Дневной лимит запросов к источникам новостей исчерпан. Он обновится в %s, попробуйте после этого времени.",
+			quotaErr.ResetAt.In(userLocation(user)).Format("15:04"))
+	case errors.As(err, &rateErr):
+		return fmt.Sprintf("❗ Источник новостей временно ограничил частоту запросов. Попробуйте снова после %s.",
+			rateErr.ResetAt.In(userLocation(user)).Format("15:04"))
+	case errors.Is(err, fetcher.ErrBadQuery):
+		return "⚠️ Источник новостей не принял запрос. Попробуйте упростить его: меньше слов и операторов."
+	case errors.Is(err, fetcher.ErrUnauthorized):
+		return "⚠️ Источник новостей отклонил ключ доступа бота. Сообщите об этом администратору бота."
+	case errors.Is(err, fetcher.ErrUpstreamUnavailable):
+		return "⚠️ Источники новостей сейчас недоступны. Попробуйте через несколько минут."
+	}
+	return fallback
+}
+
+// userLocation возвращает часовой пояс пользователя или местный пояс сервера, если он не задан.
+func userLocation(user *database.User) *time.Location {
+	if user != nil && user.TimeZone != "" {
+		if loc, err := time.LoadLocation(user.TimeZone); err == nil {
+			return loc
+		}
+	}
+	return time.Local
+}
@@ -3,14 +3,17 @@ package handlers
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
 	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/sender"
 	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/utils"
 )
 
@@ -21,6 +24,10 @@ const (
 	StateViewingFavorites    = "viewing_favorites"
 )
 
+// searchTimeout limits how long a user waits for search results before the
+// requests to the news providers are cancelled.
+const searchTimeout = time.Minute
+
 // Scheduler is an interface that the scheduler must implement.
 // This avoids a circular dependency.
 type Scheduler interface {
@@ -39,24 +46,62 @@ type Scheduler interface {
 // Handler processes incoming updates from Telegram
 // and manages the bot's state.
 type Handler struct {
-	bot       *tgbotapi.BotAPI
+	sender    *sender.Sender
 	userRepo  database.UserRepository
 	subRepo   database.SubscriptionRepository
+	aliasRepo database.TopicAliasRepository
+	statsRepo database.StatsRepository
+	aliases   *fetcher.Aliases
 	scheduler Scheduler
+	admins    map[int64]bool
+
+	broadcastMu       sync.Mutex
+	pendingBroadcasts map[int64]pendingBroadcast // Broadcasts awaiting confirmation, by admin Telegram ID
+	broadcasting      atomic.Bool
+
+	// ctx is cancelled by Stop and interrupts background work such as broadcasts;
+	// wg tracks that work so Stop can wait for it.
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
 }
 
 // NewHandler creates a new handler instance.
-func NewHandler(bot *tgbotapi.BotAPI, userRepo database.UserRepository, subRepo database.SubscriptionRepository, scheduler Scheduler) *Handler {
+// adminIDs are Telegram IDs allowed to run admin commands.
+func NewHandler(sender *sender.Sender, userRepo database.UserRepository, subRepo database.SubscriptionRepository, aliasRepo database.TopicAliasRepository, statsRepo database.StatsRepository, aliases *fetcher.Aliases, scheduler Scheduler, adminIDs []int64) *Handler {
+	admins := make(map[int64]bool, len(adminIDs))
+	for _, id := range adminIDs {
+		admins[id] = true
+	}
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Handler{
-		bot:       bot,
+		sender:    sender,
 		userRepo:  userRepo,
 		subRepo:   subRepo,
+		aliasRepo: aliasRepo,
+		statsRepo: statsRepo,
+		aliases:   aliases,
 		scheduler: scheduler,
+		admins:    admins,
+
+		pendingBroadcasts: make(map[int64]pendingBroadcast),
+		ctx:               ctx,
+		cancel:            cancel,
 	}
 }
 
+// Stop cancels background work started by the handler and waits for it to finish.
+// After it returns the handler no longer uses the database.
+func (h *Handler) Stop() {
+	h.broadcastMu.Lock()
+	h.cancel()
+	h.broadcastMu.Unlock()
+	h.wg.Wait()
+}
+
 // HandleUpdate is the main handler for incoming updates.
 func (h *Handler) HandleUpdate(update tgbotapi.Update) {
+	updatesTotal.Inc(updateType(update))
 	switch {
 	case update.Message != nil:
 		h.handleMessage(update.Message)
@@ -69,7 +114,7 @@ func (h *Handler) HandleUpdate(update tgbotapi.Update) {
 func (h *Handler) handleMessage(msg *tgbotapi.Message) {
 	user, err := h.getOrCreateUser(msg.From)
 	if err != nil {
-		log.Printf("Error getting or creating user: %v", err)
+		slog.Error("Error getting or creating user", "error", err)
 		return
 	}
 
@@ -94,6 +139,13 @@ func (h *Handler) handleCommand(msg *tgbotapi.Message, user *database.User) {
 	command := msg.Command()
 	topic := strings.TrimSpace(msg.CommandArguments())
 
+	// Unknown commands share one label so that arbitrary input does not create new series
+	label := command
+	start := time.Now()
+	defer func() {
+		commandDuration.Observe(time.Since(start).Seconds(), label)
+	}()
+
 	switch command {
 	case "start":
 		h.handleStart(msg.Chat.ID)
@@ -116,7 +168,29 @@ func (h *Handler) handleCommand(msg *tgbotapi.Message, user *database.User) {
 		h.handleSubscriptionsList(ctx, user, msg.Chat.ID)
 	case "settings":
 		h.handleSettings(msg.Chat.ID)
+	case "schedule":
+		h.handleSchedule(ctx, user, topic, msg.Chat.ID)
+	case "alias", "synonym", "unalias":
+		if !h.isAdmin(user.TelegramID) {
+			h.sendMsg(msg.Chat.ID, "Неизвестная команда. Используйте /help для списка команд.")
+			return
+		}
+		h.handleAliasCommand(ctx, command, topic, msg.Chat.ID)
+	case "users", "stats", "broadcast":
+		if !h.isAdmin(user.TelegramID) {
+			h.sendMsg(msg.Chat.ID, "Неизвестная команда. Используйте /help для списка команд.")
+			return
+		}
+		switch command {
+		case "users":
+			h.handleUsersCommand(ctx, msg.Chat.ID)
+		case "stats":
+			h.handleStatsCommand(ctx, msg.Chat.ID)
+		default:
+			h.handleBroadcastCommand(ctx, user, topic, msg.Chat.ID)
+		}
 	default:
+		label = "unknown"
 		h.sendMsg(msg.Chat.ID, "Неизвестная команда. Используйте /help для списка команд.")
 	}
 }
@@ -180,7 +254,8 @@ func (h *Handler) handleHelp(chatID int64) {
 		"*/subscribe <тема>* - ➕ Подписаться на новости\n" +
 		"*/unsubscribe <тема>* - ➖ Отписаться от новостей\n" +
 		"*/subscriptions* - 📋 Показать все ваши активные подписки\n" +
-		"*/settings* - ⚙️ Настроить частоту и количество новостей\n" +
+		"*/schedule <расписание>* - 🗓 Задать расписание уведомлений, например `0 9,18 * * 1-5`\n" +
+		"*/settings* - ⚙️ Настроить частоту, количество новостей и режим доставки\n" +
 		"*/help* - ℹ️ Показать это справочное сообщение\n\n" +
 		"*Кнопки в главном меню:*\n" +
 		"📰 Получить новости сейчас - мгновенное получение новостей по всем подпискам\n" +
@@ -189,10 +264,11 @@ func (h *Handler) handleHelp(chatID int64) {
 		"🔍 Поиск новостей - поиск новостей по произвольному запросу\n" +
 		"⭐ Избранное - просмотр сохраненных вами новостей\n" +
 		"🔄 Сбросить историю - очистка истории просмотренных новостей\n" +
-		"⚙️ Настройки - изменение частоты и количества новостей\n\n" +
+		"⚙️ Настройки - частота и количество новостей, режим доставки, часовой пояс и тихие часы\n\n" +
 		"*Советы:*\n" +
 		"- Для получения новостей по конкретной теме, используйте кнопку 'Новости по темам'\n" +
-		"- Для поиска новостей по произвольному запросу, нажмите 'Поиск новостей' и введите интересующий вас запрос"
+		"- Для поиска новостей по произвольному запросу, нажмите 'Поиск новостей' и введите интересующий вас запрос\n" +
+		"- В темах и запросах можно использовать AND, OR, NOT, исключения `-слово` и фразы в кавычках, например `\"искусственный интеллект\" -реклама`"
 	h.sendMsg(chatID, helpText)
 }
 
@@ -215,12 +291,17 @@ func (h *Handler) handleSubscribe(user *database.User, topic string, chatID int6
 		return
 	}
 	topic = strings.ToLower(topic)
+	if problem := describeQueryError(topic); problem != "" {
+		h.sendMsg(chatID, problem)
+		return
+	}
 	if err := h.subRepo.AddSubscription(context.Background(), user.ID, topic); err != nil {
 		h.sendMsg(chatID, fmt.Sprintf("⚠️ Ошибка: не удалось добавить подписку на '%s'. Возможно, вы уже подписаны.", topic))
-		log.Printf("Ошибка при добавлении подписки: %v", err)
+		slog.Error("Ошибка при добавлении подписки", "error", err)
 		return
 	}
 	h.sendMsg(chatID, fmt.Sprintf("👍 Отлично! Вы подписались на тему: *%s*", topic))
+	h.suggestTopicCorrection(context.Background(), user, topic, chatID)
 }
 
 func (h *Handler) handleUnsubscribeCommand(ctx context.Context, user *database.User, topic string, chatID int64) {
@@ -235,7 +316,7 @@ func (h *Handler) handleUnsubscribeCommand(ctx context.Context, user *database.U
 func (h *Handler) handleUnsubscribeButton(ctx context.Context, user *database.User, chatID int64) {
 	topics, err := h.subRepo.GetUserSubscriptions(ctx, user.ID)
 	if err != nil {
-		log.Printf("Failed to get user subscriptions: %v", err)
+		slog.Error("Failed to get user subscriptions", "error", err)
 		h.sendMsg(chatID, "Не удалось загрузить ваши подписки. Попробуйте позже.")
 		return
 	}
@@ -246,25 +327,6 @@ func (h *Handler) handleUnsubscribeButton(ctx context.Context, user *database.Us
 	h.sendMsg(chatID, "Выберите тему, от которой хотите отписаться:", h.createUnsubscribeKeyboard(topics))
 }
 
-func (h *Handler) handleSubscriptionsList(ctx context.Context, user *database.User, chatID int64) {
-	topics, err := h.subRepo.GetUserSubscriptions(ctx, user.ID)
-	if err != nil {
-		log.Printf("Ошибка при получении подписок: %v", err)
-		h.sendMsg(chatID, "Ошибка при получении списка подписок.")
-		return
-	}
-	if len(topics) == 0 {
-		h.sendMsg(chatID, "У вас пока нет подписок. 🤷‍♂️\n\nНажмите '✍️ Подписаться', чтобы добавить свою первую тему!")
-	} else {
-		var builder strings.Builder
-		builder.WriteString("📄 *Ваши текущие подписки:*\n\n")
-		for _, topic := range topics {
-			builder.WriteString(fmt.Sprintf("• %s\n", topic))
-		}
-		h.sendMsg(chatID, builder.String())
-	}
-}
-
 func (h *Handler) handleSettings(chatID int64) {
 	text := "Выберите настройки бота:"
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
@@ -274,6 +336,13 @@ func (h *Handler) handleSettings(chatID int64) {
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("Количество новостей", "settings_news_limit"),
 		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Режим доставки", "settings_delivery"),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🌍 Часовой пояс", "settings_timezone"),
+			tgbotapi.NewInlineKeyboardButtonData("🌙 Тихие часы", "settings_quiet"),
+		),
 	)
 	h.sendMsg(chatID, text, keyboard)
 }
@@ -287,6 +356,14 @@ func (h *Handler) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
 		h.handleIntervalSettings(callback)
 	case callback.Data == "settings_news_limit":
 		h.handleNewsLimitSettings(callback)
+	case callback.Data == "settings_delivery":
+		h.handleDeliverySettings(callback)
+	case callback.Data == "settings_digest_time":
+		h.handleDigestTimeSettings(callback)
+	case callback.Data == "settings_timezone":
+		h.handleTimeZoneSettings(callback)
+	case callback.Data == "settings_quiet":
+		h.handleQuietHoursSettings(callback)
 	case callback.Data == "settings_back":
 		h.handleSettings(callback.Message.Chat.ID)
 		h.answerCallback(callback, "")
@@ -294,6 +371,28 @@ func (h *Handler) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
 		h.handleIntervalCallback(callback)
 	case strings.HasPrefix(callback.Data, "news_limit_"):
 		h.handleNewsLimitCallback(callback)
+	case strings.HasPrefix(callback.Data, "delivery_"):
+		h.handleDeliveryModeCallback(callback)
+	case strings.HasPrefix(callback.Data, "digest_time_"):
+		h.handleDigestTimeCallback(callback)
+	case strings.HasPrefix(callback.Data, "tz_"):
+		h.handleTimeZoneCallback(callback)
+	case strings.HasPrefix(callback.Data, "quiet_"):
+		h.handleQuietHoursCallback(callback)
+	case callback.Data == "subs_back":
+		h.handleSubscriptionsBack(callback)
+	case strings.HasPrefix(callback.Data, "sub_"):
+		h.handleSubscriptionMenu(callback)
+	case strings.HasPrefix(callback.Data, "subopt_"):
+		h.handleSubscriptionOptions(callback)
+	case strings.HasPrefix(callback.Data, "subset_"):
+		h.handleSubscriptionSetting(callback)
+	case strings.HasPrefix(callback.Data, "submute_"):
+		h.handleSubscriptionMute(callback)
+	case strings.HasPrefix(callback.Data, "broadcast_"):
+		h.handleBroadcastCallback(callback)
+	case strings.HasPrefix(callback.Data, "alias_fix_"):
+		h.handleAliasFixCallback(callback)
 	case strings.HasPrefix(callback.Data, "unsubscribe_"):
 		h.handleUnsubscribeCallback(callback)
 	case strings.HasPrefix(callback.Data, "topic_news_"):
@@ -307,7 +406,7 @@ func (h *Handler) handleCallbackQuery(callback *tgbotapi.CallbackQuery) {
 
 // Обработчик настроек интервала обновления
 func (h *Handler) handleIntervalSettings(callback *tgbotapi.CallbackQuery) {
-	text := "Выберите, как часто вы хотите получать новости:"
+	text := "Выберите, как часто вы хотите получать новости:\n\nДля точного расписания, например «по будням в 09:00 и 18:00», используйте команду /schedule."
 	keyboard := tgbotapi.NewInlineKeyboardMarkup(
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("Раз в час", "interval_60"),
@@ -329,8 +428,8 @@ func (h *Handler) handleIntervalSettings(callback *tgbotapi.CallbackQuery) {
 		keyboard,
 	)
 
-	if _, err := h.bot.Send(editMsg); err != nil {
-		log.Printf("Ошибка редактирования сообщения: %v", err)
+	if _, err := h.sender.Send(context.Background(), editMsg); err != nil {
+		slog.Warn("Ошибка редактирования сообщения", "error", err)
 	}
 	h.answerCallback(callback, "")
 }
@@ -359,8 +458,8 @@ func (h *Handler) handleNewsLimitSettings(callback *tgbotapi.CallbackQuery) {
 		keyboard,
 	)
 
-	if _, err := h.bot.Send(editMsg); err != nil {
-		log.Printf("Ошибка редактирования сообщения: %v", err)
+	if _, err := h.sender.Send(context.Background(), editMsg); err != nil {
+		slog.Warn("Ошибка редактирования сообщения", "error", err)
 	}
 	h.answerCallback(callback, "")
 }
@@ -370,7 +469,7 @@ func (h *Handler) handleIntervalCallback(callback *tgbotapi.CallbackQuery) {
 	ctx := context.Background()
 	user, err := h.getOrCreateUser(callback.From)
 	if err != nil {
-		log.Printf("Ошибка поиска пользователя %d: %v", callback.From.ID, err)
+		slog.Error("Ошибка поиска пользователя", "telegram_id", callback.From.ID, "error", err)
 		h.answerCallback(callback, "Произошла ошибка.")
 		return
 	}
@@ -378,11 +477,18 @@ func (h *Handler) handleIntervalCallback(callback *tgbotapi.CallbackQuery) {
 	intervalStr := strings.TrimPrefix(callback.Data, "interval_")
 	interval, _ := strconv.Atoi(intervalStr)
 	if err := h.userRepo.UpdateUserNotificationInterval(ctx, user.ID, uint(interval)); err != nil {
-		log.Printf("Ошибка обновления настроек для пользователя %d: %v", user.ID, err)
+		slog.Error("Ошибка обновления настроек пользователя", "user_id", user.ID, "error", err)
 		h.answerCallback(callback, "Не удалось обновить настройки.")
 		return
 	}
 
+	// Выбор интервала заменяет ранее заданное расписание
+	if user.Schedule != "" {
+		if err := h.userRepo.UpdateUserSchedule(ctx, user.ID, ""); err != nil {
+			slog.Error("Ошибка сброса расписания", "user_id", user.ID, "error", err)
+		}
+	}
+
 	responseText := fmt.Sprintf("Интервал обновления установлен на %d минут.", interval)
 	h.answerCallback(callback, responseText)
 
@@ -395,7 +501,7 @@ func (h *Handler) handleNewsLimitCallback(callback *tgbotapi.CallbackQuery) {
 	ctx := context.Background()
 	user, err := h.getOrCreateUser(callback.From)
 	if err != nil {
-		log.Printf("Ошибка поиска пользователя %d: %v", callback.From.ID, err)
+		slog.Error("Ошибка поиска пользователя", "telegram_id", callback.From.ID, "error", err)
 		h.answerCallback(callback, "Произошла ошибка.")
 		return
 	}
@@ -403,7 +509,7 @@ func (h *Handler) handleNewsLimitCallback(callback *tgbotapi.CallbackQuery) {
 	limitStr := strings.TrimPrefix(callback.Data, "news_limit_")
 	limit, _ := strconv.Atoi(limitStr)
 	if err := h.userRepo.UpdateUserNewsLimit(ctx, user.ID, uint(limit)); err != nil {
-		log.Printf("Ошибка обновления настроек для пользователя %d: %v", user.ID, err)
+		slog.Error("Ошибка обновления настроек пользователя", "user_id", user.ID, "error", err)
 		h.answerCallback(callback, "Не удалось обновить настройки.")
 		return
 	}
@@ -420,7 +526,7 @@ func (h *Handler) handleNewsByTopics(ctx context.Context, user *database.User, c
 	// Получаем список подписок пользователя
 	topics, err := h.subRepo.GetUserSubscriptions(ctx, user.ID)
 	if err != nil {
-		log.Printf("Ошибка получения подписок пользователя: %v", err)
+		slog.Error("Ошибка получения подписок пользователя", "error", err)
 		h.sendMsg(chatID, "Произошла ошибка при получении ваших подписок.")
 		return
 	}
@@ -458,7 +564,7 @@ func (h *Handler) handleTopicNewsCallback(callback *tgbotapi.CallbackQuery) {
 	ctx := context.Background()
 	user, err := h.getOrCreateUser(callback.From)
 	if err != nil {
-		log.Printf("Ошибка поиска пользователя: %v", err)
+		slog.Error("Ошибка поиска пользователя", "error", err)
 		h.answerCallback(callback, "Произошла ошибка.")
 		return
 	}
@@ -474,17 +580,16 @@ func (h *Handler) handleTopicNewsCallback(callback *tgbotapi.CallbackQuery) {
 
 	// Запускаем поиск новостей в отдельной горутине
 	go func() {
+		ctx, cancel := context.WithTimeout(ctx, searchTimeout)
+		defer cancel()
+
 		// Получаем новости по теме
 		articles, err := h.fetchNewsForTopic(ctx, user, topic)
 		if err != nil {
-			log.Printf("Ошибка получения новостей по теме '%s': %v", topic, err)
+			slog.Error("Ошибка получения новостей по теме", "topic", topic, "error", err)
 
-			// Проверяем на ошибку лимита запросов
-			if strings.Contains(err.Error(), "request limit") || strings.Contains(err.Error(), "rate limit") {
-				h.sendMsg(callback.Message.Chat.ID, "❗ Достигнут лимит запросов к API. Пожалуйста, попробуйте позже.")
-			} else {
-				h.sendMsg(callback.Message.Chat.ID, "Произошла ошибка при получении новостей. Попробуйте другую тему или повторите запрос позже.")
-			}
+			h.sendMsg(callback.Message.Chat.ID, describeFetchError(user, err,
+				"Произошла ошибка при получении новостей. Попробуйте другую тему или повторите запрос позже."))
 			return
 		}
 
@@ -510,7 +615,7 @@ func (h *Handler) handleTopicNewsCallback(callback *tgbotapi.CallbackQuery) {
 		for _, article := range articlesToSend {
 			// Используем метод отправки статьи с кнопкой "В избранное"
 			if err := h.sendArticleWithFavoriteButton(ctx, callback.Message.Chat.ID, user.ID, article); err != nil {
-				log.Printf("Ошибка отправки новости: %v", err)
+				slog.Warn("Ошибка отправки новости", "error", err)
 				continue
 			}
 		}
@@ -537,7 +642,7 @@ func (h *Handler) filterSentArticles(ctx context.Context, userID uint, articles
 		// Проверяем, была ли статья уже отправлена
 		isSent, err := h.scheduler.IsArticleSent(ctx, userID, article.URL)
 		if err != nil {
-			log.Printf("Ошибка проверки отправленной статьи: %v", err)
+			slog.Error("Ошибка проверки отправленной статьи", "error", err)
 			continue
 		}
 
@@ -605,21 +710,25 @@ func (h *Handler) handleSearchNewsQuery(ctx context.Context, user *database.User
 		h.sendMsg(chatID, "❌ Поисковый запрос не может быть пустым. Пожалуйста, введите запрос для поиска новостей.")
 		return
 	}
+	if problem := describeQueryError(query); problem != "" {
+		h.sendMsg(chatID, problem)
+		return
+	}
 
 	// Отправляем сообщение о начале поиска
 	h.sendMsg(chatID, fmt.Sprintf("🔍 Ищу новости по запросу '%s'... Это может занять несколько секунд.", query))
 
 	// Запускаем поиск в отдельной горутине
 	go func() {
+		ctx, cancel := context.WithTimeout(ctx, searchTimeout)
+		defer cancel()
+
 		// Получаем новости по запросу
 		articles, err := h.scheduler.SearchNews(ctx, query)
 		if err != nil {
-			log.Printf("Ошибка поиска новостей по запросу '%s': %v", query, err)
-			if strings.Contains(err.Error(), "request limit") || strings.Contains(err.Error(), "rate limit") {
-				h.sendMsg(chatID, "❗ Достигнут лимит запросов к API. Пожалуйста, попробуйте позже.")
-			} else {
-				h.sendMsg(chatID, "Произошла ошибка при поиске новостей. Попробуйте другой запрос или повторите позже.")
-			}
+			slog.Error("Ошибка поиска новостей по запросу", "query", query, "error", err)
+			h.sendMsg(chatID, describeFetchError(user, err,
+				"Произошла ошибка при поиске новостей. Попробуйте другой запрос или повторите позже."))
 			return
 		}
 
@@ -632,7 +741,7 @@ func (h *Handler) handleSearchNewsQuery(ctx context.Context, user *database.User
 		// Фильтруем новости, которые уже были отправлены пользователю
 		freshArticles, err := h.filterSentArticles(ctx, user.ID, articles)
 		if err != nil {
-			log.Printf("Ошибка фильтрации отправленных статей: %v", err)
+			slog.Error("Ошибка фильтрации отправленных статей", "error", err)
 			h.sendMsg(chatID, "Произошла ошибка при обработке результатов. Пожалуйста, попробуйте позже.")
 			return
 		}
@@ -662,13 +771,13 @@ func (h *Handler) handleSearchNewsQuery(ctx context.Context, user *database.User
 		for _, article := range articlesToSend {
 			// Используем метод отправки статьи с кнопкой "В избранное"
 			if err := h.sendArticleWithFavoriteButton(ctx, chatID, user.ID, article); err != nil {
-				log.Printf("Ошибка отправки новости: %v", err)
+				slog.Warn("Ошибка отправки новости", "error", err)
 				continue
 			}
 
 			// Помечаем статью как отправленную
 			if err := h.scheduler.MarkArticleAsSent(ctx, user.ID, article.URL); err != nil {
-				log.Printf("Ошибка при маркировке статьи как отправленной: %v", err)
+				slog.Error("Ошибка при маркировке статьи как отправленной", "error", err)
 			}
 		}
 
@@ -689,7 +798,7 @@ func (h *Handler) handleResetHistory(ctx context.Context, user *database.User, c
 		// Сбрасываем историю отправленных статей
 		err := h.scheduler.ResetSentArticlesHistory(ctx, user.ID)
 		if err != nil {
-			log.Printf("Ошибка сброса истории отправленных статей: %v", err)
+			slog.Error("Ошибка сброса истории отправленных статей", "error", err)
 			h.sendMsg(chatID, "❌ Произошла ошибка при сбросе истории. Пожалуйста, попробуйте позже.")
 			return
 		}
@@ -703,7 +812,7 @@ func (h *Handler) handleUnsubscribeCallback(callback *tgbotapi.CallbackQuery) {
 	ctx := context.Background()
 	user, err := h.getOrCreateUser(callback.From)
 	if err != nil {
-		log.Printf("Ошибка поиска пользователя при отписке: %v", err)
+		slog.Error("Ошибка поиска пользователя при отписке", "error", err)
 		h.answerCallback(callback, "Произошла ошибка.")
 		return
 	}
@@ -719,8 +828,8 @@ func (h *Handler) handleUnsubscribeCallback(callback *tgbotapi.CallbackQuery) {
 	editMsg := tgbotapi.NewEditMessageText(callback.Message.Chat.ID, callback.Message.MessageID, responseText)
 	newKeyboard := h.removeButtonFromKeyboard(callback.Message.ReplyMarkup, callback.Data)
 	editMsg.ReplyMarkup = newKeyboard
-	if _, err := h.bot.Send(editMsg); err != nil {
-		log.Printf("Ошибка редактирования сообщения: %v", err)
+	if _, err := h.sender.Send(ctx, editMsg); err != nil {
+		slog.Warn("Ошибка редактирования сообщения", "error", err)
 	}
 }
 
@@ -759,14 +868,14 @@ func (h *Handler) sendMsg(chatID int64, text string, markup ...interface{}) {
 	if len(markup) > 0 {
 		msg.ReplyMarkup = markup[0]
 	}
-	if _, err := h.bot.Send(msg); err != nil {
-		log.Printf("Ошибка при отправке сообщения: %v", err)
+	if _, err := h.sender.Send(context.Background(), msg); err != nil {
+		slog.Warn("Ошибка при отправке сообщения", "error", err)
 	}
 }
 
 func (h *Handler) setUserState(ctx context.Context, userID uint, state string, chatID int64) {
 	if err := h.userRepo.SetUserState(ctx, userID, state); err != nil {
-		log.Printf("Failed to set user state for user %d: %v", userID, err)
+		slog.Error("Failed to set user state", "user_id", userID, "error", err)
 		h.sendMsg(chatID, "Произошла внутренняя ошибка. Попробуйте еще раз.")
 	}
 }
@@ -782,8 +891,8 @@ func (h *Handler) createUnsubscribeKeyboard(topics []string) tgbotapi.InlineKeyb
 
 func (h *Handler) answerCallback(callback *tgbotapi.CallbackQuery, text string) {
 	answer := tgbotapi.NewCallback(callback.ID, text)
-	if _, err := h.bot.Request(answer); err != nil {
-		log.Printf("Ошибка ответа на callback: %v", err)
+	if _, err := h.sender.Request(context.Background(), answer); err != nil {
+		slog.Warn("Ошибка ответа на callback", "error", err)
 	}
 }
 
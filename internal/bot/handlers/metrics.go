@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/pkg/metrics"
+)
+
+var (
+	updatesTotal = metrics.NewCounter("newsbot_updates_total",
+		"Обновления Telegram, полученные обработчиком, по типу.", "type")
+	commandDuration = metrics.NewHistogram("newsbot_command_duration_seconds",
+		"Время обработки команд бота в секундах.", nil, "command")
+)
+
+// updateType возвращает тип обновления для метрик.
+func updateType(update tgbotapi.Update) string {
+	switch {
+	case update.Message != nil && update.Message.IsCommand():
+		return "command"
+	case update.Message != nil:
+		return "message"
+	case update.CallbackQuery != nil:
+		return "callback_query"
+	case update.EditedMessage != nil:
+		return "edited_message"
+	}
+	return "other"
+}
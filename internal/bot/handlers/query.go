@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/query"
+)
+
+// queryHelp кратко описывает синтаксис запросов для сообщений об ошибке.
+const queryHelp = "*Как составить запрос:*\n" +
+	"`apple iphone` - оба слова\n" +
+	"`apple OR google` - любое из слов\n" +
+	"`apple -fruit` или `apple NOT fruit` - без слова\n" +
+	"`\"искусственный интеллект\"` - точная фраза\n" +
+	"`(apple OR google) AND ai` - группировка скобками"
+
+// describeQueryError проверяет запрос подписки или поиска и возвращает сообщение
+// для пользователя, если запрос составлен некорректно. Для корректного запроса
+// возвращается пустая строка.
+func describeQueryError(text string) string {
+	if _, err := query.Parse(text); err != nil {
+		return fmt.Sprintf("⚠️ Не удалось разобрать запрос: %s\n\n%s", err, queryHelp)
+	}
+	return ""
+}
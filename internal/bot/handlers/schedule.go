@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/scheduling"
+)
+
+// scheduleHelp объясняет формат расписания в ответ на /schedule без аргументов.
+const scheduleHelp = "Формат: `/schedule минуты часы день месяц день_недели`\n\n" +
+	"*Примеры:*\n" +
+	"`/schedule 0 9,18 * * 1-5` - по будням в 09:00 и 18:00\n" +
+	"`/schedule 30 8 * * *` - каждый день в 08:30\n" +
+	"`/schedule 0 */3 * * *` - каждые 3 часа\n" +
+	"`/schedule @weekdays` - по будням в 09:00\n" +
+	"`/schedule off` - вернуться к интервалу из настроек\n\n" +
+	"Время указывается в вашем часовом поясе (⚙️ Настройки → 🌍 Часовой пояс)."
+
+// handleSchedule показывает или меняет расписание уведомлений пользователя.
+func (h *Handler) handleSchedule(ctx context.Context, user *database.User, args string, chatID int64) {
+	args = strings.TrimSpace(args)
+
+	switch strings.ToLower(args) {
+	case "":
+		current := "не задано, используется интервал из настроек"
+		if user.Schedule != "" {
+			current = "`" + user.Schedule + "`"
+		}
+		h.sendMsg(chatID, fmt.Sprintf("🗓 Текущее расписание: %s\n\n%s", current, scheduleHelp))
+		return
+	case "off":
+		if err := h.userRepo.UpdateUserSchedule(ctx, user.ID, ""); err != nil {
+			slog.Error("Ошибка обновления расписания", "user_id", user.ID, "error", err)
+			h.sendMsg(chatID, "Не удалось обновить настройки.")
+			return
+		}
+		h.sendMsg(chatID, "🗓 Расписание отключено, новости будут приходить с интервалом из настроек.")
+		return
+	}
+
+	schedule, err := scheduling.Parse(args)
+	if err != nil {
+		// Текст ошибки содержит ввод пользователя и символы разметки, например "день_недели"
+		h.sendMsg(chatID, fmt.Sprintf("⚠️ Не удалось разобрать расписание: %s\n\n%s", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, err.Error()), scheduleHelp))
+		return
+	}
+
+	var upcoming []string
+	next := time.Now().In(userLocation(user))
+	for i := 0; i < 3; i++ {
+		next = schedule.Next(next)
+		if next.IsZero() {
+			break
+		}
+		upcoming = append(upcoming, next.Format("02.01 15:04"))
+	}
+	if len(upcoming) == 0 {
+		h.sendMsg(chatID, "⚠️ По этому расписанию уведомления никогда не придут. Проверьте даты.")
+		return
+	}
+
+	if err := h.userRepo.UpdateUserSchedule(ctx, user.ID, schedule.String()); err != nil {
+		slog.Error("Ошибка обновления расписания", "user_id", user.ID, "error", err)
+		h.sendMsg(chatID, "Не удалось обновить настройки.")
+		return
+	}
+
+	h.sendMsg(chatID, fmt.Sprintf("🗓 Расписание `%s` сохранено.\n\nБлижайшие уведомления: %s", schedule, strings.Join(upcoming, ", ")))
+}
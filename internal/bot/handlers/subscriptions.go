@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+)
+
+// subscriptionOption - вариант значения настройки темы. Пустое значение означает общие настройки.
+type subscriptionOption struct {
+	Value string
+	Label string
+}
+
+// subscriptionFields - редактируемые настройки темы и варианты их значений.
+var subscriptionFields = map[string]struct {
+	Title   string
+	Options []subscriptionOption
+}{
+	"interval": {"Как часто проверять тему:", []subscriptionOption{
+		{"", "Как в настройках"}, {"15", "15 минут"}, {"30", "30 минут"}, {"60", "1 час"},
+		{"180", "3 часа"}, {"720", "12 часов"}, {"1440", "1 день"},
+	}},
+	"limit": {"Сколько новостей по теме присылать за раз:", []subscriptionOption{
+		{"", "Как в настройках"}, {"1", "1"}, {"3", "3"}, {"5", "5"}, {"10", "10"},
+	}},
+	"lang": {"На каком языке искать новости:", []subscriptionOption{
+		{"", "По умолчанию"}, {"ru", "Русский"}, {"en", "English"}, {"de", "Deutsch"},
+		{"fr", "Français"}, {"es", "Español"},
+	}},
+	"country": {"Источники какой страны предпочитать:", []subscriptionOption{
+		{"", "По умолчанию"}, {"ru", "Россия"}, {"us", "США"}, {"gb", "Великобритания"},
+		{"de", "Германия"}, {"fr", "Франция"},
+	}},
+}
+
+// handleSubscriptionsList показывает подписки пользователя с кнопками настройки каждой темы.
+func (h *Handler) handleSubscriptionsList(ctx context.Context, user *database.User, chatID int64) {
+	subscriptions, err := h.subRepo.GetUserSubscriptionDetails(ctx, user.ID)
+	if err != nil {
+		slog.Error("Ошибка при получении подписок", "error", err)
+		h.sendMsg(chatID, "Ошибка при получении списка подписок.")
+		return
+	}
+	if len(subscriptions) == 0 {
+		h.sendMsg(chatID, "У вас пока нет подписок. 🤷‍♂️\n\nНажмите '✍️ Подписаться', чтобы добавить свою первую тему!")
+		return
+	}
+
+	text, keyboard := subscriptionsListView(subscriptions)
+	h.sendMsg(chatID, text, keyboard)
+}
+
+// Обработчик возврата к списку подписок из меню темы
+func (h *Handler) handleSubscriptionsBack(callback *tgbotapi.CallbackQuery) {
+	user, err := h.getOrCreateUser(callback.From)
+	if err != nil {
+		slog.Error("Ошибка поиска пользователя", "telegram_id", callback.From.ID, "error", err)
+		h.answerCallback(callback, "Произошла ошибка.")
+		return
+	}
+
+	subscriptions, err := h.subRepo.GetUserSubscriptionDetails(context.Background(), user.ID)
+	if err != nil {
+		slog.Error("Ошибка при получении подписок", "error", err)
+		h.answerCallback(callback, "Ошибка при получении списка подписок.")
+		return
+	}
+	if len(subscriptions) == 0 {
+		h.answerCallback(callback, "У вас пока нет подписок.")
+		return
+	}
+
+	text, keyboard := subscriptionsListView(subscriptions)
+	h.editSettingsMessage(callback, text, keyboard)
+}
+
+// subscriptionsListView формирует текст списка подписок и клавиатуру с кнопками настройки тем.
+func subscriptionsListView(subscriptions []database.Subscription) (string, tgbotapi.InlineKeyboardMarkup) {
+	var builder strings.Builder
+	builder.WriteString("📄 Ваши текущие подписки:\n\n")
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for _, sub := range subscriptions {
+		line := "• " + sub.Topic
+		if sub.Muted {
+			line += " 🔕"
+		}
+		builder.WriteString(line + "\n")
+
+		button := tgbotapi.NewInlineKeyboardButtonData("⚙️ "+sub.Topic, fmt.Sprintf("sub_%d", sub.ID))
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(button))
+	}
+	builder.WriteString("\nНажмите на тему, чтобы настроить ее отдельно от общих настроек.")
+
+	return builder.String(), tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// Обработчик открытия меню настроек темы
+func (h *Handler) handleSubscriptionMenu(callback *tgbotapi.CallbackQuery) {
+	id, err := strconv.ParseUint(strings.TrimPrefix(callback.Data, "sub_"), 10, 64)
+	if err != nil {
+		h.answerCallback(callback, "Некорректная подписка.")
+		return
+	}
+
+	sub, ok := h.callbackSubscription(callback, uint(id))
+	if !ok {
+		return
+	}
+	h.showSubscriptionMenu(callback, sub)
+}
+
+// showSubscriptionMenu показывает текущие настройки темы и кнопки их изменения.
+func (h *Handler) showSubscriptionMenu(callback *tgbotapi.CallbackQuery, sub *database.Subscription) {
+	notifications, muteLabel := "включены", "🔕 Выключить уведомления"
+	if sub.Muted {
+		notifications, muteLabel = "выключены", "🔔 Включить уведомления"
+	}
+
+	text := fmt.Sprintf("⚙️ Настройки темы «%s»\n\n"+
+		"Частота проверки: %s\n"+
+		"Новостей за раз: %s\n"+
+		"Язык: %s\n"+
+		"Страна: %s\n"+
+		"Уведомления: %s",
+		sub.Topic,
+		describeSubscriptionOption("interval", subscriptionIntervalValue(sub)),
+		describeSubscriptionOption("limit", subscriptionLimitValue(sub)),
+		describeSubscriptionOption("lang", sub.Language),
+		describeSubscriptionOption("country", sub.Country),
+		notifications,
+	)
+
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Частота", fmt.Sprintf("subopt_%d_interval", sub.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("Количество", fmt.Sprintf("subopt_%d_limit", sub.ID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Язык", fmt.Sprintf("subopt_%d_lang", sub.ID)),
+			tgbotapi.NewInlineKeyboardButtonData("Страна", fmt.Sprintf("subopt_%d_country", sub.ID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData(muteLabel, fmt.Sprintf("submute_%d", sub.ID)),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("Назад", "subs_back"),
+		),
+	)
+
+	h.editSettingsMessage(callback, text, keyboard)
+}
+
+// Обработчик открытия списка вариантов одной настройки темы
+func (h *Handler) handleSubscriptionOptions(callback *tgbotapi.CallbackQuery) {
+	idPart, fieldName, ok := strings.Cut(strings.TrimPrefix(callback.Data, "subopt_"), "_")
+	id, err := strconv.ParseUint(idPart, 10, 64)
+	field, known := subscriptionFields[fieldName]
+	if !ok || err != nil || !known {
+		h.answerCallback(callback, "Некорректная настройка.")
+		return
+	}
+
+	sub, found := h.callbackSubscription(callback, uint(id))
+	if !found {
+		return
+	}
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var currentRow []tgbotapi.InlineKeyboardButton
+	for i, option := range field.Options {
+		value := option.Value
+		if value == "" {
+			value = "-"
+		}
+		data := fmt.Sprintf("subset_%d_%s_%s", sub.ID, fieldName, value)
+		currentRow = append(currentRow, tgbotapi.NewInlineKeyboardButtonData(option.Label, data))
+		if len(currentRow) == 2 || i == len(field.Options)-1 {
+			rows = append(rows, currentRow)
+			currentRow = nil
+		}
+	}
+	rows = append(rows, tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("Назад", fmt.Sprintf("sub_%d", sub.ID)),
+	))
+
+	text := fmt.Sprintf("Тема «%s»\n\n%s", sub.Topic, field.Title)
+	h.editSettingsMessage(callback, text, tgbotapi.NewInlineKeyboardMarkup(rows...))
+}
+
+// Обработчик выбора значения настройки темы
+func (h *Handler) handleSubscriptionSetting(callback *tgbotapi.CallbackQuery) {
+	parts := strings.SplitN(strings.TrimPrefix(callback.Data, "subset_"), "_", 3)
+	if len(parts) != 3 {
+		h.answerCallback(callback, "Некорректная настройка.")
+		return
+	}
+	id, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		h.answerCallback(callback, "Некорректная подписка.")
+		return
+	}
+	fieldName, value := parts[1], parts[2]
+	if value == "-" {
+		value = ""
+	}
+	if !validSubscriptionOption(fieldName, value) {
+		h.answerCallback(callback, "Недопустимое значение.")
+		return
+	}
+
+	sub, ok := h.callbackSubscription(callback, uint(id))
+	if !ok {
+		return
+	}
+
+	settings := sub.SubscriptionSettings
+	number, _ := strconv.ParseUint(value, 10, 32)
+	switch fieldName {
+	case "interval":
+		settings.IntervalMinutes = uint(number)
+	case "limit":
+		settings.MaxArticles = uint(number)
+	case "lang":
+		settings.Language = value
+	case "country":
+		settings.Country = value
+	}
+
+	h.saveSubscriptionSettings(callback, sub, settings)
+}
+
+// Обработчик включения и выключения уведомлений по теме
+func (h *Handler) handleSubscriptionMute(callback *tgbotapi.CallbackQuery) {
+	id, err := strconv.ParseUint(strings.TrimPrefix(callback.Data, "submute_"), 10, 64)
+	if err != nil {
+		h.answerCallback(callback, "Некорректная подписка.")
+		return
+	}
+
+	sub, ok := h.callbackSubscription(callback, uint(id))
+	if !ok {
+		return
+	}
+
+	settings := sub.SubscriptionSettings
+	settings.Muted = !settings.Muted
+	h.saveSubscriptionSettings(callback, sub, settings)
+}
+
+// saveSubscriptionSettings сохраняет настройки темы и возвращает пользователя в ее меню.
+func (h *Handler) saveSubscriptionSettings(callback *tgbotapi.CallbackQuery, sub *database.Subscription, settings database.SubscriptionSettings) {
+	if err := h.subRepo.UpdateSubscriptionSettings(context.Background(), sub.UserID, sub.ID, settings); err != nil {
+		slog.Error("Ошибка обновления настроек подписки", "subscription_id", sub.ID, "error", err)
+		h.answerCallback(callback, "Не удалось обновить настройки.")
+		return
+	}
+
+	sub.SubscriptionSettings = settings
+	h.showSubscriptionMenu(callback, sub)
+}
+
+// callbackSubscription находит подписку пользователя, нажавшего кнопку.
+// Чужие и удаленные подписки не находятся; в этом случае пользователю отправляется ответ.
+func (h *Handler) callbackSubscription(callback *tgbotapi.CallbackQuery, id uint) (*database.Subscription, bool) {
+	user, err := h.getOrCreateUser(callback.From)
+	if err != nil {
+		slog.Error("Ошибка поиска пользователя", "telegram_id", callback.From.ID, "error", err)
+		h.answerCallback(callback, "Произошла ошибка.")
+		return nil, false
+	}
+
+	sub, err := h.subRepo.GetSubscription(context.Background(), user.ID, id)
+	if err != nil {
+		slog.Error("Ошибка получения подписки пользователя", "subscription_id", id, "user_id", user.ID, "error", err)
+		h.answerCallback(callback, "Подписка не найдена.")
+		return nil, false
+	}
+	return sub, true
+}
+
+// subscriptionIntervalValue возвращает интервал темы в виде значения из subscriptionFields.
+func subscriptionIntervalValue(sub *database.Subscription) string {
+	if sub.IntervalMinutes == 0 {
+		return ""
+	}
+	return strconv.FormatUint(uint64(sub.IntervalMinutes), 10)
+}
+
+// subscriptionLimitValue возвращает лимит темы в виде значения из subscriptionFields.
+func subscriptionLimitValue(sub *database.Subscription) string {
+	if sub.MaxArticles == 0 {
+		return ""
+	}
+	return strconv.FormatUint(uint64(sub.MaxArticles), 10)
+}
+
+// describeSubscriptionOption возвращает подпись значения настройки темы.
+func describeSubscriptionOption(fieldName, value string) string {
+	for _, option := range subscriptionFields[fieldName].Options {
+		if option.Value == value {
+			return option.Label
+		}
+	}
+	return value
+}
+
+// validSubscriptionOption проверяет, что значение есть среди вариантов настройки.
+func validSubscriptionOption(fieldName, value string) bool {
+	for _, option := range subscriptionFields[fieldName].Options {
+		if option.Value == value {
+			return true
+		}
+	}
+	return false
+}
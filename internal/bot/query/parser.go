@@ -0,0 +1,258 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ParseError описывает ошибку разбора запроса и позицию (в символах), где она обнаружена.
+type ParseError struct {
+	Pos int
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s (позиция %d)", e.Msg, e.Pos+1)
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenPhrase
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenMinus
+	tokenLParen
+	tokenRParen
+	tokenEOF
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// Parse разбирает запрос в синтаксическое дерево. AND связывает сильнее OR,
+// NOT и "-" относятся к ближайшему слову, фразе или группе в скобках.
+// Запрос должен находить хотя бы что-то: выражения из одних исключений не принимаются.
+func Parse(input string) (Node, error) {
+	if strings.TrimSpace(input) == "" {
+		return nil, &ParseError{Pos: 0, Msg: "пустой запрос"}
+	}
+	if utf8.RuneCountInString(input) > MaxLength {
+		return nil, &ParseError{Pos: MaxLength, Msg: fmt.Sprintf("запрос длиннее %d символов", MaxLength)}
+	}
+
+	tokens, err := tokenize(strings.ToLower(input))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	node, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		if tok.kind == tokenRParen {
+			return nil, &ParseError{Pos: tok.pos, Msg: "лишняя закрывающая скобка"}
+		}
+		return nil, &ParseError{Pos: tok.pos, Msg: fmt.Sprintf("неожиданное «%s»", tok.value)}
+	}
+	if p.terms > MaxTerms {
+		return nil, &ParseError{Pos: 0, Msg: fmt.Sprintf("в запросе больше %d слов", MaxTerms)}
+	}
+	if !positive(node) {
+		return nil, &ParseError{Pos: 0, Msg: "запрос состоит только из исключений, добавьте слово для поиска"}
+	}
+	return node, nil
+}
+
+// positive проверяет, что выражение может совпасть не только за счет отсутствия слов.
+func positive(node Node) bool {
+	switch n := node.(type) {
+	case *Term:
+		return true
+	case *And:
+		for _, child := range n.Nodes {
+			if positive(child) {
+				return true
+			}
+		}
+		return false
+	case *Or:
+		for _, child := range n.Nodes {
+			if !positive(child) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// tokenize разбивает запрос на слова, фразы, операторы и скобки.
+// Позиции токенов считаются в символах.
+func tokenize(input string) ([]token, error) {
+	runes := []rune(input)
+	var tokens []token
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, value: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, value: ")", pos: i})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, &ParseError{Pos: i, Msg: "не закрыта кавычка"}
+			}
+			phrase := strings.Join(strings.Fields(string(runes[i+1:end])), " ")
+			if phrase == "" {
+				return nil, &ParseError{Pos: i, Msg: "пустая фраза в кавычках"}
+			}
+			tokens = append(tokens, token{kind: tokenPhrase, value: phrase, pos: i})
+			i = end + 1
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && (i == 0 || unicode.IsSpace(runes[i-1]) || runes[i-1] == '('):
+			// Минус в начале слова - исключение; внутри слова ("covid-19") - часть слова
+			tokens = append(tokens, token{kind: tokenMinus, value: "-", pos: i})
+			i++
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' && runes[i] != '"' {
+				i++
+			}
+			word := string(runes[start:i])
+			kind := tokenWord
+			switch word {
+			case "and":
+				kind = tokenAnd
+			case "or":
+				kind = tokenOr
+			case "not":
+				kind = tokenNot
+			}
+			tokens = append(tokens, token{kind: kind, value: word, pos: start})
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, value: "конец запроса", pos: len(runes)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	terms  int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// parseOr разбирает выражение вида a OR b OR c.
+func (p *parser) parseOr(depth int) (Node, error) {
+	first, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []Node{first}
+	for p.peek().kind == tokenOr {
+		p.next()
+		node, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) == 1 {
+		return first, nil
+	}
+	return &Or{Nodes: nodes}, nil
+}
+
+// parseAnd разбирает выражение из условий, связанных явным или неявным AND.
+func (p *parser) parseAnd(depth int) (Node, error) {
+	first, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []Node{first}
+	for {
+		switch p.peek().kind {
+		case tokenAnd:
+			p.next()
+		case tokenWord, tokenPhrase, tokenNot, tokenMinus, tokenLParen:
+		default:
+			if len(nodes) == 1 {
+				return first, nil
+			}
+			return &And{Nodes: nodes}, nil
+		}
+
+		node, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+}
+
+// parseUnary разбирает исключение, группу в скобках, слово или фразу.
+func (p *parser) parseUnary(depth int) (Node, error) {
+	if depth > MaxDepth {
+		return nil, &ParseError{Pos: p.peek().pos, Msg: "слишком глубокая вложенность"}
+	}
+
+	tok := p.next()
+	switch tok.kind {
+	case tokenNot, tokenMinus:
+		node, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Node: node}, nil
+	case tokenLParen:
+		node, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, &ParseError{Pos: tok.pos, Msg: "не закрыта скобка"}
+		}
+		return node, nil
+	case tokenWord:
+		p.terms++
+		return &Term{Value: tok.value}, nil
+	case tokenPhrase:
+		p.terms++
+		return &Term{Value: tok.value, Phrase: true}, nil
+	case tokenEOF:
+		return nil, &ParseError{Pos: tok.pos, Msg: "запрос обрывается, ожидается слово"}
+	default:
+		return nil, &ParseError{Pos: tok.pos, Msg: fmt.Sprintf("ожидается слово, а не «%s»", tok.value)}
+	}
+}
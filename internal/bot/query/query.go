@@ -0,0 +1,204 @@
+// Package query разбирает поисковые запросы подписок с логическими операторами
+// в синтаксическое дерево, которое провайдеры переводят в свой синтаксис,
+// а бот использует для локальной фильтрации статей.
+//
+// Поддерживаемый синтаксис:
+//
+//	apple iphone               - оба слова (неявный AND)
+//	apple AND iphone           - то же самое
+//	apple OR iphone            - любое из слов
+//	NOT fruit, -fruit          - исключение
+//	"искусственный интеллект"  - точная фраза
+//	(apple OR google) -fruit   - группировка
+//
+// Операторы не зависят от регистра, так как темы подписок хранятся в нижнем регистре.
+package query
+
+import (
+	"strings"
+)
+
+const (
+	// MaxLength - максимальная длина запроса в символах.
+	MaxLength = 255
+	// MaxTerms - максимальное количество слов и фраз в запросе.
+	MaxTerms = 16
+	// MaxDepth - максимальная вложенность скобок и отрицаний.
+	MaxDepth = 8
+)
+
+// Node - узел синтаксического дерева запроса.
+type Node interface {
+	// String возвращает запрос в каноническом виде с явными операторами AND, OR и NOT.
+	String() string
+	// match проверяет узел на тексте в нижнем регистре.
+	match(text string) bool
+}
+
+// Term - слово или фраза в кавычках.
+type Term struct {
+	Value  string
+	Phrase bool
+}
+
+// Not - исключение: подходят статьи, в которых нет вложенного выражения.
+type Not struct {
+	Node Node
+}
+
+// And - все вложенные выражения должны совпасть.
+type And struct {
+	Nodes []Node
+}
+
+// Or - достаточно совпадения любого вложенного выражения.
+type Or struct {
+	Nodes []Node
+}
+
+func (t *Term) String() string {
+	if t.Phrase {
+		return `"` + t.Value + `"`
+	}
+	return t.Value
+}
+
+func (n *Not) String() string {
+	return "NOT " + group(n.Node)
+}
+
+// String выводит сначала обычные условия, затем исключения: некоторые провайдеры
+// не принимают запрос, который начинается с NOT.
+func (a *And) String() string {
+	parts := make([]string, 0, len(a.Nodes))
+	var negated []string
+	for _, node := range a.Nodes {
+		if _, ok := node.(*Not); ok {
+			negated = append(negated, node.String())
+			continue
+		}
+		parts = append(parts, group(node))
+	}
+	return strings.Join(append(parts, negated...), " AND ")
+}
+
+func (o *Or) String() string {
+	parts := make([]string, len(o.Nodes))
+	for i, node := range o.Nodes {
+		parts[i] = group(node)
+	}
+	return strings.Join(parts, " OR ")
+}
+
+// group заключает составное выражение в скобки.
+func group(node Node) string {
+	switch node.(type) {
+	case *And, *Or:
+		return "(" + node.String() + ")"
+	}
+	return node.String()
+}
+
+func (t *Term) match(text string) bool {
+	return strings.Contains(text, t.Value)
+}
+
+func (n *Not) match(text string) bool {
+	return !n.Node.match(text)
+}
+
+func (a *And) match(text string) bool {
+	for _, node := range a.Nodes {
+		if !node.match(text) {
+			return false
+		}
+	}
+	return true
+}
+
+func (o *Or) match(text string) bool {
+	for _, node := range o.Nodes {
+		if node.match(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match проверяет, подходит ли текст под запрос. Сравнение не учитывает регистр,
+// слова ищутся как подстроки, фразы - с точностью до пробелов.
+func Match(node Node, text string) bool {
+	return node.match(strings.Join(strings.Fields(strings.ToLower(text)), " "))
+}
+
+// Rewrite возвращает копию дерева, в которой каждое слово и фраза заменены результатом fn.
+// Если fn возвращает nil, терм остается без изменений.
+func Rewrite(node Node, fn func(term *Term) Node) Node {
+	switch n := node.(type) {
+	case *Term:
+		if replacement := fn(n); replacement != nil {
+			return replacement
+		}
+		return n
+	case *Not:
+		return &Not{Node: Rewrite(n.Node, fn)}
+	case *And:
+		return &And{Nodes: rewriteAll(n.Nodes, fn)}
+	case *Or:
+		return &Or{Nodes: rewriteAll(n.Nodes, fn)}
+	}
+	return node
+}
+
+func rewriteAll(nodes []Node, fn func(term *Term) Node) []Node {
+	rewritten := make([]Node, len(nodes))
+	for i, node := range nodes {
+		rewritten[i] = Rewrite(node, fn)
+	}
+	return rewritten
+}
+
+// IsSimple сообщает, что запрос - это одно или несколько слов без операторов
+// и фраз, то есть его понимает любой поисковик.
+func IsSimple(node Node) bool {
+	switch n := node.(type) {
+	case *Term:
+		return !n.Phrase
+	case *And:
+		for _, child := range n.Nodes {
+			if term, ok := child.(*Term); !ok || term.Phrase {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Keywords возвращает слова и фразы запроса, кроме исключений, в порядке появления.
+// Используется для провайдеров, которые не понимают логические операторы.
+func Keywords(node Node) []string {
+	var keywords []string
+	seen := make(map[string]bool)
+
+	var walk func(Node)
+	walk = func(node Node) {
+		switch n := node.(type) {
+		case *Term:
+			if !seen[n.Value] {
+				seen[n.Value] = true
+				keywords = append(keywords, n.Value)
+			}
+		case *And:
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		case *Or:
+			for _, child := range n.Nodes {
+				walk(child)
+			}
+		}
+	}
+	walk(node)
+	return keywords
+}
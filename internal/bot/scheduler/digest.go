@@ -0,0 +1,270 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/scheduling"
+)
+
+// digestMaxMessageLength - предел длины одного сообщения сводки с запасом до лимита Telegram в 4096 символов.
+const digestMaxMessageLength = 4000
+
+// processDigestUser откладывает свежие новости до сводки и отправляет сводку,
+// если наступило ее время или запуск принудительный. Время проверки пользователя
+// сдвигается, только если checked: наступило время пользователя и новости хотя бы
+// по одной теме получены. Возвращает количество отправленных новостей.
+func (s *Scheduler) processDigestUser(ctx context.Context, user database.User, force, checked bool, fresh []topicArticle, now time.Time) int {
+	added := s.addDigestItems(ctx, user, fresh)
+	if checked {
+		if err := s.userRepo.UpdateUserLastNotifiedAt(ctx, user.ID, now); err != nil {
+			slog.Error("Планировщик: не удалось обновить время последней проверки пользователя", "user_id", user.ID, "error", err)
+		}
+	}
+
+	if !force && !isDigestDue(user, now) {
+		if added > 0 {
+			slog.Info("Планировщик: новости отложены для сводки", "user_id", user.ID, "count", added)
+		}
+		return 0
+	}
+
+	// В тихие часы сводка ждет их окончания; принудительный запуск их не учитывает
+	if end, quiet := quietHoursEnd(user, now); quiet && !force {
+		slog.Info("Планировщик: у пользователя тихие часы, сводка будет отправлена после их окончания", "user_id", user.ID, "quiet_until", end)
+		return 0
+	}
+
+	return s.sendDigest(ctx, user, now)
+}
+
+// addDigestItems сохраняет новости для будущей сводки. Возвращает количество новых записей.
+func (s *Scheduler) addDigestItems(ctx context.Context, user database.User, fresh []topicArticle) int {
+	added := 0
+	for _, item := range fresh {
+		payload, err := json.Marshal(item.article)
+		if err != nil {
+			slog.Error("Планировщик: не удалось сериализовать статью", "url", item.article.URL, "error", err)
+			continue
+		}
+
+		created, err := s.digestRepo.AddDigestItem(ctx, &database.DigestItem{
+			UserID:      user.ID,
+			Topic:       item.topic,
+			ArticleHash: fetcher.ArticleKey(item.article.URL),
+			Payload:     string(payload),
+			Duplicates:  database.JoinDuplicates(item.duplicates),
+		})
+		if err != nil {
+			slog.Error("Планировщик: не удалось отложить статью для сводки", "user_id", user.ID, "error", err)
+			continue
+		}
+		if created {
+			added++
+		}
+	}
+	return added
+}
+
+// isArticleInDigest проверяет, отложена ли статья для сводки пользователя.
+func (s *Scheduler) isArticleInDigest(ctx context.Context, userID uint, articleHash string) bool {
+	inDigest, err := s.digestRepo.IsArticleInDigest(ctx, userID, articleHash)
+	if err != nil {
+		slog.Error("Ошибка при проверке новостей сводки", "error", err)
+		return false
+	}
+	return inDigest
+}
+
+// digestChunk - одно сообщение сводки и новости, которые в него вошли.
+type digestChunk struct {
+	text       string
+	ids        []uint
+	hashes     []string
+	topics     []string // Тема каждой новости из hashes
+	duplicates []string // Похожие статьи каждой новости из hashes, см. database.JoinDuplicates
+}
+
+// sendDigest отправляет накопленные новости одной сводкой, сгруппированной по темам.
+// Если сводка не помещается в одно сообщение, она разбивается на несколько.
+// При ошибке отправки неотправленные новости остаются до следующего цикла.
+func (s *Scheduler) sendDigest(ctx context.Context, user database.User, now time.Time) int {
+	items, err := s.digestRepo.GetDigestItems(ctx, user.ID)
+	if err != nil {
+		slog.Error("Планировщик: не удалось получить новости сводки", "user_id", user.ID, "error", err)
+		return 0
+	}
+
+	// Темы с собственным лимитом ограничиваются им, остальные - лимитом пользователя
+	limits := make(map[string]int)
+	subscriptions, err := s.subRepo.GetUserSubscriptionDetails(ctx, user.ID)
+	if err != nil {
+		slog.Error("Планировщик: не удалось получить подписки пользователя", "user_id", user.ID, "error", err)
+	}
+	for _, sub := range subscriptions {
+		if sub.MaxArticles > 0 {
+			limits[sub.Topic] = int(sub.MaxArticles)
+		}
+	}
+
+	chunks, dropped := buildDigest(user, items, limits, now)
+
+	sent := 0
+	for _, chunk := range chunks {
+		msg := tgbotapi.NewMessage(user.TelegramID, chunk.text)
+		msg.ParseMode = tgbotapi.ModeHTML
+		msg.DisableWebPagePreview = true
+
+		if _, err := s.sender.Send(ctx, msg); err != nil {
+			slog.Warn("Планировщик: не удалось отправить сводку", "user_id", user.ID, "error", err)
+			return sent
+		}
+
+		// Помечаем новости только после подтверждения доставки от Telegram
+		for i, hash := range chunk.hashes {
+			s.markArticleAsSent(ctx, user.ID, hash)
+			s.markDuplicatesAsSent(ctx, user.ID, chunk.duplicates[i])
+			articlesSent.Inc(topicLabel(chunk.topics[i]))
+		}
+		if err := s.digestRepo.DeleteDigestItems(ctx, chunk.ids); err != nil {
+			slog.Error("Планировщик: не удалось удалить отправленные новости сводки", "user_id", user.ID, "error", err)
+		}
+		sent += len(chunk.hashes)
+	}
+
+	// Новости сверх лимита по теме в сводку не попадают. Они помечаются как отправленные,
+	// иначе следующий цикл снова сочтет их свежими и отложит для новой сводки
+	droppedIDs := make([]uint, 0, len(dropped))
+	for _, item := range dropped {
+		s.markArticleAsSent(ctx, user.ID, item.ArticleHash)
+		s.markDuplicatesAsSent(ctx, user.ID, item.Duplicates)
+		droppedIDs = append(droppedIDs, item.ID)
+	}
+	if err := s.digestRepo.DeleteDigestItems(ctx, droppedIDs); err != nil {
+		slog.Error("Планировщик: не удалось удалить лишние новости сводки", "user_id", user.ID, "error", err)
+	}
+	if err := s.userRepo.UpdateUserLastDigestAt(ctx, user.ID, now); err != nil {
+		slog.Error("Планировщик: не удалось обновить время сводки", "user_id", user.ID, "error", err)
+	}
+
+	slog.Info("Планировщик: отправлена сводка", "user_id", user.ID, "articles", sent, "messages", len(chunks))
+	return sent
+}
+
+// buildDigest группирует новости по темам и разбивает сводку на сообщения.
+// В каждую тему попадает не больше новостей, чем задано в limits для темы или
+// в настройках пользователя; остальные новости возвращаются вторым значением.
+func buildDigest(user database.User, items []database.DigestItem, limits map[string]int, now time.Time) ([]digestChunk, []database.DigestItem) {
+	var topics []string
+	byTopic := make(map[string][]database.DigestItem)
+	var dropped []database.DigestItem
+	for _, item := range items {
+		if _, ok := byTopic[item.Topic]; !ok {
+			topics = append(topics, item.Topic)
+		}
+		limit, ok := limits[item.Topic]
+		if !ok {
+			limit = userNewsLimit(user)
+		}
+		if len(byTopic[item.Topic]) >= limit {
+			dropped = append(dropped, item)
+			continue
+		}
+		byTopic[item.Topic] = append(byTopic[item.Topic], item)
+	}
+
+	if len(topics) == 0 {
+		return nil, dropped
+	}
+
+	title := "Ежедневная сводка новостей"
+	if user.DigestPeriod == database.DigestPeriodWeekly {
+		title = "Еженедельная сводка новостей"
+	}
+	header := fmt.Sprintf("📰 <b>%s</b> · %s\n", title, now.In(userLocation(user)).Format("02.01.2006"))
+
+	var chunks []digestChunk
+	current := digestChunk{text: header}
+	for _, topic := range topics {
+		section := fmt.Sprintf("\n<b>🔹 %s</b>\n", html.EscapeString(topic))
+		for _, item := range byTopic[topic] {
+			var article fetcher.Article
+			if err := json.Unmarshal([]byte(item.Payload), &article); err != nil {
+				slog.Error("Планировщик: повреждена новость сводки", "item_id", item.ID, "error", err)
+				dropped = append(dropped, item)
+				continue
+			}
+
+			line := formatDigestLine(article)
+			if len(current.text)+len(section)+len(line) > digestMaxMessageLength && len(current.ids) > 0 {
+				chunks = append(chunks, current)
+				current = digestChunk{}
+				section = fmt.Sprintf("<b>🔹 %s</b>\n", html.EscapeString(topic))
+			}
+			current.text += section + line
+			section = ""
+			current.ids = append(current.ids, item.ID)
+			current.hashes = append(current.hashes, item.ArticleHash)
+			current.topics = append(current.topics, item.Topic)
+			current.duplicates = append(current.duplicates, item.Duplicates)
+		}
+	}
+	if len(current.ids) > 0 {
+		chunks = append(chunks, current)
+	}
+	return chunks, dropped
+}
+
+// formatDigestLine форматирует одну новость сводки: заголовок-ссылка и источник.
+func formatDigestLine(article fetcher.Article) string {
+	title := html.EscapeString(strings.TrimSpace(article.Title))
+	if title == "" {
+		title = "Без заголовка"
+	}
+
+	line := fmt.Sprintf("• <a href=\"%s\">%s</a>", html.EscapeString(article.URL), title)
+	if article.Source.Name != "" {
+		line += fmt.Sprintf(" <i>(%s)</i>", html.EscapeString(article.Source.Name))
+	}
+	if len(article.AlsoReportedBy) > 0 {
+		line += fmt.Sprintf(" <i>также: %s</i>", html.EscapeString(strings.Join(article.AlsoReportedBy, ", ")))
+	}
+	return line + "\n"
+}
+
+// isDigestDue проверяет, наступило ли время очередной сводки пользователя.
+func isDigestDue(user database.User, now time.Time) bool {
+	if user.LastDigestAt == nil {
+		return true
+	}
+	return user.LastDigestAt.Before(previousDigestTime(user, now))
+}
+
+// previousDigestTime возвращает последний момент не позже now, когда по расписанию
+// пользователя должна была уйти сводка. Время сводки задается в часовом поясе пользователя.
+func previousDigestTime(user database.User, now time.Time) time.Time {
+	hour, minute, err := scheduling.ParseClock(user.DigestTime)
+	if err != nil {
+		hour, minute, _ = scheduling.ParseClock(database.DefaultDigestTime)
+	}
+
+	local := now.In(userLocation(user))
+	scheduled := clockOn(local, hour, minute)
+	if scheduled.After(local) {
+		scheduled = scheduled.AddDate(0, 0, -1)
+	}
+	if user.DigestPeriod == database.DigestPeriodWeekly {
+		for scheduled.Weekday() != time.Monday {
+			scheduled = scheduled.AddDate(0, 0, -1)
+		}
+	}
+	return scheduled
+}
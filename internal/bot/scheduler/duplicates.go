@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"context"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
+)
+
+// duplicateWindow - в пределах какого времени публикации похожие статьи считаются одной новостью.
+const duplicateWindow = 48 * time.Hour
+
+// mergeNearDuplicates объединяет статьи, пересказывающие одну новость из разных источников.
+// Остается первая статья группы (порядок тем и провайдеров сохраняется), остальные
+// источники перечисляются в ее AlsoReportedBy, а ключи остальных статей сохраняются
+// вместе с ней в очереди или сводке, чтобы после доставки представителя пометить их как отправленные.
+func mergeNearDuplicates(fresh []topicArticle) []topicArticle {
+	if len(fresh) < 2 {
+		return fresh
+	}
+
+	articles := make([]fetcher.Article, len(fresh))
+	for i, item := range fresh {
+		articles[i] = item.article
+	}
+
+	merged := make([]topicArticle, 0, len(fresh))
+	for _, group := range fetcher.ClusterNearDuplicates(articles, duplicateWindow) {
+		item := fresh[group[0]]
+		if len(group) > 1 {
+			duplicates := make([]fetcher.Article, 0, len(group)-1)
+			for _, index := range group[1:] {
+				duplicates = append(duplicates, fresh[index].article)
+				item.duplicates = append(item.duplicates, fetcher.ArticleKey(fresh[index].article.URL))
+			}
+			item.article = fetcher.MergeDuplicates(item.article, duplicates)
+		}
+		merged = append(merged, item)
+	}
+	return merged
+}
+
+// countNearDuplicates учитывает в метрике похожие статьи из разных источников в ответе
+// провайдеров по теме. Вызывается один раз на полученную пачку статей, до раздачи
+// подписчикам, поэтому значение не зависит от числа подписчиков темы.
+func countNearDuplicates(topic string, articles []fetcher.Article) {
+	duplicates := 0
+	for _, group := range fetcher.ClusterNearDuplicates(articles, duplicateWindow) {
+		duplicates += len(group) - 1
+	}
+	if duplicates > 0 {
+		articlesDeduplicated.Add(float64(duplicates), topicLabel(topic))
+	}
+}
+
+// markDuplicatesAsSent помечает как отправленные статьи, объединенные с доставленным
+// представителем (поле Duplicates сообщения очереди или новости сводки), чтобы они
+// не пришли пользователю в следующих циклах.
+func (s *Scheduler) markDuplicatesAsSent(ctx context.Context, userID uint, duplicates string) {
+	for _, key := range database.SplitDuplicates(duplicates) {
+		s.markArticleAsSent(ctx, userID, key)
+	}
+}
+
+// alsoReportedBy возвращает строку сообщения "Также сообщают: ..." с другими источниками
+// новости или пустую строку, если статья не объединялась с другими.
+func alsoReportedBy(article fetcher.Article) string {
+	if len(article.AlsoReportedBy) == 0 {
+		return ""
+	}
+	return "<i>🔁 Также сообщают: " + html.EscapeString(strings.Join(article.AlsoReportedBy, ", ")) + "</i>\n\n"
+}
@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/pkg/metrics"
+)
+
+var (
+	cycleDuration = metrics.NewHistogram("newsbot_scheduler_cycle_duration_seconds",
+		"Длительность цикла рассылки в секундах.", []float64{1, 5, 15, 30, 60, 120, 300, 600})
+	articlesFetched = metrics.NewCounter("newsbot_articles_fetched_total",
+		"Статьи, полученные от провайдеров по темам подписок.", "topic")
+	articlesDeduplicated = metrics.NewCounter("newsbot_articles_deduplicated_total",
+		"Похожие статьи из других источников в ответах провайдеров по темам подписок.", "topic")
+	articlesSent = metrics.NewCounter("newsbot_articles_sent_total",
+		"Статьи, доставленные подписчикам сообщениями и в сводках.", "topic")
+	outboxDepth = metrics.NewGauge("newsbot_outbox_depth",
+		"Сообщения в очереди, ожидающие доставки.")
+)
+
+// metricsTopTopics - сколько самых популярных тем получают собственное значение метки topic.
+// Остальные темы, в том числе произвольные запросы пользователей, учитываются как "other",
+// чтобы число рядов метрик не росло с каждой новой темой.
+const metricsTopTopics = 20
+
+var (
+	labeledTopicsMu sync.RWMutex
+	labeledTopics   = make(map[string]bool)
+)
+
+// topicLabel возвращает значение метки topic для темы подписки.
+func topicLabel(topic string) string {
+	labeledTopicsMu.RLock()
+	defer labeledTopicsMu.RUnlock()
+	if labeledTopics[topic] {
+		return topic
+	}
+	return "other"
+}
+
+// setLabeledTopics выбирает для меток метрик темы с наибольшим числом подписчиков.
+func setLabeledTopics(subscribers map[string]int) {
+	topics := make([]string, 0, len(subscribers))
+	for topic := range subscribers {
+		topics = append(topics, topic)
+	}
+	sort.Slice(topics, func(i, j int) bool {
+		if subscribers[topics[i]] != subscribers[topics[j]] {
+			return subscribers[topics[i]] > subscribers[topics[j]]
+		}
+		return topics[i] < topics[j]
+	})
+	if len(topics) > metricsTopTopics {
+		topics = topics[:metricsTopTopics]
+	}
+
+	labeled := make(map[string]bool, len(topics))
+	for _, topic := range topics {
+		labeled[topic] = true
+	}
+	labeledTopicsMu.Lock()
+	labeledTopics = labeled
+	labeledTopicsMu.Unlock()
+}
+
+// updateOutboxDepth обновляет метрику размера очереди доставки.
+func (s *Scheduler) updateOutboxDepth(ctx context.Context) {
+	count, err := s.outboxRepo.CountPendingOutboxMessages(ctx)
+	if err != nil {
+		slog.Warn("Диспетчер: не удалось посчитать сообщения в очереди", "error", err)
+		return
+	}
+	outboxDepth.Set(float64(count))
+}
@@ -0,0 +1,212 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
+)
+
+const (
+	dispatchInterval  = 15 * time.Second // Как часто диспетчер проверяет очередь
+	dispatchBatchSize = 50               // Сколько сообщений обрабатывается за один проход
+	outboxLease       = 2 * time.Minute  // На сколько сообщение резервируется за обработчиком
+	outboxBaseBackoff = 30 * time.Second // Задержка перед первой повторной попыткой
+	outboxMaxBackoff  = time.Hour        // Максимальная задержка между попытками
+	outboxMaxAttempts = 8                // После стольких неудач сообщение помечается как failed
+	outboxPruneEvery  = time.Hour        // Как часто из очереди удаляются устаревшие недоставленные сообщения
+)
+
+// runDispatcher периодически доставляет сообщения из очереди, для которых наступило
+// время повторной попытки, и удаляет устаревшие недоставленные сообщения.
+// Очередь хранится в БД, поэтому доставка переживает перезапуск.
+func (s *Scheduler) runDispatcher() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(dispatchInterval)
+	defer ticker.Stop()
+	pruneTicker := time.NewTicker(outboxPruneEvery)
+	defer pruneTicker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if delivered := s.deliverOutbox(s.ctx, 0); delivered > 0 {
+				slog.Info("Диспетчер: доставлены сообщения из очереди", "count", delivered)
+			}
+			s.updateOutboxDepth(s.ctx)
+		case <-pruneTicker.C:
+			s.pruneOutbox(s.ctx, time.Now())
+		case <-s.stop:
+			slog.Info("Диспетчер очереди сообщений остановлен")
+			return
+		}
+	}
+}
+
+// pruneOutbox удаляет недоставленные окончательно сообщения, статьи которых уже
+// слишком стары, чтобы снова попасть в рассылку. Более свежие записи остаются и не
+// дают поставить ту же статью в очередь повторно.
+func (s *Scheduler) pruneOutbox(ctx context.Context, now time.Time) {
+	pruned, err := s.outboxRepo.PruneFailedOutboxMessages(ctx, now.Add(-freshArticleMaxAge))
+	if err != nil {
+		slog.Error("Диспетчер: не удалось очистить очередь от недоставленных сообщений", "error", err)
+		return
+	}
+	if pruned > 0 {
+		slog.Info("Диспетчер: удалены устаревшие недоставленные сообщения", "count", pruned)
+	}
+}
+
+// enqueueArticles помещает статьи в очередь доставки пользователю. Если deliverAt не нулевое,
+// сообщения не отправляются раньше этого момента. Возвращает количество новых сообщений в очереди.
+func (s *Scheduler) enqueueArticles(ctx context.Context, user database.User, articles []topicArticle, deliverAt time.Time) int {
+	queued := 0
+	for _, item := range articles {
+		article := item.article
+		payload, err := json.Marshal(article)
+		if err != nil {
+			slog.Error("Планировщик: не удалось сериализовать статью", "url", article.URL, "error", err)
+			continue
+		}
+
+		created, err := s.outboxRepo.EnqueueOutboxMessage(ctx, &database.OutboxMessage{
+			UserID:        user.ID,
+			ChatID:        user.TelegramID,
+			ArticleHash:   fetcher.ArticleKey(article.URL),
+			Topic:         item.topic,
+			Payload:       string(payload),
+			Duplicates:    database.JoinDuplicates(item.duplicates),
+			NextAttemptAt: deliverAt,
+		})
+		if err != nil {
+			slog.Error("Планировщик: не удалось поставить статью в очередь", "user_id", user.ID, "error", err)
+			continue
+		}
+		if created {
+			queued++
+		}
+	}
+	return queued
+}
+
+// isArticleQueued проверяет, ожидает ли статья доставки в очереди или уже не была доставлена окончательно.
+func (s *Scheduler) isArticleQueued(ctx context.Context, userID uint, articleHash string) bool {
+	queued, err := s.outboxRepo.IsArticleQueued(ctx, userID, articleHash)
+	if err != nil {
+		slog.Error("Ошибка при проверке очереди сообщений", "error", err)
+		return false
+	}
+	return queued
+}
+
+// deliverOutbox доставляет сообщения, время отправки которых наступило.
+// Если userID не равен 0, обрабатываются только сообщения этого пользователя.
+// Возвращает количество доставленных сообщений.
+func (s *Scheduler) deliverOutbox(ctx context.Context, userID uint) int {
+	messages, err := s.outboxRepo.GetDueOutboxMessages(ctx, userID, time.Now(), dispatchBatchSize)
+	if err != nil {
+		slog.Error("Диспетчер: не удалось получить сообщения из очереди", "error", err)
+		return 0
+	}
+
+	delivered := 0
+	for _, msg := range messages {
+		if ctx.Err() != nil {
+			// Остановка: недоставленные сообщения остаются в очереди до следующего запуска
+			break
+		}
+		if s.deliverOutboxMessage(ctx, msg) {
+			delivered++
+		}
+	}
+	return delivered
+}
+
+// deliverOutboxMessage отправляет одно сообщение из очереди и фиксирует результат.
+func (s *Scheduler) deliverOutboxMessage(ctx context.Context, msg database.OutboxMessage) bool {
+	now := time.Now()
+
+	// Резервируем сообщение, чтобы его не отправил параллельный обработчик
+	claimed, err := s.outboxRepo.ClaimOutboxMessage(ctx, msg.ID, now, now.Add(outboxLease))
+	if err != nil {
+		slog.Error("Диспетчер: не удалось зарезервировать сообщение", "message_id", msg.ID, "error", err)
+		return false
+	}
+	if !claimed {
+		return false
+	}
+
+	var article fetcher.Article
+	if err := json.Unmarshal([]byte(msg.Payload), &article); err != nil {
+		slog.Error("Диспетчер: повреждено сообщение в очереди", "message_id", msg.ID, "error", err)
+		s.markOutboxFailed(ctx, msg, err, now, true)
+		return false
+	}
+
+	if err := s.sendArticleWithFavoriteButton(ctx, msg.ChatID, msg.UserID, article); err != nil {
+		if ctx.Err() != nil {
+			// Отправка прервана остановкой, попытка не засчитывается: сообщение
+			// вернется в очередь после истечения резерва
+			return false
+		}
+		delay, giveUp := outboxRetryDelay(msg.Attempts+1, err)
+		s.markOutboxFailed(ctx, msg, err, now.Add(delay), giveUp)
+		return false
+	}
+
+	// Помечаем статью и объединенные с ней похожие статьи только после подтверждения доставки от Telegram
+	s.markArticleAsSent(ctx, msg.UserID, msg.ArticleHash)
+	s.markDuplicatesAsSent(ctx, msg.UserID, msg.Duplicates)
+	articlesSent.Inc(topicLabel(msg.Topic))
+	if err := s.outboxRepo.MarkOutboxMessageDelivered(ctx, msg.ID); err != nil {
+		slog.Error("Диспетчер: не удалось удалить доставленное сообщение", "message_id", msg.ID, "error", err)
+	}
+	return true
+}
+
+func (s *Scheduler) markOutboxFailed(ctx context.Context, msg database.OutboxMessage, sendErr error, nextAttemptAt time.Time, giveUp bool) {
+	if giveUp {
+		slog.Warn("Диспетчер: сообщение не доставлено окончательно", "message_id", msg.ID, "user_id", msg.UserID, "error", sendErr)
+	} else {
+		slog.Warn("Диспетчер: сообщение не доставлено, будет повторная попытка",
+			"message_id", msg.ID, "user_id", msg.UserID, "attempt", msg.Attempts+1, "next_attempt_at", nextAttemptAt, "error", sendErr)
+	}
+
+	if err := s.outboxRepo.MarkOutboxMessageFailed(ctx, msg.ID, sendErr.Error(), nextAttemptAt, giveUp); err != nil {
+		slog.Error("Диспетчер: не удалось обновить сообщение", "message_id", msg.ID, "error", err)
+	}
+}
+
+// outboxRetryDelay вычисляет задержку перед следующей попыткой доставки.
+// Задержка растет экспоненциально; при ответе 429 используется retry_after от Telegram.
+// Второе значение сообщает, что повторять доставку не нужно: ошибка постоянная
+// или исчерпаны outboxMaxAttempts попыток.
+func outboxRetryDelay(attempts uint, err error) (time.Duration, bool) {
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) {
+		switch {
+		case tgErr.RetryAfter > 0:
+			// Ответы 429 тоже расходуют попытки, иначе чат с постоянным ограничением не выйдет из очереди
+			return time.Duration(tgErr.RetryAfter) * time.Second, attempts >= outboxMaxAttempts
+		case tgErr.Code == http.StatusForbidden || tgErr.Code == http.StatusBadRequest:
+			// Бот заблокирован или запрос некорректен: повтор не поможет
+			return 0, true
+		}
+	}
+
+	if attempts >= outboxMaxAttempts {
+		return 0, true
+	}
+
+	delay := outboxBaseBackoff << (attempts - 1)
+	if delay <= 0 || delay > outboxMaxBackoff {
+		delay = outboxMaxBackoff
+	}
+	return delay, false
+}
@@ -0,0 +1,68 @@
+package scheduler
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/scheduling"
+)
+
+// userLocation возвращает часовой пояс пользователя. Если он не задан или некорректен,
+// используется часовой пояс сервера.
+func userLocation(user database.User) *time.Location {
+	if user.TimeZone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(user.TimeZone)
+	if err != nil {
+		slog.Warn("Планировщик: некорректный часовой пояс пользователя", "user_id", user.ID, "time_zone", user.TimeZone, "error", err)
+		return time.Local
+	}
+	return loc
+}
+
+// quietHoursEnd проверяет, попадает ли now в тихие часы пользователя, и возвращает
+// момент их окончания. Окно может переходить через полночь, например 22:00-08:00.
+func quietHoursEnd(user database.User, now time.Time) (time.Time, bool) {
+	if user.QuietHoursStart == "" || user.QuietHoursEnd == "" {
+		return time.Time{}, false
+	}
+
+	startHour, startMinute, err := scheduling.ParseClock(user.QuietHoursStart)
+	if err != nil {
+		return time.Time{}, false
+	}
+	endHour, endMinute, err := scheduling.ParseClock(user.QuietHoursEnd)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	local := now.In(userLocation(user))
+	start := clockOn(local, startHour, startMinute)
+	end := clockOn(local, endHour, endMinute)
+
+	switch {
+	case start.Equal(end):
+		return time.Time{}, false
+	case start.Before(end):
+		// Окно в пределах одних суток
+		if !local.Before(start) && local.Before(end) {
+			return end, true
+		}
+	default:
+		// Окно переходит через полночь
+		if !local.Before(start) {
+			return end.AddDate(0, 0, 1), true
+		}
+		if local.Before(end) {
+			return end, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// clockOn возвращает момент с указанным временем суток в день и часовом поясе day.
+func clockOn(day time.Time, hour, minute int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, 0, 0, day.Location())
+}
@@ -2,98 +2,205 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
 	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/scheduling"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/sender"
 	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/utils"
 )
 
+// Options задает параметры цикла планировщика.
+type Options struct {
+	Interval     time.Duration // Как часто запускается цикл
+	Workers      int           // Сколько пользователей обрабатывается одновременно
+	BatchSize    int           // Сколько пользователей читается из БД за один запрос
+	CycleTimeout time.Duration // Максимальная длительность одного цикла
+}
+
+// DefaultOptions возвращает параметры планировщика по умолчанию.
+func DefaultOptions() Options {
+	return Options{
+		Interval:     time.Minute,
+		Workers:      8,
+		BatchSize:    100,
+		CycleTimeout: 5 * time.Minute,
+	}
+}
+
+// withDefaults подставляет значения по умолчанию вместо незаданных параметров.
+func (o Options) withDefaults() Options {
+	defaults := DefaultOptions()
+	if o.Interval <= 0 {
+		o.Interval = defaults.Interval
+	}
+	if o.Workers <= 0 {
+		o.Workers = defaults.Workers
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = defaults.BatchSize
+	}
+	if o.CycleTimeout <= 0 {
+		o.CycleTimeout = defaults.CycleTimeout
+	}
+	return o
+}
+
+// freshArticleMaxAge - статьи старше этого возраста не отправляются (примерно полгода).
+const freshArticleMaxAge = 183 * 24 * time.Hour
+
 // Scheduler управляет периодической отправкой новостей.
 // Он будет запрашивать новости и рассылать их подписчикам.
 type Scheduler struct {
-	bot                 *tgbotapi.BotAPI
+	sender              *sender.Sender
 	userRepo            database.UserRepository
 	subRepo             database.SubscriptionRepository
 	sentArticleRepo     database.SentArticleRepository
 	favoriteArticleRepo database.FavoriteArticleRepository
+	outboxRepo          database.OutboxRepository
+	digestRepo          database.DigestRepository
 	fetcher             *fetcher.Fetcher
-	interval            time.Duration
+	options             Options
 	stop                chan struct{}
-	sentArticles        map[string]map[string]bool // Локальный кэш для оптимизации (будет постепенно заменен на БД)
+	running             atomic.Bool  // Выполняется ли сейчас цикл рассылки
+	startedAt           atomic.Int64 // Время запуска планировщика, Unix-наносекунды
+	lastCycleAt         atomic.Int64 // Время завершения последнего успешного цикла, Unix-наносекунды
+
+	// ctx отменяется в Stop и прерывает циклы рассылки, доставку очереди
+	// и запросы к провайдерам, начатые через планировщик
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup // Горутины планировщика и выполняющийся цикл
+	stopOnce sync.Once
+
+	sentMu       sync.Mutex
+	sentArticles map[string]map[string]bool // Локальный кэш для оптимизации (будет постепенно заменен на БД)
 }
 
 // NewScheduler создает новый экземпляр планировщика.
 func NewScheduler(
-	bot *tgbotapi.BotAPI,
+	sender *sender.Sender,
 	userRepo database.UserRepository,
 	subRepo database.SubscriptionRepository,
 	sentArticleRepo database.SentArticleRepository,
 	favoriteArticleRepo database.FavoriteArticleRepository,
+	outboxRepo database.OutboxRepository,
+	digestRepo database.DigestRepository,
 	fetcher *fetcher.Fetcher,
-	interval time.Duration,
+	options Options,
 ) *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Scheduler{
-		bot:                 bot,
+		sender:              sender,
 		userRepo:            userRepo,
 		subRepo:             subRepo,
 		sentArticleRepo:     sentArticleRepo,
 		favoriteArticleRepo: favoriteArticleRepo,
+		outboxRepo:          outboxRepo,
+		digestRepo:          digestRepo,
 		fetcher:             fetcher,
-		interval:            interval,
+		options:             options.withDefaults(),
 		stop:                make(chan struct{}),
+		ctx:                 ctx,
+		cancel:              cancel,
 		sentArticles:        make(map[string]map[string]bool),
 	}
 }
 
-// Start запускает цикл планировщика в отдельной горутине.
+// Start запускает цикл планировщика и диспетчер очереди сообщений в отдельных горутинах.
 func (s *Scheduler) Start() {
-	log.Println("Запуск планировщика новостей с интервалом:", s.interval)
-	ticker := time.NewTicker(s.interval)
+	slog.Info("Запуск планировщика новостей", "interval", s.options.Interval, "workers", s.options.Workers,
+		"batch_size", s.options.BatchSize, "cycle_timeout", s.options.CycleTimeout)
+	ticker := time.NewTicker(s.options.Interval)
+	s.startedAt.Store(time.Now().UnixNano())
+
+	s.wg.Add(2)
+	go s.runDispatcher()
 
 	go func() {
+		defer s.wg.Done()
 		for {
 			select {
 			case <-ticker.C:
-				s.sendNewsUpdates()
+				s.startCycle()
 			case <-s.stop:
 				ticker.Stop()
-				log.Println("Планировщик новостей остановлен.")
+				slog.Info("Планировщик новостей остановлен")
 				return
 			}
 		}
 	}()
 }
 
-// Stop останавливает цикл планировщика.
+// Stop останавливает планировщик: отменяет выполняющийся цикл рассылки и запросы
+// к провайдерам и дожидается их завершения. После возврата планировщик не обращается
+// к базе данных, и ее можно закрывать.
 func (s *Scheduler) Stop() {
-	close(s.stop)
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		s.cancel()
+		if s.running.Load() {
+			slog.Info("Планировщик: ожидаю завершения прерванного цикла рассылки")
+		}
+		s.wg.Wait()
+	})
+}
+
+// HealthCheck возвращает ошибку, если планировщик не запущен или давно не завершал
+// цикл рассылки успешно. До первого цикла отсчет ведется от момента запуска.
+func (s *Scheduler) HealthCheck(ctx context.Context) error {
+	started := s.startedAt.Load()
+	if started == 0 {
+		return errors.New("планировщик не запущен")
+	}
+	last := s.lastCycleAt.Load()
+	if last == 0 {
+		last = started
+	}
+	// Допускаем пропуск цикла, пока предыдущий еще выполняется
+	limit := 2*s.options.Interval + s.options.CycleTimeout
+	if since := time.Since(time.Unix(0, last)); since > limit {
+		return fmt.Errorf("последний успешный цикл рассылки завершился %s назад (допустимо %s)", since.Round(time.Second), limit)
+	}
+	return nil
+}
+
+// bind возвращает контекст, который отменяется вместе с ctx или при остановке планировщика.
+func (s *Scheduler) bind(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(s.ctx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
 }
 
 // IsArticleSent проверяет, была ли статья уже отправлена пользователю.
 func (s *Scheduler) IsArticleSent(ctx context.Context, userID uint, articleURL string) (bool, error) {
-	return s.isArticleSent(ctx, userID, articleURL), nil
+	return s.isArticleSent(ctx, userID, fetcher.ArticleKey(articleURL)), nil
 }
 
-// isArticleSent проверяет, была ли статья уже отправлена по данной теме.
-func (s *Scheduler) isArticleSent(ctx context.Context, userID uint, articleURL string) bool {
-	// Генерируем хеш статьи из URL
-	articleHash := articleURL
-
+// isArticleSent проверяет по ключу статьи (fetcher.ArticleKey), была ли она уже отправлена.
+func (s *Scheduler) isArticleSent(ctx context.Context, userID uint, articleHash string) bool {
 	// Проверяем в базе данных, была ли статья отправлена
 	sent, err := s.sentArticleRepo.IsArticleSent(ctx, userID, articleHash)
 	if err != nil {
-		log.Printf("Ошибка при проверке статьи в БД: %v", err)
+		slog.Error("Ошибка при проверке статьи в БД", "error", err)
 		// В случае ошибки используем локальный кэш как запасной вариант
+		s.sentMu.Lock()
+		defer s.sentMu.Unlock()
 		topicKey := fmt.Sprintf("%d:%s", userID, articleHash)
 		if _, ok := s.sentArticles[topicKey]; !ok {
 			return false
 		}
-		return s.sentArticles[topicKey][articleURL]
+		return s.sentArticles[topicKey][articleHash]
 	}
 
 	return sent
@@ -101,15 +208,8 @@ func (s *Scheduler) isArticleSent(ctx context.Context, userID uint, articleURL s
 
 // MarkArticleAsSent помечает статью как отправленную для данного пользователя.
 func (s *Scheduler) MarkArticleAsSent(ctx context.Context, userID uint, articleURL string) error {
-	// Помечаем в БД
-	err := s.sentArticleRepo.MarkArticleAsSent(ctx, userID, articleURL)
-	if err != nil {
-		return err
-	}
-
-	// Помечаем в локальном кэше
-	s.markArticleAsSent(ctx, userID, articleURL)
-	return nil
+	// Одна и та же статья может прийти с разными адресами, поэтому храним ключ канонического адреса
+	return s.sentArticleRepo.MarkArticleAsSent(ctx, userID, fetcher.ArticleKey(articleURL))
 }
 
 // formatArticleMessage создает красиво отформатированное HTML-сообщение для новостной статьи
@@ -135,11 +235,13 @@ func (s *Scheduler) formatArticleMessage(article fetcher.Article) string {
 			"%s\n\n"+ // Описание
 			"<i>📰 Источник: %s</i>\n"+ // Источник курсивом
 			"<i>📅 Опубликовано: %s</i>\n\n"+ // Дата публикации курсивом
+			"%s"+ // Другие источники той же новости (вместе с пустой строкой) или пусто
 			"<a href=\"%s\">Читать полностью →</a>", // Ссылка на статью
 		article.Title,
 		description,
 		sourceName,
 		publishedDate,
+		alsoReportedBy(article),
 		article.URL,
 	)
 
@@ -155,22 +257,23 @@ func (s *Scheduler) ResetSentArticlesHistory(ctx context.Context, userID uint) e
 	}
 
 	// Сбрасываем локальный кэш
+	s.sentMu.Lock()
+	defer s.sentMu.Unlock()
 	userIDStr := fmt.Sprintf("%d", userID)
 	s.sentArticles[userIDStr] = make(map[string]bool)
 
 	return nil
 }
 
-// markArticleAsSent помечает статью как отправленную для данного пользователя.
-func (s *Scheduler) markArticleAsSent(ctx context.Context, userID uint, articleURL string) {
-	// Генерируем хеш статьи из URL
-	articleHash := articleURL
-
+// markArticleAsSent помечает статью с ключом articleHash (fetcher.ArticleKey) как отправленную.
+func (s *Scheduler) markArticleAsSent(ctx context.Context, userID uint, articleHash string) {
 	// Сохраняем в базе данных
 	err := s.sentArticleRepo.MarkArticleAsSent(ctx, userID, articleHash)
 	if err != nil {
-		log.Printf("Ошибка при сохранении статьи в БД: %v", err)
+		slog.Error("Ошибка при сохранении статьи в БД", "error", err)
 		// В случае ошибки используем локальный кэш как запасной вариант
+		s.sentMu.Lock()
+		defer s.sentMu.Unlock()
 		topicKey := fmt.Sprintf("%d:%s", userID, articleHash)
 		if _, ok := s.sentArticles[topicKey]; !ok {
 			s.sentArticles[topicKey] = make(map[string]bool)
@@ -183,51 +286,276 @@ func (s *Scheduler) markArticleAsSent(ctx context.Context, userID uint, articleU
 			s.sentArticles[topicKey] = make(map[string]bool)
 		}
 
-		s.sentArticles[topicKey][articleURL] = true
+		s.sentArticles[topicKey][articleHash] = true
 	}
 }
 
-// sendNewsUpdates выполняет основную логику: получает темы, запрашивает новости и отправляет их.
-func (s *Scheduler) sendNewsUpdates() {
-	ctx := context.Background()
-	log.Println("Планировщик: начинаю персональную проверку обновлений для пользователей...")
+// topicArticles хранит результаты запросов к провайдерам по темам за один цикл планировщика.
+// Заполняется горутиной, читающей пользователей, и читается обработчиками пула.
+type topicArticles struct {
+	mu       sync.RWMutex
+	articles map[string][]fetcher.Article
+	fetched  map[string]bool // Темы, которые уже запрашивались в этом цикле, включая неудачные
 
-	users, err := s.userRepo.GetAllUsers(ctx)
-	if err != nil {
-		log.Printf("Планировщик: не удалось получить список пользователей: %v", err)
+	// subscribers - число подписчиков каждой темы для меток метрик.
+	// Заполняется только горутиной, читающей пользователей.
+	subscribers map[string]int
+}
+
+func newTopicArticles() *topicArticles {
+	return &topicArticles{
+		articles:    make(map[string][]fetcher.Article),
+		fetched:     make(map[string]bool),
+		subscribers: make(map[string]int),
+	}
+}
+
+func (t *topicArticles) get(topic string) ([]fetcher.Article, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	articles, ok := t.articles[topic]
+	return articles, ok
+}
+
+// startCycle запускает цикл рассылки, если предыдущий цикл уже завершился.
+// Каждый цикл получает собственный контекст с ограничением по времени.
+func (s *Scheduler) startCycle() {
+	if !s.running.CompareAndSwap(false, true) {
+		slog.Info("Планировщик: предыдущий цикл еще выполняется, пропускаю запуск")
 		return
 	}
 
-	log.Printf("Планировщик: найдено %d пользователей для проверки.", len(users))
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.running.Store(false)
+
+		ctx, cancel := context.WithTimeout(s.ctx, s.options.CycleTimeout)
+		defer cancel()
+		s.sendNewsUpdates(ctx)
+	}()
+}
 
+// sendNewsUpdates выполняет основную логику: получает темы, запрашивает новости и отправляет их.
+// Пользователи читаются из БД пачками по курсору и передаются ограниченному пулу обработчиков.
+// Каждая уникальная тема запрашивается один раз за цикл, после чего результаты
+// распределяются между всеми подписчиками этой темы, которым пора отправлять новости.
+func (s *Scheduler) sendNewsUpdates(ctx context.Context) {
+	start := time.Now()
+	slog.Info("Планировщик: начинаю проверку обновлений по всем темам")
+
+	results := newTopicArticles()
+	jobs := make(chan userJob)
+	var sentCount atomic.Int64
 	var wg sync.WaitGroup
-	newsSentCount := 0
-	mu := &sync.Mutex{}
 
-	for _, user := range users {
+	for i := 0; i < s.options.Workers; i++ {
 		wg.Add(1)
-		go func(u database.User) {
+		go func() {
 			defer wg.Done()
-			foundNewsCount := s.ProcessUser(ctx, u, false) // Обычный запуск по расписанию
-			mu.Lock()
-			newsSentCount += foundNewsCount
-			mu.Unlock()
-		}(user)
+			for job := range jobs {
+				sentCount.Add(int64(s.processUser(ctx, job, false, results, start))) // Обычный запуск по расписанию
+			}
+		}()
 	}
+
+	dispatched, err := s.dispatchUsers(ctx, jobs, results, start)
+	close(jobs)
 	wg.Wait()
 
-	log.Println("Планировщик: проверка обновлений для всех пользователей завершена.")
+	if err != nil {
+		slog.Warn("Планировщик: цикл прерван", "error", err)
+	} else {
+		s.lastCycleAt.Store(time.Now().UnixNano())
+		// Популярность тем пересчитывается только по полному обходу пользователей
+		setLabeledTopics(results.subscribers)
+	}
+
+	results.mu.RLock()
+	fetchedTopics, failedTopics := len(results.fetched), len(results.fetched)-len(results.articles)
+	results.mu.RUnlock()
+
+	cycleDuration.Observe(time.Since(start).Seconds())
+	s.updateOutboxDepth(ctx)
+	slog.Info("Планировщик: проверка обновлений завершена", "duration", time.Since(start).Round(time.Millisecond),
+		"users", dispatched, "topics", fetchedTopics, "failed_topics", failedTopics, "sent", sentCount.Load())
+
+	if stats, ok := s.fetcher.CacheStats(); ok {
+		slog.Info("Планировщик: кэш новостей", "hits", stats.Hits+stats.StoreHits, "store_hits", stats.StoreHits,
+			"misses", stats.Misses, "entries", stats.Entries)
+	}
+	for _, provider := range s.fetcher.ProviderHealth() {
+		if provider.Breaker != fetcher.BreakerClosed || provider.Quota.Exhausted {
+			slog.Warn("Планировщик: провайдер недоступен", "provider", provider.Name, "breaker", provider.Breaker.String(),
+				"quota_used", provider.Quota.Used, "quota_limit", provider.Quota.Limit, "quota_exhausted", provider.Quota.Exhausted)
+		}
+	}
+}
+
+// userJob - пользователь, переданный в пул обработчиков, и его темы, для которых
+// на момент начала цикла наступило время проверки.
+type userJob struct {
+	user    database.User
+	due     []database.Subscription
+	userDue bool // Наступило ли время уведомления по общему интервалу или расписанию пользователя
 }
 
-// FetchNewsForTopic получает новости по конкретной теме.
+// dispatchUsers обходит пользователей пачками и передает в пул тех, кому пора отправлять
+// новости. Перед передачей пользователя запрашиваются еще не полученные темы его подписок,
+// для которых наступило время проверки. Время проверки определяется один раз по now,
+// и обработчики получают уже отобранные темы.
+// Возвращает количество переданных пользователей.
+func (s *Scheduler) dispatchUsers(ctx context.Context, jobs chan<- userJob, results *topicArticles, now time.Time) (int, error) {
+	var afterID uint
+	dispatched := 0
+
+	for {
+		users, err := s.userRepo.GetUsersBatch(ctx, afterID, s.options.BatchSize)
+		if err != nil {
+			return dispatched, fmt.Errorf("не удалось получить пользователей: %w", err)
+		}
+		if len(users) == 0 {
+			return dispatched, nil
+		}
+		afterID = users[len(users)-1].ID
+
+		userIDs := make([]uint, len(users))
+		for i, user := range users {
+			userIDs[i] = user.ID
+		}
+		subscriptions, err := s.subRepo.GetSubscriptionsForUsers(ctx, userIDs)
+		if err != nil {
+			return dispatched, fmt.Errorf("не удалось получить подписки: %w", err)
+		}
+		byUser := make(map[uint][]database.Subscription)
+		for _, sub := range subscriptions {
+			byUser[sub.UserID] = append(byUser[sub.UserID], sub)
+			results.subscribers[sub.Topic]++
+		}
+
+		for _, user := range users {
+			// Тема с собственным интервалом может быть готова к отправке раньше общего интервала
+			userDue := s.isUserDue(user, now)
+			due := dueSubscriptions(byUser[user.ID], false, userDue, now)
+			if len(due) == 0 {
+				continue
+			}
+
+			s.fetchTopics(ctx, due, results)
+
+			select {
+			case jobs <- userJob{user: user, due: due, userDue: userDue}:
+				dispatched++
+			case <-ctx.Done():
+				return dispatched, ctx.Err()
+			}
+		}
+
+		if len(users) < s.options.BatchSize {
+			return dispatched, nil
+		}
+	}
+}
+
+// fetchTopics запрашивает новости по темам подписок, которые еще не запрашивались в этом цикле.
+// Одна тема с разными языком или страной запрашивается отдельно.
+// Темы, по которым запрос завершился ошибкой, в результат не попадают.
+func (s *Scheduler) fetchTopics(ctx context.Context, subscriptions []database.Subscription, results *topicArticles) {
+	for _, sub := range subscriptions {
+		if ctx.Err() != nil {
+			return
+		}
+
+		req := subscriptionRequest(sub)
+		key := fetcher.RequestKey(req)
+
+		results.mu.RLock()
+		fetched := results.fetched[key]
+		results.mu.RUnlock()
+		if fetched {
+			continue
+		}
+
+		articles, err := s.fetcher.Search(ctx, req)
+		if ctx.Err() != nil {
+			// Цикл прерван: тема не считается запрошенной
+			return
+		}
+
+		results.mu.Lock()
+		results.fetched[key] = true
+		if err == nil {
+			results.articles[key] = articles
+		}
+		results.mu.Unlock()
+		articlesFetched.Add(float64(len(articles)), topicLabel(sub.Topic))
+		countNearDuplicates(sub.Topic, articles)
+
+		if err != nil {
+			logFetchError(sub.Topic, err)
+		}
+	}
+}
+
+// logFetchError пишет в лог ошибку получения новостей по теме с учетом ее категории.
+func logFetchError(topic string, err error) {
+	var quotaErr *fetcher.QuotaExhaustedError
+	switch {
+	case errors.As(err, &quotaErr):
+		slog.Info("Планировщик: тема пропущена - квота провайдеров исчерпана", "topic", topic, "reset_at", quotaErr.ResetAt.UTC())
+	case errors.Is(err, fetcher.ErrRateLimited):
+		slog.Warn("Планировщик: тема пропущена - провайдеры ограничили частоту запросов", "topic", topic, "error", err)
+	case errors.Is(err, fetcher.ErrBadQuery):
+		slog.Warn("Планировщик: провайдеры отклонили тему как некорректный запрос", "topic", topic, "error", err)
+	case errors.Is(err, fetcher.ErrUnauthorized):
+		slog.Error("Планировщик: провайдеры не приняли ключ API при запросе темы", "topic", topic, "error", err)
+	default:
+		slog.Warn("Планировщик: ошибка при получении новостей по теме", "topic", topic, "error", err)
+	}
+}
+
+// subscriptionRequest формирует запрос к провайдерам с учетом языка и страны подписки.
+func subscriptionRequest(sub database.Subscription) fetcher.SearchRequest {
+	return fetcher.SearchRequest{Query: sub.Topic, Language: sub.Language, Country: sub.Country}
+}
+
+// dueSubscriptions отбирает подписки, по которым пора искать новости. Темы с собственным
+// интервалом проверяются по нему, остальные - когда наступило время пользователя (userDue).
+// Выключенные темы пропускаются всегда, при force остальные темы отбираются без проверки времени.
+func dueSubscriptions(subscriptions []database.Subscription, force, userDue bool, now time.Time) []database.Subscription {
+	var due []database.Subscription
+	for _, sub := range subscriptions {
+		switch {
+		case sub.Muted:
+			continue
+		case force:
+		case sub.IntervalMinutes > 0:
+			interval := time.Duration(sub.IntervalMinutes) * time.Minute
+			if sub.LastNotifiedAt != nil && now.Sub(*sub.LastNotifiedAt) < interval {
+				continue
+			}
+		case !userDue:
+			continue
+		}
+		due = append(due, sub)
+	}
+	return due
+}
+
+// FetchNewsForTopic получает новости по конкретной теме. Запрос прерывается
+// отменой ctx или остановкой планировщика.
 func (s *Scheduler) FetchNewsForTopic(ctx context.Context, topic string) ([]fetcher.Article, error) {
-	return s.fetcher.FetchNews(topic)
+	ctx, cancel := s.bind(ctx)
+	defer cancel()
+	return s.fetcher.FetchNews(ctx, topic)
 }
 
 // SearchNews получает новости по произвольному поисковому запросу.
 func (s *Scheduler) SearchNews(ctx context.Context, query string) ([]fetcher.Article, error) {
+	ctx, cancel := s.bind(ctx)
+	defer cancel()
 	// Используем тот же метод FetchNews, что и для поиска по теме
-	return s.fetcher.FetchNews(query)
+	return s.fetcher.FetchNews(ctx, query)
 }
 
 // AddFavoriteArticle добавляет статью в избранное пользователя.
@@ -235,6 +563,7 @@ func (s *Scheduler) AddFavoriteArticle(ctx context.Context, userID uint, article
 	return s.favoriteArticleRepo.AddFavoriteArticle(
 		ctx,
 		userID,
+		fetcher.ArticleKey(article.URL),
 		article.URL,
 		article.Title,
 		article.Source.Name,
@@ -244,7 +573,7 @@ func (s *Scheduler) AddFavoriteArticle(ctx context.Context, userID uint, article
 
 // RemoveFavoriteArticle удаляет статью из избранного пользователя.
 func (s *Scheduler) RemoveFavoriteArticle(ctx context.Context, userID uint, articleURL string) error {
-	return s.favoriteArticleRepo.RemoveFavoriteArticle(ctx, userID, articleURL)
+	return s.favoriteArticleRepo.RemoveFavoriteArticle(ctx, userID, fetcher.ArticleKey(articleURL))
 }
 
 // GetUserFavoriteArticles возвращает список избранных статей пользователя.
@@ -254,7 +583,7 @@ func (s *Scheduler) GetUserFavoriteArticles(ctx context.Context, userID uint) ([
 
 // IsFavoriteArticle проверяет, добавлена ли статья в избранное пользователя.
 func (s *Scheduler) IsFavoriteArticle(ctx context.Context, userID uint, articleURL string) (bool, error) {
-	return s.favoriteArticleRepo.IsFavoriteArticle(ctx, userID, articleURL)
+	return s.favoriteArticleRepo.IsFavoriteArticle(ctx, userID, fetcher.ArticleKey(articleURL))
 }
 
 // sendArticleWithFavoriteButton отправляет новостную статью с кнопкой "В избранное"
@@ -265,7 +594,7 @@ func (s *Scheduler) sendArticleWithFavoriteButton(ctx context.Context, chatID in
 	// Проверяем, находится ли статья в избранном
 	isFavorite, err := s.IsFavoriteArticle(ctx, userID, article.URL)
 	if err != nil {
-		log.Printf("Ошибка проверки избранной статьи: %v", err)
+		slog.Error("Ошибка проверки избранной статьи", "error", err)
 		// Продолжаем выполнение, даже если произошла ошибка
 	}
 
@@ -297,8 +626,8 @@ func (s *Scheduler) sendArticleWithFavoriteButton(ctx context.Context, chatID in
 	msg.DisableWebPagePreview = false
 	msg.ReplyMarkup = keyboard
 
-	if _, err := s.bot.Send(msg); err != nil {
-		log.Printf("Ошибка отправки новости: %v", err)
+	if _, err := s.sender.Send(ctx, msg); err != nil {
+		slog.Warn("Ошибка отправки новости", "error", err)
 		return err
 	}
 
@@ -308,81 +637,217 @@ func (s *Scheduler) sendArticleWithFavoriteButton(ctx context.Context, chatID in
 // ProcessUser обрабатывает пользователя, отправляя ему новости по его подпискам.
 // Возвращает количество отправленных новостей.
 func (s *Scheduler) ProcessUser(ctx context.Context, user database.User, force bool) int {
+	ctx, cancel := s.bind(ctx)
+	defer cancel()
 	now := time.Now()
-	interval := time.Duration(user.NotificationIntervalMinutes) * time.Minute
 
-	// Проверяем, пора ли отправлять уведомление (если это не принудительный запуск)
-	if !force && user.LastNotifiedAt != nil && now.Sub(*user.LastNotifiedAt) < interval {
-		// Еще не время
+	subscriptions, err := s.subRepo.GetUserSubscriptionDetails(ctx, user.ID)
+	if err != nil {
+		slog.Error("Планировщик: не удалось получить подписки пользователя", "user_id", user.ID, "error", err)
 		return 0
 	}
 
-	log.Printf("Планировщик: обрабатываю пользователя ID %d (TelegramID: %d)", user.ID, user.TelegramID)
+	// Проверяем, пора ли отправлять уведомление (если это не принудительный запуск).
+	// Темы с собственным интервалом могут быть готовы раньше общего времени пользователя.
+	userDue := force || s.isUserDue(user, now)
+	job := userJob{user: user, due: dueSubscriptions(subscriptions, force, userDue, now), userDue: userDue}
+	return s.processUser(ctx, job, force, nil, now)
+}
 
-	topics, err := s.subRepo.GetUserSubscriptions(ctx, user.ID)
-	if err != nil {
-		log.Printf("Планировщик: не удалось получить подписки для пользователя ID %d: %v", user.ID, err)
+// processUser отправляет пользователю новости по темам job.due. Если prefetched
+// не nil, новости берутся из результатов текущего цикла, иначе запрашиваются заново.
+func (s *Scheduler) processUser(ctx context.Context, job userJob, force bool, prefetched *topicArticles, now time.Time) int {
+	user, due, userDue := job.user, job.due, job.userDue
+	if len(due) == 0 {
+		// Еще не время или нет активных подписок
 		return 0
 	}
 
-	if len(topics) == 0 {
-		// У пользователя нет подписок, нечего отправлять
+	slog.Debug("Планировщик: обрабатываю пользователя", "user_id", user.ID, "telegram_id", user.TelegramID, "topics", len(due))
+
+	fresh, processed := s.collectFreshArticles(ctx, user, due, prefetched, now)
+	freshByTopic := mergeNearDuplicates(fresh)
+	// Темы, новости по которым получить не удалось, проверяются снова в следующем цикле
+	s.markSubscriptionsProcessed(ctx, processed, now)
+
+	// В режиме сводки новости накапливаются и отправляются одним сообщением
+	if user.DeliveryMode == database.DeliveryModeDigest {
+		// Если ни одну тему получить не удалось, пользователь проверяется снова в следующем цикле
+		return s.processDigestUser(ctx, user, force, userDue && len(processed) > 0, freshByTopic, now)
+	}
+
+	if len(freshByTopic) == 0 {
+		slog.Debug("Планировщик: новых статей для пользователя не найдено", "user_id", user.ID)
+		// Обновляем время, чтобы не проверять его снова на каждой итерации до истечения интервала.
+		// Если ни одну тему получить не удалось, пользователь проверяется снова в следующем цикле
+		if userDue && len(processed) > 0 {
+			if err := s.userRepo.UpdateUserLastNotifiedAt(ctx, user.ID, now); err != nil {
+				slog.Error("Планировщик: не удалось обновить время последней проверки пользователя", "user_id", user.ID, "error", err)
+			}
+		}
 		return 0
 	}
 
-	var allFreshArticles []fetcher.Article
-	newsFilterThreshold := time.Hour * 24 * 183 // 183 дня (примерно полгода)
+	// Ставим новости в очередь с учетом ограничений пользователя и тем. Статьи сверх лимита
+	// не попадают в очередь и остаются ожидающими до следующего цикла.
+	selected := limitArticles(user, freshByTopic)
+
+	// В тихие часы новости откладываются до их окончания и уходят одной пачкой через диспетчер.
+	// Принудительный запуск пользователь вызывает сам, поэтому тихие часы не учитываются.
+	deliverAt, quiet := quietHoursEnd(user, now)
+	if force {
+		deliverAt, quiet = time.Time{}, false
+	}
+
+	queued := s.enqueueArticles(ctx, user, selected, deliverAt)
+
+	// Сразу доставляем сообщения пользователя; неудачные попытки повторит диспетчер
+	sentCount := 0
+	if quiet {
+		slog.Info("Планировщик: у пользователя тихие часы, доставка отложена", "user_id", user.ID, "deliver_at", deliverAt)
+	} else {
+		sentCount = s.deliverOutbox(ctx, user.ID)
+	}
 
-	for _, topic := range topics {
-		articles, err := s.fetcher.FetchNews(topic)
+	if queued > 0 && userDue {
+		// Обновляем время последней отправки: дальнейшая доставка гарантируется очередью
+		if err := s.userRepo.UpdateUserLastNotifiedAt(ctx, user.ID, now); err != nil {
+			slog.Error("Планировщик: не удалось обновить время последней отправки пользователя", "user_id", user.ID, "error", err)
+		}
+	}
+
+	pending := len(freshByTopic) - len(selected)
+	slog.Info("Планировщик: новости поставлены в очередь", "user_id", user.ID, "queued", queued, "delivered", sentCount, "pending", pending)
+
+	return sentCount
+}
+
+// topicArticle - свежая статья вместе с темой, по которой она найдена.
+type topicArticle struct {
+	topic       string
+	maxArticles uint // Собственный лимит темы; 0 - общий лимит пользователя
+	article     fetcher.Article
+	duplicates  []string // Ключи похожих статей из других источников, объединенных с этой
+}
+
+// collectFreshArticles собирает по подпискам пользователя статьи, которые еще не отправлялись,
+// не ждут доставки в очереди и не отложены для сводки. Вторым значением возвращаются
+// подписки, новости по которым удалось получить.
+func (s *Scheduler) collectFreshArticles(ctx context.Context, user database.User, subscriptions []database.Subscription, prefetched *topicArticles, now time.Time) ([]topicArticle, []database.Subscription) {
+	var fresh []topicArticle
+	var processed []database.Subscription
+	seen := make(map[string]bool) // Одна и та же статья может прийти по нескольким темам
+
+	for _, sub := range subscriptions {
+		articles, err := s.articlesForSubscription(ctx, sub, prefetched)
 		if err != nil {
-			log.Printf("Планировщик: ошибка при получении новостей для темы '%s': %v", topic, err)
+			logFetchError(sub.Topic, err)
 			continue
 		}
+		processed = append(processed, sub)
 
 		for _, article := range articles {
-			if now.Sub(article.PublishedAt) < newsFilterThreshold && !s.isArticleSent(ctx, user.ID, article.URL) {
-				allFreshArticles = append(allFreshArticles, article)
-				s.markArticleAsSent(ctx, user.ID, article.URL)
+			// Провайдеры возвращают одну и ту же новость с разными адресами,
+			// поэтому статьи сравниваются по ключу канонического адреса
+			key := fetcher.ArticleKey(article.URL)
+			if seen[key] {
+				continue
+			}
+			if now.Sub(article.PublishedAt) < freshArticleMaxAge &&
+				!s.isArticleSent(ctx, user.ID, key) &&
+				!s.isArticleQueued(ctx, user.ID, key) &&
+				!s.isArticleInDigest(ctx, user.ID, key) {
+				seen[key] = true
+				fresh = append(fresh, topicArticle{topic: sub.Topic, maxArticles: sub.MaxArticles, article: article})
 			}
 		}
 	}
+	return fresh, processed
+}
 
-	if len(allFreshArticles) == 0 {
-		log.Printf("Планировщик: для пользователя ID %d новых статей не найдено.", user.ID)
-		// Обновляем время, чтобы не проверять его снова на каждой итерации до истечения интервала
-		if err := s.userRepo.UpdateUserLastNotifiedAt(ctx, user.ID, now); err != nil {
-			log.Printf("Планировщик: не удалось обновить время последней проверки для пользователя ID %d: %v", user.ID, err)
-		}
-		return 0
+// isUserDue проверяет, наступило ли время уведомления пользователя по его расписанию
+// или интервалу, либо время его сводки.
+func (s *Scheduler) isUserDue(user database.User, now time.Time) bool {
+	if user.LastNotifiedAt == nil || !now.Before(nextDueTime(user, *user.LastNotifiedAt)) {
+		return true
 	}
+	return user.DeliveryMode == database.DeliveryModeDigest && isDigestDue(user, now)
+}
 
-	// Ограничиваем количество новостей по настройкам пользователя
-	newsLimit := int(user.NewsLimit)
-	if newsLimit <= 0 {
-		newsLimit = 5 // Значение по умолчанию, если вдруг в базе значение некорректное
+// nextDueTime вычисляет время следующего уведомления после last. Если у пользователя
+// задано расписание, оно вычисляется в его часовом поясе, иначе используется интервал.
+func nextDueTime(user database.User, last time.Time) time.Time {
+	if user.Schedule != "" {
+		schedule, err := scheduling.Parse(user.Schedule)
+		if err == nil {
+			if next := schedule.Next(last.In(userLocation(user))); !next.IsZero() {
+				return next
+			}
+		} else {
+			slog.Warn("Планировщик: некорректное расписание пользователя", "user_id", user.ID, "schedule", user.Schedule, "error", err)
+		}
 	}
+	return last.Add(time.Duration(user.NotificationIntervalMinutes) * time.Minute)
+}
 
-	// Отправляем новости с учетом ограничения
-	articlesToSend := allFreshArticles
-	if len(allFreshArticles) > newsLimit {
-		articlesToSend = allFreshArticles[:newsLimit]
+// limitArticles отбирает статьи для отправки: темы с собственным лимитом ограничиваются
+// им, остальные темы делят общий лимит пользователя.
+func limitArticles(user database.User, fresh []topicArticle) []topicArticle {
+	shared := userNewsLimit(user)
+	perTopic := make(map[string]uint)
+
+	var selected []topicArticle
+	for _, item := range fresh {
+		if item.maxArticles > 0 {
+			if perTopic[item.topic] < item.maxArticles {
+				perTopic[item.topic]++
+				selected = append(selected, item)
+			}
+			continue
+		}
+		if shared > 0 {
+			shared--
+			selected = append(selected, item)
+		}
 	}
+	return selected
+}
 
-	for _, article := range articlesToSend {
-		// Используем метод sendArticleWithFavoriteButton для отправки новостей с кнопкой "В избранное"
-		if err := s.sendArticleWithFavoriteButton(ctx, user.TelegramID, user.ID, article); err != nil {
-			log.Printf("Планировщик: не удалось отправить новость пользователю ID %d: %v", user.ID, err)
-			continue
+// markSubscriptionsProcessed фиксирует время проверки тем с собственным интервалом.
+func (s *Scheduler) markSubscriptionsProcessed(ctx context.Context, subscriptions []database.Subscription, now time.Time) {
+	var ids []uint
+	for _, sub := range subscriptions {
+		if sub.IntervalMinutes > 0 {
+			ids = append(ids, sub.ID)
 		}
 	}
+	if err := s.subRepo.UpdateSubscriptionsLastNotifiedAt(ctx, ids, now); err != nil {
+		slog.Error("Планировщик: не удалось обновить время проверки тем", "error", err)
+	}
+}
 
-	// Обновляем время последней отправки
-	if err := s.userRepo.UpdateUserLastNotifiedAt(ctx, user.ID, now); err != nil {
-		log.Printf("Планировщик: не удалось обновить время последней отправки для пользователя ID %d: %v", user.ID, err)
+// userNewsLimit возвращает количество новостей, которое пользователь получает за раз.
+func userNewsLimit(user database.User) int {
+	if user.NewsLimit == 0 {
+		return 5 // Значение по умолчанию, если вдруг в базе значение некорректное
 	}
+	return int(user.NewsLimit)
+}
 
-	log.Printf("Планировщик: успешно отправлено %d новостей пользователю ID %d.", len(allFreshArticles), user.ID)
+// articlesForSubscription возвращает новости по теме подписки из результатов цикла
+// или запрашивает их у провайдеров.
+func (s *Scheduler) articlesForSubscription(ctx context.Context, sub database.Subscription, prefetched *topicArticles) ([]fetcher.Article, error) {
+	req := subscriptionRequest(sub)
+	if prefetched == nil {
+		articles, err := s.fetcher.Search(ctx, req)
+		articlesFetched.Add(float64(len(articles)), topicLabel(sub.Topic))
+		countNearDuplicates(sub.Topic, articles)
+		return articles, err
+	}
 
-	return len(allFreshArticles)
+	articles, ok := prefetched.get(fetcher.RequestKey(req))
+	if !ok {
+		return nil, fmt.Errorf("новости по теме '%s' не были получены в этом цикле", sub.Topic)
+	}
+	return articles, nil
 }
@@ -0,0 +1,221 @@
+// Package scheduling разбирает пользовательские расписания уведомлений
+// в упрощенном формате cron и вычисляет время следующего срабатывания.
+package scheduling
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxExpressionLength - максимальная длина выражения расписания, которое хранится в БД.
+const MaxExpressionLength = 128
+
+// macros - сокращенные записи распространенных расписаний.
+var macros = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 9 * * *",
+	"@weekdays": "0 9 * * 1-5",
+	"@weekly":   "0 9 * * 1",
+}
+
+// field описывает допустимый диапазон одного поля выражения.
+type field struct {
+	name     string
+	min, max int
+}
+
+var fields = [5]field{
+	{name: "минуты", min: 0, max: 59},
+	{name: "часы", min: 0, max: 23},
+	{name: "день месяца", min: 1, max: 31},
+	{name: "месяц", min: 1, max: 12},
+	{name: "день недели", min: 0, max: 7}, // 0 и 7 - воскресенье
+}
+
+// Schedule - разобранное расписание. Каждое поле хранится как набор битов допустимых значений.
+type Schedule struct {
+	expr                     string
+	minute, hour, dom, month uint64
+	dow                      uint64
+	domAny, dowAny           bool // Поле задано как "*"
+}
+
+// Parse разбирает расписание из пяти полей: минуты, часы, день месяца, месяц и день недели.
+// Поддерживаются "*", списки через запятую, диапазоны "1-5" и шаг "*/15", а также
+// сокращения @hourly, @daily, @weekdays и @weekly. Например, "0 9,18 * * 1-5" -
+// по будням в 09:00 и 18:00.
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.Join(strings.Fields(strings.ToLower(expr)), " ")
+	if expr == "" {
+		return nil, fmt.Errorf("пустое расписание")
+	}
+	if len(expr) > MaxExpressionLength {
+		return nil, fmt.Errorf("расписание длиннее %d символов", MaxExpressionLength)
+	}
+
+	spec := expr
+	if strings.HasPrefix(expr, "@") {
+		macro, ok := macros[expr]
+		if !ok {
+			return nil, fmt.Errorf("неизвестное сокращение %s", expr)
+		}
+		spec = macro
+	}
+
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("ожидается 5 полей (минуты часы день месяц день_недели), получено %d", len(parts))
+	}
+
+	var sets [5]uint64
+	for i, part := range parts {
+		set, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, err
+		}
+		sets[i] = set
+	}
+
+	// Воскресенье можно указать как 0 или 7
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+
+	return &Schedule{
+		expr:   expr,
+		minute: sets[0],
+		hour:   sets[1],
+		dom:    sets[2],
+		month:  sets[3],
+		dow:    sets[4],
+		domAny: parts[2] == "*",
+		dowAny: parts[4] == "*",
+	}, nil
+}
+
+// String возвращает нормализованное выражение расписания.
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// Next возвращает первый момент строго после after, подходящий под расписание.
+// Время вычисляется в часовом поясе after. Если подходящего момента нет в течение
+// пяти лет (например, 31 февраля), возвращается нулевое время.
+func (s *Schedule) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchDay проверяет день месяца и день недели. Как и в cron, если оба поля
+// ограничены, достаточно совпадения любого из них.
+func (s *Schedule) matchDay(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+
+	switch {
+	case s.domAny && s.dowAny:
+		return true
+	case s.domAny:
+		return dowMatch
+	case s.dowAny:
+		return domMatch
+	default:
+		return domMatch || dowMatch
+	}
+}
+
+// parseField разбирает одно поле выражения в набор битов.
+func parseField(value string, f field) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(value, ",") {
+		if item == "" {
+			return 0, fmt.Errorf("пустое значение в поле «%s»", f.name)
+		}
+
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("некорректный шаг «%s» в поле «%s»", stepPart, f.name)
+			}
+			step = n
+		}
+
+		start, end := f.min, f.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			from, to, _ := strings.Cut(rangePart, "-")
+			var err error
+			if start, err = parseValue(from, f); err != nil {
+				return 0, err
+			}
+			if end, err = parseValue(to, f); err != nil {
+				return 0, err
+			}
+			if start > end {
+				return 0, fmt.Errorf("некорректный диапазон «%s» в поле «%s»", rangePart, f.name)
+			}
+		default:
+			n, err := parseValue(rangePart, f)
+			if err != nil {
+				return 0, err
+			}
+			start = n
+			if !hasStep {
+				end = n
+			}
+		}
+
+		for n := start; n <= end; n += step {
+			set |= 1 << uint(n)
+		}
+	}
+	return set, nil
+}
+
+// parseValue разбирает число и проверяет, что оно входит в диапазон поля.
+func parseValue(value string, f field) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("некорректное значение «%s» в поле «%s»", value, f.name)
+	}
+	if n < f.min || n > f.max {
+		return 0, fmt.Errorf("значение %d в поле «%s» вне диапазона %d-%d", n, f.name, f.min, f.max)
+	}
+	return n, nil
+}
+
+// ParseClock разбирает время суток в формате ЧЧ:ММ.
+func ParseClock(value string) (hour, minute int, err error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, 0, fmt.Errorf("некорректное время '%s', ожидается ЧЧ:ММ", value)
+	}
+	return t.Hour(), t.Minute(), nil
+}
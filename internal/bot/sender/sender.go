@@ -0,0 +1,266 @@
+package sender
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// API определяет минимальный набор методов Telegram Bot API, нужный отправителю.
+// Его реализует *tgbotapi.BotAPI.
+type API interface {
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
+}
+
+// Config задает лимиты отправки сообщений.
+type Config struct {
+	GlobalRate  float64 // Сообщений в секунду для всего бота
+	GlobalBurst int     // Сколько сообщений можно отправить подряд без ожидания
+	ChatRate    float64 // Сообщений в секунду в один чат
+	ChatBurst   int     // Допустимая короткая серия сообщений в один чат
+	MaxRetries  int     // Сколько раз повторять запрос после ответа 429
+}
+
+// DefaultConfig возвращает лимиты, рекомендованные документацией Telegram:
+// около 30 сообщений в секунду суммарно и около одного сообщения в секунду в чат.
+func DefaultConfig() Config {
+	return Config{
+		GlobalRate:  30,
+		GlobalBurst: 30,
+		ChatRate:    1,
+		ChatBurst:   3,
+		MaxRetries:  3,
+	}
+}
+
+// withDefaults заменяет некорректные лимиты значениями DefaultConfig.
+func (c Config) withDefaults() Config {
+	defaults := DefaultConfig()
+	if !(c.GlobalRate > 0) || math.IsInf(c.GlobalRate, 0) {
+		slog.Warn("Некорректный глобальный лимит отправки, используется значение по умолчанию", "rate", c.GlobalRate, "default", defaults.GlobalRate)
+		c.GlobalRate = defaults.GlobalRate
+	}
+	if c.GlobalBurst < 1 {
+		slog.Warn("Некорректная глобальная серия отправки, используется значение по умолчанию", "burst", c.GlobalBurst, "default", defaults.GlobalBurst)
+		c.GlobalBurst = defaults.GlobalBurst
+	}
+	if !(c.ChatRate > 0) || math.IsInf(c.ChatRate, 0) {
+		slog.Warn("Некорректный лимит отправки в чат, используется значение по умолчанию", "rate", c.ChatRate, "default", defaults.ChatRate)
+		c.ChatRate = defaults.ChatRate
+	}
+	if c.ChatBurst < 1 {
+		slog.Warn("Некорректная серия отправки в чат, используется значение по умолчанию", "burst", c.ChatBurst, "default", defaults.ChatBurst)
+		c.ChatBurst = defaults.ChatBurst
+	}
+	if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	}
+	return c
+}
+
+// maxIdleChats - после скольких чатов в памяти начинается очистка неактивных лимитеров.
+const maxIdleChats = 10000
+
+// Sender - общий компонент отправки сообщений для планировщика и обработчиков.
+// Вызовы Send и Request ставятся в очередь: каждый получает время отправки с учетом
+// глобального лимита и лимита чата и ждет его. Ответы 429 обрабатываются централизованно:
+// отправка приостанавливается на retry_after для всех ожидающих.
+type Sender struct {
+	api    API
+	config Config
+
+	mu          sync.Mutex
+	global      *bucket
+	chats       map[int64]*bucket
+	pausedUntil time.Time
+}
+
+// New создает отправителя с заданными лимитами. Непредставимые лимиты (нулевая или
+// отрицательная частота, серия меньше одного сообщения) заменяются значениями DefaultConfig.
+func New(api API, cfg Config) *Sender {
+	cfg = cfg.withDefaults()
+	return &Sender{
+		api:    api,
+		config: cfg,
+		global: newBucket(cfg.GlobalRate, cfg.GlobalBurst),
+		chats:  make(map[int64]*bucket),
+	}
+}
+
+// Send отправляет сообщение с соблюдением лимитов и возвращает отправленное сообщение.
+func (s *Sender) Send(ctx context.Context, c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	resp, err := s.Request(ctx, c)
+	if err != nil {
+		return tgbotapi.Message{}, err
+	}
+
+	var message tgbotapi.Message
+	if err := json.Unmarshal(resp.Result, &message); err != nil {
+		return tgbotapi.Message{}, fmt.Errorf("ошибка декодирования ответа Telegram: %w", err)
+	}
+	return message, nil
+}
+
+// Request выполняет запрос к Telegram с соблюдением лимитов.
+// При ответе 429 запрос повторяется после паузы retry_after.
+func (s *Sender) Request(ctx context.Context, c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	chatID, hasChat := chatIDOf(c)
+
+	for attempt := 0; ; attempt++ {
+		if err := s.wait(ctx, chatID, hasChat); err != nil {
+			return nil, err
+		}
+
+		resp, err := s.api.Request(c)
+		if err == nil {
+			return resp, nil
+		}
+
+		var tgErr *tgbotapi.Error
+		if !errors.As(err, &tgErr) || tgErr.RetryAfter <= 0 || attempt >= s.config.MaxRetries {
+			return resp, err
+		}
+
+		retryAfter := time.Duration(tgErr.RetryAfter) * time.Second
+		slog.Warn("Telegram ограничил частоту запросов", "pause", retryAfter, "attempt", attempt+1)
+		s.pause(retryAfter)
+	}
+}
+
+// wait резервирует время отправки и ждет его наступления. Если ожидание прервано
+// контекстом, занятое место освобождается для следующих запросов.
+func (s *Sender) wait(ctx context.Context, chatID int64, hasChat bool) error {
+	delay, chat := s.reserve(time.Now(), chatID, hasChat)
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		s.release(chat)
+		return ctx.Err()
+	}
+}
+
+// reserve вычисляет, через сколько можно выполнить запрос, и занимает это место в очереди.
+// Вторым значением возвращается лимитер чата, в котором занято место (nil для запросов без чата).
+func (s *Sender) reserve(now time.Time, chatID int64, hasChat bool) (time.Duration, *bucket) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	at := now
+	if s.pausedUntil.After(at) {
+		at = s.pausedUntil
+	}
+
+	var chat *bucket
+	if hasChat {
+		var ok bool
+		chat, ok = s.chats[chatID]
+		if !ok {
+			if len(s.chats) >= maxIdleChats {
+				s.dropIdleChats(now)
+			}
+			chat = newBucket(s.config.ChatRate, s.config.ChatBurst)
+			s.chats[chatID] = chat
+		}
+		at = chat.reserve(at)
+	}
+
+	at = s.global.reserve(at)
+	return at.Sub(now), chat
+}
+
+// release возвращает место в очереди, занятое запросом, который так и не был выполнен.
+func (s *Sender) release(chat *bucket) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if chat != nil {
+		chat.release()
+	}
+	s.global.release()
+}
+
+// pause приостанавливает все отправки на указанное время.
+func (s *Sender) pause(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(s.pausedUntil) {
+		s.pausedUntil = until
+	}
+}
+
+// dropIdleChats удаляет лимитеры чатов, которые уже полностью восстановились.
+func (s *Sender) dropIdleChats(now time.Time) {
+	for chatID, chat := range s.chats {
+		if !chat.tat.After(now) {
+			delete(s.chats, chatID)
+		}
+	}
+}
+
+// chatIDOf извлекает идентификатор чата из запроса, если он адресован конкретному чату.
+func chatIDOf(c tgbotapi.Chattable) (int64, bool) {
+	switch v := c.(type) {
+	case tgbotapi.MessageConfig:
+		return v.ChatID, true
+	case tgbotapi.PhotoConfig:
+		return v.ChatID, true
+	case tgbotapi.EditMessageTextConfig:
+		return v.ChatID, true
+	case tgbotapi.EditMessageReplyMarkupConfig:
+		return v.ChatID, true
+	case tgbotapi.DeleteMessageConfig:
+		return v.ChatID, true
+	default:
+		return 0, false
+	}
+}
+
+// bucket - лимитер по алгоритму GCRA (вариант token bucket), который выдает
+// время, когда очередной запрос укладывается в лимит.
+type bucket struct {
+	interval  time.Duration // Время восстановления одного токена
+	tolerance time.Duration // Допустимое опережение графика (размер серии)
+	tat       time.Time     // Теоретическое время прибытия следующего запроса
+}
+
+func newBucket(rate float64, burst int) *bucket {
+	interval := time.Duration(float64(time.Second) / rate)
+	return &bucket{
+		interval:  interval,
+		tolerance: time.Duration(burst-1) * interval,
+	}
+}
+
+// reserve возвращает самое раннее время не раньше at, когда запрос укладывается в лимит,
+// и учитывает этот запрос.
+func (b *bucket) reserve(at time.Time) time.Time {
+	if earliest := b.tat.Add(-b.tolerance); earliest.After(at) {
+		at = earliest
+	}
+	if b.tat.Before(at) {
+		b.tat = at
+	}
+	b.tat = b.tat.Add(b.interval)
+	return at
+}
+
+// release отменяет учет одного запроса.
+func (b *bucket) release() {
+	b.tat = b.tat.Add(-b.interval)
+}
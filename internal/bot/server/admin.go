@@ -0,0 +1,713 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/query"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/scheduling"
+)
+
+const (
+	adminDefaultLimit   = 50              // Размер страницы списков по умолчанию
+	adminMaxLimit       = 500             // Максимальный размер страницы
+	adminMaxBodySize    = 64 << 10        // Максимальный размер тела запроса
+	adminProcessTimeout = 2 * time.Minute // Сколько ждать внеочередной рассылки пользователю
+)
+
+// UserProcessor отправляет пользователю свежие новости. Его реализует планировщик.
+type UserProcessor interface {
+	ProcessUser(ctx context.Context, user database.User, force bool) int
+}
+
+// AdminConfig задает адрес и токен административного API.
+type AdminConfig struct {
+	Addr  string // Адрес, например "127.0.0.1:8081"
+	Token string // Статический токен, который клиенты передают в заголовке Authorization: Bearer
+}
+
+// AdminAPI - HTTP/JSON API для скриптов администраторов: пользователи, подписки,
+// очередь доставки и провайдеры новостей. Все запросы требуют токен.
+type AdminAPI struct {
+	config    AdminConfig
+	userRepo  database.UserRepository
+	subRepo   database.SubscriptionRepository
+	outbox    database.OutboxRepository
+	processor UserProcessor
+	registry  *fetcher.Registry
+	fetcher   *fetcher.Fetcher
+}
+
+// NewAdminAPI создает административное API поверх репозиториев, планировщика и реестра провайдеров.
+func NewAdminAPI(cfg AdminConfig, userRepo database.UserRepository, subRepo database.SubscriptionRepository, outbox database.OutboxRepository, processor UserProcessor, registry *fetcher.Registry, newsFetcher *fetcher.Fetcher) *AdminAPI {
+	return &AdminAPI{
+		config:    cfg,
+		userRepo:  userRepo,
+		subRepo:   subRepo,
+		outbox:    outbox,
+		processor: processor,
+		registry:  registry,
+		fetcher:   newsFetcher,
+	}
+}
+
+// Handler возвращает HTTP-обработчик API с проверкой токена.
+func (a *AdminAPI) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/users", a.listUsers)
+	mux.HandleFunc("GET /api/users/{telegram_id}", a.getUser)
+	mux.HandleFunc("PATCH /api/users/{telegram_id}/settings", a.updateUserSettings)
+	mux.HandleFunc("POST /api/users/{telegram_id}/process", a.processUser)
+	mux.HandleFunc("GET /api/users/{telegram_id}/subscriptions", a.listSubscriptions)
+	mux.HandleFunc("POST /api/users/{telegram_id}/subscriptions", a.addSubscription)
+	mux.HandleFunc("PATCH /api/users/{telegram_id}/subscriptions/{id}", a.updateSubscription)
+	mux.HandleFunc("DELETE /api/users/{telegram_id}/subscriptions/{id}", a.removeSubscription)
+	mux.HandleFunc("GET /api/outbox", a.listOutbox)
+	mux.HandleFunc("GET /api/providers", a.listProviders)
+	mux.HandleFunc("PATCH /api/providers/{name}", a.updateProvider)
+	return a.authenticate(mux)
+}
+
+// Start обслуживает запросы до отмены ctx.
+func (a *AdminAPI) Start(ctx context.Context) error {
+	if a.config.Token == "" {
+		return errors.New("не задан токен административного API")
+	}
+	return serve(ctx, "административного API", a.config.Addr, a.Handler())
+}
+
+// authenticate пропускает только запросы с верным токеном и пишет в лог изменяющие запросы.
+func (a *AdminAPI) authenticate(next http.Handler) http.Handler {
+	expected := []byte("Bearer " + a.config.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Пустой токен не открывает доступ, даже если API запущено без Start
+		if a.config.Token == "" || subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			writeError(w, http.StatusUnauthorized, "требуется токен администратора")
+			return
+		}
+		if r.Method != http.MethodGet {
+			slog.Info("Административное API: запрос", "method", r.Method, "path", r.URL.Path)
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, adminMaxBodySize)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// userView - пользователь в ответах API.
+type userView struct {
+	ID                          uint               `json:"id"`
+	TelegramID                  int64              `json:"telegram_id"`
+	Username                    string             `json:"username"`
+	FirstName                   string             `json:"first_name"`
+	LastName                    string             `json:"last_name"`
+	CreatedAt                   time.Time          `json:"created_at"`
+	LastNotifiedAt              *time.Time         `json:"last_notified_at,omitempty"`
+	NotificationIntervalMinutes uint               `json:"notification_interval_minutes"`
+	NewsLimit                   uint               `json:"news_limit"`
+	DeliveryMode                string             `json:"delivery_mode"`
+	DigestPeriod                string             `json:"digest_period"`
+	DigestTime                  string             `json:"digest_time"`
+	TimeZone                    string             `json:"time_zone"`
+	QuietHoursStart             string             `json:"quiet_hours_start"`
+	QuietHoursEnd               string             `json:"quiet_hours_end"`
+	Schedule                    string             `json:"schedule"`
+	Subscriptions               []subscriptionView `json:"subscriptions,omitempty"`
+}
+
+func newUserView(user *database.User) userView {
+	return userView{
+		ID:                          user.ID,
+		TelegramID:                  user.TelegramID,
+		Username:                    user.Username,
+		FirstName:                   user.FirstName,
+		LastName:                    user.LastName,
+		CreatedAt:                   user.CreatedAt,
+		LastNotifiedAt:              user.LastNotifiedAt,
+		NotificationIntervalMinutes: user.NotificationIntervalMinutes,
+		NewsLimit:                   user.NewsLimit,
+		DeliveryMode:                user.DeliveryMode,
+		DigestPeriod:                user.DigestPeriod,
+		DigestTime:                  user.DigestTime,
+		TimeZone:                    user.TimeZone,
+		QuietHoursStart:             user.QuietHoursStart,
+		QuietHoursEnd:               user.QuietHoursEnd,
+		Schedule:                    user.Schedule,
+	}
+}
+
+// subscriptionView - подписка в ответах API. Нулевые настройки означают общие настройки пользователя.
+type subscriptionView struct {
+	ID              uint   `json:"id"`
+	Topic           string `json:"topic"`
+	IntervalMinutes uint   `json:"interval_minutes"`
+	MaxArticles     uint   `json:"max_articles"`
+	Language        string `json:"language"`
+	Country         string `json:"country"`
+	Muted           bool   `json:"muted"`
+}
+
+func newSubscriptionViews(subscriptions []database.Subscription) []subscriptionView {
+	views := make([]subscriptionView, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		views = append(views, subscriptionView{
+			ID:              sub.ID,
+			Topic:           sub.Topic,
+			IntervalMinutes: sub.IntervalMinutes,
+			MaxArticles:     sub.MaxArticles,
+			Language:        sub.Language,
+			Country:         sub.Country,
+			Muted:           sub.Muted,
+		})
+	}
+	return views
+}
+
+// listUsers возвращает страницу пользователей. Параметры: q - поиск по имени,
+// username или Telegram ID; after_id - курсор из next_after_id; limit - размер страницы.
+func (a *AdminAPI) listUsers(w http.ResponseWriter, r *http.Request) {
+	limit, ok := queryLimit(w, r)
+	if !ok {
+		return
+	}
+	afterID, err := strconv.ParseUint(r.URL.Query().Get("after_id"), 10, 64)
+	if err != nil && r.URL.Query().Get("after_id") != "" {
+		writeError(w, http.StatusBadRequest, "некорректный after_id")
+		return
+	}
+
+	users, err := a.userRepo.SearchUsers(r.Context(), r.URL.Query().Get("q"), uint(afterID), limit)
+	if err != nil {
+		a.internalError(w, "Ошибка поиска пользователей", err)
+		return
+	}
+
+	response := struct {
+		Users       []userView `json:"users"`
+		NextAfterID uint       `json:"next_after_id,omitempty"` // Пусто на последней странице
+	}{Users: make([]userView, 0, len(users))}
+	for i := range users {
+		response.Users = append(response.Users, newUserView(&users[i]))
+	}
+	if len(users) == limit {
+		response.NextAfterID = users[len(users)-1].ID
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// getUser возвращает пользователя с настройками и подписками.
+func (a *AdminAPI) getUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := a.pathUser(w, r)
+	if !ok {
+		return
+	}
+	a.writeUser(r.Context(), w, user)
+}
+
+// writeUser отвечает пользователем вместе с его подписками.
+func (a *AdminAPI) writeUser(ctx context.Context, w http.ResponseWriter, user *database.User) {
+	subscriptions, err := a.subRepo.GetUserSubscriptionDetails(ctx, user.ID)
+	if err != nil {
+		a.internalError(w, "Ошибка получения подписок", err)
+		return
+	}
+	view := newUserView(user)
+	view.Subscriptions = newSubscriptionViews(subscriptions)
+	writeJSON(w, http.StatusOK, view)
+}
+
+// userSettingsPatch - изменяемые настройки пользователя. Отсутствующие поля не меняются.
+type userSettingsPatch struct {
+	NotificationIntervalMinutes *uint   `json:"notification_interval_minutes"`
+	NewsLimit                   *uint   `json:"news_limit"`
+	DeliveryMode                *string `json:"delivery_mode"`
+	DigestPeriod                *string `json:"digest_period"`
+	DigestTime                  *string `json:"digest_time"`
+	TimeZone                    *string `json:"time_zone"`
+	QuietHoursStart             *string `json:"quiet_hours_start"`
+	QuietHoursEnd               *string `json:"quiet_hours_end"`
+	Schedule                    *string `json:"schedule"`
+}
+
+// validate проверяет новые значения и приводит их к виду, в котором они хранятся.
+func (p *userSettingsPatch) validate(user *database.User) error {
+	if p.NotificationIntervalMinutes != nil && *p.NotificationIntervalMinutes == 0 {
+		return errors.New("notification_interval_minutes должен быть больше 0")
+	}
+	if p.NewsLimit != nil && (*p.NewsLimit == 0 || *p.NewsLimit > 50) {
+		return errors.New("news_limit должен быть от 1 до 50")
+	}
+	if p.DeliveryMode != nil && *p.DeliveryMode != database.DeliveryModeInstant && *p.DeliveryMode != database.DeliveryModeDigest {
+		return fmt.Errorf("delivery_mode должен быть %s или %s", database.DeliveryModeInstant, database.DeliveryModeDigest)
+	}
+	if p.DigestPeriod != nil {
+		if *p.DigestPeriod != database.DigestPeriodDaily && *p.DigestPeriod != database.DigestPeriodWeekly {
+			return fmt.Errorf("digest_period должен быть %s или %s", database.DigestPeriodDaily, database.DigestPeriodWeekly)
+		}
+		// Период сводки сохраняется вместе с режимом доставки
+		mode := user.DeliveryMode
+		if p.DeliveryMode != nil {
+			mode = *p.DeliveryMode
+		}
+		if mode != database.DeliveryModeDigest {
+			return fmt.Errorf("digest_period задается только в режиме %s", database.DeliveryModeDigest)
+		}
+		p.DeliveryMode = &mode
+	}
+	if p.DigestTime != nil {
+		if _, _, err := scheduling.ParseClock(*p.DigestTime); err != nil {
+			return fmt.Errorf("некорректный digest_time: %w", err)
+		}
+	}
+	if p.TimeZone != nil && *p.TimeZone != "" {
+		if _, err := time.LoadLocation(*p.TimeZone); err != nil {
+			return fmt.Errorf("неизвестный time_zone %q", *p.TimeZone)
+		}
+	}
+	if p.QuietHoursStart != nil || p.QuietHoursEnd != nil {
+		start, end := user.QuietHoursStart, user.QuietHoursEnd
+		if p.QuietHoursStart != nil {
+			start = *p.QuietHoursStart
+		}
+		if p.QuietHoursEnd != nil {
+			end = *p.QuietHoursEnd
+		}
+		if (start == "") != (end == "") {
+			return errors.New("quiet_hours_start и quiet_hours_end задаются вместе или оба пустые")
+		}
+		for _, clock := range []string{start, end} {
+			if _, _, err := scheduling.ParseClock(clock); clock != "" && err != nil {
+				return fmt.Errorf("некорректное время тихих часов: %w", err)
+			}
+		}
+		p.QuietHoursStart, p.QuietHoursEnd = &start, &end
+	}
+	if p.Schedule != nil && *p.Schedule != "" {
+		schedule, err := scheduling.Parse(*p.Schedule)
+		if err != nil {
+			return fmt.Errorf("некорректный schedule: %w", err)
+		}
+		normalized := schedule.String()
+		p.Schedule = &normalized
+	}
+	return nil
+}
+
+// updateUserSettings меняет настройки пользователя и возвращает его новое состояние.
+func (a *AdminAPI) updateUserSettings(w http.ResponseWriter, r *http.Request) {
+	user, ok := a.pathUser(w, r)
+	if !ok {
+		return
+	}
+	var patch userSettingsPatch
+	if !decodeJSON(w, r, &patch) {
+		return
+	}
+	if err := patch.validate(user); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	var updates []func() error
+	if patch.NotificationIntervalMinutes != nil {
+		updates = append(updates, func() error {
+			return a.userRepo.UpdateUserNotificationInterval(ctx, user.ID, *patch.NotificationIntervalMinutes)
+		})
+	}
+	if patch.NewsLimit != nil {
+		updates = append(updates, func() error { return a.userRepo.UpdateUserNewsLimit(ctx, user.ID, *patch.NewsLimit) })
+	}
+	if patch.DeliveryMode != nil {
+		period := user.DigestPeriod
+		if patch.DigestPeriod != nil {
+			period = *patch.DigestPeriod
+		}
+		updates = append(updates, func() error { return a.userRepo.UpdateUserDeliveryMode(ctx, user.ID, *patch.DeliveryMode, period) })
+	}
+	if patch.DigestTime != nil {
+		updates = append(updates, func() error { return a.userRepo.UpdateUserDigestTime(ctx, user.ID, *patch.DigestTime) })
+	}
+	if patch.TimeZone != nil {
+		updates = append(updates, func() error { return a.userRepo.UpdateUserTimeZone(ctx, user.ID, *patch.TimeZone) })
+	}
+	if patch.QuietHoursStart != nil {
+		updates = append(updates, func() error {
+			return a.userRepo.UpdateUserQuietHours(ctx, user.ID, *patch.QuietHoursStart, *patch.QuietHoursEnd)
+		})
+	}
+	if patch.Schedule != nil {
+		updates = append(updates, func() error { return a.userRepo.UpdateUserSchedule(ctx, user.ID, *patch.Schedule) })
+	}
+	for _, update := range updates {
+		if err := update(); err != nil {
+			a.internalError(w, "Ошибка обновления настроек пользователя", err)
+			return
+		}
+	}
+
+	if user, err := a.userRepo.GetUserByTelegramID(ctx, user.TelegramID); err != nil {
+		a.internalError(w, "Ошибка получения пользователя", err)
+	} else {
+		a.writeUser(ctx, w, user)
+	}
+}
+
+// processUser запускает внеочередную рассылку пользователю и возвращает число отправленных новостей.
+func (a *AdminAPI) processUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := a.pathUser(w, r)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), adminProcessTimeout)
+	defer cancel()
+
+	sent := a.processor.ProcessUser(ctx, *user, true)
+	writeJSON(w, http.StatusOK, map[string]int{"sent": sent})
+}
+
+// listSubscriptions возвращает подписки пользователя.
+func (a *AdminAPI) listSubscriptions(w http.ResponseWriter, r *http.Request) {
+	user, ok := a.pathUser(w, r)
+	if !ok {
+		return
+	}
+	subscriptions, err := a.subRepo.GetUserSubscriptionDetails(r.Context(), user.ID)
+	if err != nil {
+		a.internalError(w, "Ошибка получения подписок", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newSubscriptionViews(subscriptions))
+}
+
+// addSubscription подписывает пользователя на тему из тела {"topic": "..."}.
+func (a *AdminAPI) addSubscription(w http.ResponseWriter, r *http.Request) {
+	user, ok := a.pathUser(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Topic string `json:"topic"`
+	}
+	if !decodeJSON(w, r, &body) {
+		return
+	}
+	topic := strings.ToLower(strings.TrimSpace(body.Topic))
+	if topic == "" || len(topic) > database.MaxTopicLength {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("topic должен содержать от 1 до %d байт", database.MaxTopicLength))
+		return
+	}
+	if _, err := query.Parse(topic); err != nil {
+		writeError(w, http.StatusBadRequest, "не удалось разобрать topic: "+err.Error())
+		return
+	}
+
+	if err := a.subRepo.AddSubscription(r.Context(), user.ID, topic); err != nil {
+		if errors.Is(err, database.ErrSubscriptionExists) {
+			writeError(w, http.StatusConflict, fmt.Sprintf("пользователь уже подписан на %q", topic))
+			return
+		}
+		a.internalError(w, "Ошибка добавления подписки", err)
+		return
+	}
+	subscriptions, err := a.subRepo.GetUserSubscriptionDetails(r.Context(), user.ID)
+	if err != nil {
+		a.internalError(w, "Ошибка получения подписок", err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newSubscriptionViews(subscriptions))
+}
+
+// subscriptionPatch - изменяемые настройки подписки. Отсутствующие поля не меняются.
+type subscriptionPatch struct {
+	IntervalMinutes *uint   `json:"interval_minutes"`
+	MaxArticles     *uint   `json:"max_articles"`
+	Language        *string `json:"language"`
+	Country         *string `json:"country"`
+	Muted           *bool   `json:"muted"`
+}
+
+// updateSubscription меняет собственные настройки подписки.
+func (a *AdminAPI) updateSubscription(w http.ResponseWriter, r *http.Request) {
+	user, sub, ok := a.pathSubscription(w, r)
+	if !ok {
+		return
+	}
+	var patch subscriptionPatch
+	if !decodeJSON(w, r, &patch) {
+		return
+	}
+
+	settings := sub.SubscriptionSettings
+	if patch.IntervalMinutes != nil {
+		settings.IntervalMinutes = *patch.IntervalMinutes
+	}
+	if patch.MaxArticles != nil {
+		settings.MaxArticles = *patch.MaxArticles
+	}
+	if patch.Language != nil {
+		settings.Language = strings.ToLower(strings.TrimSpace(*patch.Language))
+	}
+	if patch.Country != nil {
+		settings.Country = strings.ToLower(strings.TrimSpace(*patch.Country))
+	}
+	if patch.Muted != nil {
+		settings.Muted = *patch.Muted
+	}
+	if len(settings.Language) > 8 || len(settings.Country) > 8 {
+		writeError(w, http.StatusBadRequest, "language и country - коды длиной не больше 8 символов")
+		return
+	}
+
+	if err := a.subRepo.UpdateSubscriptionSettings(r.Context(), user.ID, sub.ID, settings); err != nil {
+		a.internalError(w, "Ошибка обновления настроек подписки", err)
+		return
+	}
+	sub.SubscriptionSettings = settings
+	writeJSON(w, http.StatusOK, newSubscriptionViews([]database.Subscription{*sub})[0])
+}
+
+// removeSubscription удаляет подписку пользователя.
+func (a *AdminAPI) removeSubscription(w http.ResponseWriter, r *http.Request) {
+	user, sub, ok := a.pathSubscription(w, r)
+	if !ok {
+		return
+	}
+	if err := a.subRepo.RemoveSubscription(r.Context(), user.ID, sub.Topic); err != nil {
+		a.internalError(w, "Ошибка при удалении подписки", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// outboxView - сообщение очереди доставки в ответах API. Текст сообщения не возвращается.
+type outboxView struct {
+	ID            uint      `json:"id"`
+	UserID        uint      `json:"user_id"`
+	ChatID        int64     `json:"chat_id"`
+	Topic         string    `json:"topic"`
+	Status        string    `json:"status"`
+	Attempts      uint      `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	LastError     string    `json:"last_error,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// listOutbox возвращает сообщения очереди доставки. Параметры: status - pending или failed,
+// telegram_id - сообщения одного пользователя, limit - размер выборки.
+func (a *AdminAPI) listOutbox(w http.ResponseWriter, r *http.Request) {
+	limit, ok := queryLimit(w, r)
+	if !ok {
+		return
+	}
+	filter := database.OutboxFilter{Status: r.URL.Query().Get("status")}
+	if filter.Status != "" && filter.Status != database.OutboxStatusPending && filter.Status != database.OutboxStatusFailed {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("status должен быть %s или %s", database.OutboxStatusPending, database.OutboxStatusFailed))
+		return
+	}
+	if value := r.URL.Query().Get("telegram_id"); value != "" {
+		user, ok := a.findUser(r.Context(), w, value)
+		if !ok {
+			return
+		}
+		filter.UserID = user.ID
+	}
+
+	pending, err := a.outbox.CountPendingOutboxMessages(r.Context())
+	if err != nil {
+		a.internalError(w, "Ошибка подсчета очереди доставки", err)
+		return
+	}
+	messages, err := a.outbox.ListOutboxMessages(r.Context(), filter, limit)
+	if err != nil {
+		a.internalError(w, "Ошибка получения очереди доставки", err)
+		return
+	}
+
+	response := struct {
+		Pending  int64        `json:"pending"`
+		Messages []outboxView `json:"messages"`
+	}{Pending: pending, Messages: make([]outboxView, 0, len(messages))}
+	for _, msg := range messages {
+		response.Messages = append(response.Messages, outboxView{
+			ID:            msg.ID,
+			UserID:        msg.UserID,
+			ChatID:        msg.ChatID,
+			Topic:         msg.Topic,
+			Status:        msg.Status,
+			Attempts:      msg.Attempts,
+			NextAttemptAt: msg.NextAttemptAt,
+			LastError:     msg.LastError,
+			CreatedAt:     msg.CreatedAt,
+		})
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// providerView - провайдер новостей в ответах API. Выключатель и квота заполняются
+// только для включенных провайдеров.
+type providerView struct {
+	Name    string     `json:"name"`
+	Enabled bool       `json:"enabled"`
+	Breaker string     `json:"breaker,omitempty"`
+	Quota   *quotaView `json:"quota,omitempty"`
+}
+
+// quotaView - расход дневной квоты провайдера.
+type quotaView struct {
+	Used      int       `json:"used"`
+	Limit     int       `json:"limit"` // 0 - без ограничения
+	Exhausted bool      `json:"exhausted"`
+	ResetAt   time.Time `json:"reset_at"`
+}
+
+// providers возвращает провайдеров в порядке цепочки.
+func (a *AdminAPI) providers() []providerView {
+	health := make(map[string]fetcher.ProviderHealth)
+	for _, item := range a.fetcher.ProviderHealth() {
+		health[item.Name] = item
+	}
+
+	names := a.registry.Names()
+	views := make([]providerView, 0, len(names))
+	for _, name := range names {
+		view := providerView{Name: name, Enabled: a.registry.IsEnabled(name)}
+		if item, ok := health[name]; ok {
+			view.Breaker = item.Breaker.String()
+			if quota := item.Quota; quota.Limit > 0 || quota.Used > 0 || quota.Exhausted {
+				view.Quota = &quotaView{Used: quota.Used, Limit: quota.Limit, Exhausted: quota.Exhausted, ResetAt: quota.ResetAt}
+			}
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+// listProviders возвращает провайдеров новостей и их состояние.
+func (a *AdminAPI) listProviders(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.providers())
+}
+
+// updateProvider включает или отключает провайдера: тело {"enabled": false}.
+// Изменение действует до перезапуска, затем снова применяется NEWS_PROVIDERS.
+func (a *AdminAPI) updateProvider(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if !decodeJSON(w, r, &body) {
+		return
+	}
+	if body.Enabled == nil {
+		writeError(w, http.StatusBadRequest, "укажите enabled")
+		return
+	}
+
+	name := r.PathValue("name")
+	if err := a.registry.SetEnabled(name, *body.Enabled); err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	slog.Info("Административное API: провайдер переключен", "provider", name, "enabled", *body.Enabled)
+
+	for _, view := range a.providers() {
+		if view.Name == strings.ToLower(strings.TrimSpace(name)) {
+			writeJSON(w, http.StatusOK, view)
+			return
+		}
+	}
+	writeJSON(w, http.StatusOK, providerView{Name: name, Enabled: *body.Enabled})
+}
+
+// pathUser находит пользователя по {telegram_id} из пути. Если пользователя нет,
+// отвечает ошибкой и возвращает false.
+func (a *AdminAPI) pathUser(w http.ResponseWriter, r *http.Request) (*database.User, bool) {
+	return a.findUser(r.Context(), w, r.PathValue("telegram_id"))
+}
+
+// findUser находит пользователя по Telegram ID в строке value.
+func (a *AdminAPI) findUser(ctx context.Context, w http.ResponseWriter, value string) (*database.User, bool) {
+	telegramID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("некорректный Telegram ID %q", value))
+		return nil, false
+	}
+	user, err := a.userRepo.GetUserByTelegramID(ctx, telegramID)
+	if errors.Is(err, database.ErrNotFound) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("пользователь %d не найден", telegramID))
+		return nil, false
+	}
+	if err != nil {
+		a.internalError(w, "Ошибка получения пользователя", err)
+		return nil, false
+	}
+	return user, true
+}
+
+// pathSubscription находит пользователя и его подписку {id} из пути.
+func (a *AdminAPI) pathSubscription(w http.ResponseWriter, r *http.Request) (*database.User, *database.Subscription, bool) {
+	user, ok := a.pathUser(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "некорректный ID подписки")
+		return nil, nil, false
+	}
+	sub, err := a.subRepo.GetSubscription(r.Context(), user.ID, uint(id))
+	if errors.Is(err, database.ErrNotFound) {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("подписка %d не найдена", id))
+		return nil, nil, false
+	}
+	if err != nil {
+		a.internalError(w, "Ошибка получения подписки", err)
+		return nil, nil, false
+	}
+	return user, sub, true
+}
+
+// internalError пишет ошибку в лог и отвечает 500 без подробностей.
+func (a *AdminAPI) internalError(w http.ResponseWriter, msg string, err error) {
+	slog.Error("Административное API: "+msg, "error", err)
+	writeError(w, http.StatusInternalServerError, "внутренняя ошибка")
+}
+
+// queryLimit читает параметр limit. При некорректном значении отвечает 400 и возвращает false.
+func queryLimit(w http.ResponseWriter, r *http.Request) (int, bool) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return adminDefaultLimit, true
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > adminMaxLimit {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("limit должен быть от 1 до %d", adminMaxLimit))
+		return 0, false
+	}
+	return limit, true
+}
+
+// decodeJSON разбирает тело запроса. Неизвестные поля считаются ошибкой, чтобы опечатка
+// в названии настройки не проходила молча.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst any) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		writeError(w, http.StatusBadRequest, "некорректный JSON: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
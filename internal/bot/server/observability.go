@@ -0,0 +1,167 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/pkg/metrics"
+)
+
+// checkTimeout ограничивает время одной проверки готовности.
+const checkTimeout = 3 * time.Second
+
+// CheckFunc - проверка зависимости бота. Возвращает ошибку, если зависимость недоступна.
+type CheckFunc func(ctx context.Context) error
+
+type readinessCheck struct {
+	name  string
+	check CheckFunc
+}
+
+// Observability - отдельный HTTP-сервер для метрик и проверок состояния. Работает
+// в обоих режимах бота и не зависит от вебхук-сервера, поэтому его порт можно
+// не публиковать наружу.
+type Observability struct {
+	addr     string
+	registry *metrics.Registry
+	checks   []readinessCheck
+}
+
+// NewObservability создает сервер наблюдаемости на адресе addr, например ":9090".
+func NewObservability(addr string, registry *metrics.Registry) *Observability {
+	return &Observability{addr: addr, registry: registry}
+}
+
+// AddCheck добавляет проверку готовности. Вызывается до Start.
+func (o *Observability) AddCheck(name string, check CheckFunc) {
+	o.checks = append(o.checks, readinessCheck{name: name, check: check})
+}
+
+// Handler возвращает HTTP-обработчик с эндпоинтами /metrics, /livez и /readyz.
+func (o *Observability) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", o.registry.Handler())
+	// Процесс жив, пока отвечает на запросы; зависимости проверяет /readyz
+	mux.HandleFunc("GET /livez", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, HealthReport{Status: StatusOK, Checks: []CheckResult{}})
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		report := o.Ready(r.Context())
+		status := http.StatusOK
+		if report.Status != StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+		writeJSON(w, status, report)
+	})
+	return mux
+}
+
+// Статусы проверок.
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// HealthReport - ответ /livez и /readyz.
+type HealthReport struct {
+	Status string        `json:"status"`
+	Checks []CheckResult `json:"checks"`
+}
+
+// CheckResult - результат одной проверки готовности.
+type CheckResult struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Error      string `json:"error,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// Ready выполняет проверки готовности параллельно и возвращает отчет в порядке их добавления.
+// Бот готов, если прошли все проверки.
+func (o *Observability) Ready(ctx context.Context) HealthReport {
+	report := HealthReport{Status: StatusOK, Checks: make([]CheckResult, len(o.checks))}
+
+	var wg sync.WaitGroup
+	for i, c := range o.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := c.check(checkCtx)
+			result := CheckResult{Name: c.name, Status: StatusOK, DurationMS: time.Since(start).Milliseconds()}
+			if err != nil {
+				result.Status, result.Error = StatusFail, err.Error()
+			}
+			report.Checks[i] = result
+		}()
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status != StatusOK {
+			report.Status = StatusFail
+			break
+		}
+	}
+	return report
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		slog.Error("Ошибка при записи ответа", "error", err)
+	}
+}
+
+// Start обслуживает запросы до отмены ctx. Ошибка возвращается, если адрес
+// не удалось занять или сервер остановился сам.
+func (o *Observability) Start(ctx context.Context) error {
+	return serve(ctx, "сервера наблюдаемости", o.addr, o.Handler())
+}
+
+// serve обслуживает запросы handler на адресе addr до отмены ctx и затем корректно
+// останавливает сервер. name - название сервера в родительном падеже для логов и ошибок.
+func serve(ctx context.Context, name, addr string, handler http.Handler) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть адрес %s %s: %w", name, addr, err)
+	}
+
+	srv := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("Запуск "+name, "addr", listener.Addr().String())
+		serveErr <- srv.Serve(listener)
+	}()
+
+	select {
+	case <-ctx.Done():
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("ошибка %s: %w", name, err)
+		}
+		return nil
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Ошибка при завершении работы "+name, "error", err)
+	}
+	return nil
+}
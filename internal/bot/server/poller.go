@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/handlers"
+)
+
+const (
+	pollTimeout    = 60 * time.Second // Сколько Telegram держит запрос getUpdates без новых обновлений
+	pollRetryDelay = 3 * time.Second  // Пауза после неудачного запроса
+	// pollStaleAfter - после какого времени без успешного getUpdates long polling считается неработающим
+	pollStaleAfter = 2*pollTimeout + time.Minute
+)
+
+// Poller получает обновления через long polling. В отличие от GetUpdatesChan из tgbotapi
+// он запоминает время последнего успешного запроса, по которому проверяется готовность бота.
+type Poller struct {
+	bot         *tgbotapi.BotAPI
+	handler     *handlers.Handler
+	startedAt   atomic.Int64 // Unix-наносекунды
+	lastSuccess atomic.Int64 // Unix-наносекунды
+}
+
+// NewPoller создает обработчик long polling.
+func NewPoller(bot *tgbotapi.BotAPI, handler *handlers.Handler) *Poller {
+	return &Poller{bot: bot, handler: handler}
+}
+
+type pollResult struct {
+	updates []tgbotapi.Update
+	err     error
+}
+
+// Run получает обновления до отмены ctx и дожидается завершения обработки уже
+// полученных обновлений. Запрос getUpdates, начатый до отмены, не дожидается:
+// его обновления не подтверждены и придут снова после перезапуска.
+func (p *Poller) Run(ctx context.Context) {
+	p.startedAt.Store(time.Now().UnixNano())
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	config := tgbotapi.UpdateConfig{Timeout: int(pollTimeout / time.Second)}
+	for {
+		results := make(chan pollResult, 1)
+		go func(config tgbotapi.UpdateConfig) {
+			updates, err := p.bot.GetUpdates(config)
+			results <- pollResult{updates: updates, err: err}
+		}(config)
+
+		var result pollResult
+		select {
+		case <-ctx.Done():
+			slog.Info("Получен сигнал завершения, останавливаем получение обновлений")
+			return
+		case result = <-results:
+		}
+
+		if result.err != nil {
+			slog.Warn("Не удалось получить обновления, повтор после паузы", "pause", pollRetryDelay, "error", result.err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(pollRetryDelay):
+			}
+			continue
+		}
+		p.lastSuccess.Store(time.Now().UnixNano())
+
+		for _, update := range result.updates {
+			if update.UpdateID >= config.Offset {
+				config.Offset = update.UpdateID + 1
+			}
+			wg.Add(1)
+			go func(u tgbotapi.Update) {
+				defer wg.Done()
+				p.handler.HandleUpdate(u)
+			}(update)
+		}
+	}
+}
+
+// HealthCheck возвращает ошибку, если long polling не запущен или Telegram давно
+// не отвечал на getUpdates. Текст ошибки запроса не возвращается: в нем адрес с токеном бота.
+func (p *Poller) HealthCheck(ctx context.Context) error {
+	started := p.startedAt.Load()
+	if started == 0 {
+		return errors.New("long polling не запущен")
+	}
+	last := p.lastSuccess.Load()
+	if last == 0 {
+		last = started
+	}
+	if since := time.Since(time.Unix(0, last)); since > pollStaleAfter {
+		return fmt.Errorf("нет успешных запросов getUpdates %s", since.Round(time.Second))
+	}
+	return nil
+}
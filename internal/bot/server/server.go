@@ -2,23 +2,33 @@ package server
 
 import (
 	"context"
+	"crypto/subtle"
 	"crypto/tls"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/handlers"
 )
 
+// secretTokenHeader - заголовок, в котором Telegram передает секрет, указанный при установке вебхука.
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
 // Server представляет HTTP-сервер для обработки вебхуков
 type Server struct {
 	bot     *tgbotapi.BotAPI
 	handler *handlers.Handler
 	config  Config
 	server  *http.Server
+	updates sync.WaitGroup // Обновления, обработка которых еще не завершена
+	serving atomic.Bool    // Принимает ли сервер запросы от Telegram
 }
 
 // Config содержит конфигурацию сервера
@@ -27,6 +37,7 @@ type Config struct {
 	WebhookURL  string
 	TLSCertPath string
 	TLSKeyPath  string
+	SecretToken string // Секрет, который Telegram присылает в заголовке каждого запроса
 }
 
 // New создает новый экземпляр сервера
@@ -38,116 +49,105 @@ func New(bot *tgbotapi.BotAPI, handler *handlers.Handler, cfg Config) *Server {
 	}
 }
 
-// Start запускает сервер
+// Start устанавливает вебхук и обслуживает запросы до отмены ctx или ошибки HTTP-сервера.
+// При завершении сервер дожидается обработки принятых обновлений и удаляет вебхук.
 func (s *Server) Start(ctx context.Context) error {
+	// Занимаем порт до установки вебхука: если он занят, Telegram не получит адрес,
+	// на который некому отвечать
+	listener, err := net.Listen("tcp", ":"+s.config.Port)
+	if err != nil {
+		return fmt.Errorf("ошибка вебхук-сервера: %w", err)
+	}
+
 	// Удаляем предыдущий вебхук, если он был
 	if _, err := s.bot.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
-		log.Printf("Не удалось удалить предыдущий вебхук: %v", err)
+		slog.Warn("Не удалось удалить предыдущий вебхук", "error", err)
 	}
 
 	// Настраиваем вебхук
 	if err := s.setupWebhook(); err != nil {
+		listener.Close()
 		return fmt.Errorf("ошибка настройки вебхука: %w", err)
 	}
 
 	// Настраиваем HTTP-сервер
-	s.setupHTTPServer()
+	s.server = &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
 
 	// Запускаем сервер в отдельной горутине
+	serveErr := make(chan error, 1)
 	go func() {
-		log.Printf("Запуск вебхук-сервера на порту %s...", s.config.Port)
+		slog.Info("Запуск вебхук-сервера", "port", s.config.Port)
+		s.serving.Store(true)
+		defer s.serving.Store(false)
 
 		var err error
 		if s.config.TLSCertPath != "" && s.config.TLSKeyPath != "" {
-			err = s.server.ListenAndServeTLS(s.config.TLSCertPath, s.config.TLSKeyPath)
+			err = s.server.ServeTLS(listener, s.config.TLSCertPath, s.config.TLSKeyPath)
 		} else {
-			err = s.server.ListenAndServe()
+			err = s.server.Serve(listener)
 		}
 
 		if err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Ошибка вебхук-сервера: %v", err)
+			serveErr <- err
 		}
+		close(serveErr)
 	}()
 
-	// Ожидаем сигнала завершения
-	<-ctx.Done()
-	log.Println("Получен сигнал завершения, останавливаем сервер...")
+	// Ожидаем сигнала завершения или ошибки сервера
+	var runErr error
+	select {
+	case <-ctx.Done():
+		slog.Info("Получен сигнал завершения, останавливаем сервер")
+	case err := <-serveErr:
+		runErr = fmt.Errorf("ошибка вебхук-сервера: %w", err)
+	}
 
 	// Плавно завершаем работу сервера
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := s.server.Shutdown(shutdownCtx); err != nil {
-		log.Printf("Ошибка при завершении работы сервера: %v", err)
+		slog.Error("Ошибка при завершении работы сервера", "error", err)
 	}
+	s.waitUpdates(shutdownCtx)
 
 	// Удаляем вебхук при завершении
 	if _, err := s.bot.Request(tgbotapi.DeleteWebhookConfig{}); err != nil {
-		log.Printf("Ошибка при удалении вебхука: %v", err)
-	}
-
-	return nil
-}
-
-func (s *Server) setupWebhook() error {
-	// Создаем URL для вебхука
-	webhookURL := fmt.Sprintf("%s/%s", strings.TrimSuffix(s.config.WebhookURL, "/"), s.bot.Token)
-
-	// Настраиваем вебхук в зависимости от наличия сертификата
-	if s.config.TLSCertPath != "" && s.config.TLSKeyPath != "" {
-		// Проверяем сертификат
-		if _, certErr := tls.LoadX509KeyPair(s.config.TLSCertPath, s.config.TLSKeyPath); certErr != nil {
-			return fmt.Errorf("ошибка загрузки сертификата: %v", certErr)
-		}
-
-		// Устанавливаем вебхук с сертификатом
-		webhookCfg, err := tgbotapi.NewWebhookWithCert(webhookURL, tgbotapi.FilePath(s.config.TLSCertPath))
-		if err != nil {
-			return fmt.Errorf("ошибка при создании конфигурации вебхука с сертификатом: %v", err)
-		}
-		if _, reqErr := s.bot.Request(webhookCfg); reqErr != nil {
-			return fmt.Errorf("ошибка при установке вебхука с сертификатом: %v", reqErr)
-		}
-		log.Printf("Используется TLS сертификат: %s", s.config.TLSCertPath)
-	} else {
-		// Устанавливаем вебхук без сертификата (для локальной разработки)
-		webhookCfg, err := tgbotapi.NewWebhook(webhookURL)
-		if err != nil {
-			return fmt.Errorf("ошибка при создании конфигурации вебхука: %v", err)
-		}
-		if _, reqErr := s.bot.Request(webhookCfg); reqErr != nil {
-			return fmt.Errorf("ошибка при установке вебхука: %v", reqErr)
-		}
+		slog.Error("Ошибка при удалении вебхука", "error", err)
 	}
 
-	// Получаем информацию о вебхуке
-	info, err := s.bot.GetWebhookInfo()
-	if err != nil {
-		log.Printf("Ошибка при получении информации о вебхуке: %v", err)
-	} else {
-		log.Printf("Вебхук установлен: %+v", info)
-	}
-
-	return nil
+	return runErr
 }
 
-func (s *Server) setupHTTPServer() {
+// Handler возвращает HTTP-обработчик с эндпоинтом вебхука и проверкой работоспособности.
+func (s *Server) Handler() http.Handler {
 	// Создаем маршрутизатор
 	mux := http.NewServeMux()
 
 	// Обработчик обновлений от Telegram
 	mux.HandleFunc("/"+s.bot.Token, func(w http.ResponseWriter, r *http.Request) {
+		if !s.validSecretToken(r) {
+			slog.Warn("Отклонен запрос к вебхуку с неверным секретом", "remote_addr", r.RemoteAddr)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
 		update, err := s.bot.HandleUpdate(r)
 		if err != nil {
-			log.Printf("Ошибка при обработке обновления: %v", err)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			slog.Warn("Ошибка при разборе обновления", "error", err)
+			http.Error(w, "Bad Request", http.StatusBadRequest)
 			return
 		}
 
+		s.updates.Add(1)
 		go func(u tgbotapi.Update) {
+			defer s.updates.Done()
 			defer func() {
 				if r := recover(); r != nil {
-					log.Printf("Паника при обработке обновления: %v", r)
+					slog.Error("Паника при обработке обновления", "update_id", u.UpdateID, "panic", r)
 				}
 			}()
 
@@ -162,12 +162,80 @@ func (s *Server) setupHTTPServer() {
 		w.WriteHeader(http.StatusOK)
 		_, err := w.Write([]byte("OK"))
 		if err != nil {
-			log.Printf("Ошибка при записи ответа: %v", err)
+			slog.Error("Ошибка при записи ответа", "error", err)
 		}
 	})
 
-	s.server = &http.Server{
-		Addr:    ":" + s.config.Port,
-		Handler: mux,
+	return mux
+}
+
+// HealthCheck возвращает ошибку, если вебхук-сервер не принимает запросы.
+func (s *Server) HealthCheck(ctx context.Context) error {
+	if !s.serving.Load() {
+		return errors.New("вебхук-сервер не принимает запросы")
+	}
+	return nil
+}
+
+// validSecretToken проверяет заголовок с секретом вебхука. Если секрет не задан, проверка не выполняется.
+func (s *Server) validSecretToken(r *http.Request) bool {
+	if s.config.SecretToken == "" {
+		return true
+	}
+	got := r.Header.Get(secretTokenHeader)
+	return subtle.ConstantTimeCompare([]byte(got), []byte(s.config.SecretToken)) == 1
+}
+
+// waitUpdates ждет завершения обработки принятых обновлений, но не дольше ctx.
+func (s *Server) waitUpdates(ctx context.Context) {
+	done := make(chan struct{})
+	go func() {
+		s.updates.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		slog.Warn("Не дождались завершения обработки всех обновлений")
 	}
 }
+
+func (s *Server) setupWebhook() error {
+	// Создаем URL для вебхука
+	webhookURL := fmt.Sprintf("%s/%s", strings.TrimSuffix(s.config.WebhookURL, "/"), s.bot.Token)
+
+	// WebhookConfig из tgbotapi не поддерживает secret_token, поэтому параметры собираем сами
+	params := tgbotapi.Params{"url": webhookURL}
+	params.AddNonEmpty("secret_token", s.config.SecretToken)
+
+	// Настраиваем вебхук в зависимости от наличия сертификата
+	if s.config.TLSCertPath != "" && s.config.TLSKeyPath != "" {
+		// Проверяем сертификат
+		if _, certErr := tls.LoadX509KeyPair(s.config.TLSCertPath, s.config.TLSKeyPath); certErr != nil {
+			return fmt.Errorf("ошибка загрузки сертификата: %v", certErr)
+		}
+
+		// Устанавливаем вебхук с сертификатом
+		files := []tgbotapi.RequestFile{{Name: "certificate", Data: tgbotapi.FilePath(s.config.TLSCertPath)}}
+		if _, reqErr := s.bot.UploadFiles("setWebhook", params, files); reqErr != nil {
+			return fmt.Errorf("ошибка при установке вебхука с сертификатом: %v", reqErr)
+		}
+		slog.Info("Используется TLS сертификат", "path", s.config.TLSCertPath)
+	} else {
+		// Устанавливаем вебхук без сертификата (для локальной разработки)
+		if _, reqErr := s.bot.MakeRequest("setWebhook", params); reqErr != nil {
+			return fmt.Errorf("ошибка при установке вебхука: %v", reqErr)
+		}
+	}
+
+	// Получаем информацию о вебхуке
+	info, err := s.bot.GetWebhookInfo()
+	if err != nil {
+		slog.Warn("Ошибка при получении информации о вебхуке", "error", err)
+	} else {
+		slog.Info("Вебхук установлен", "pending_updates", info.PendingUpdateCount)
+	}
+
+	return nil
+}
@@ -1,35 +1,84 @@
+// Package logger настраивает структурированное логирование приложения на базе log/slog.
+//
+// Логгер поддерживает уровни, текстовый и JSON-формат и маскирует секреты
+// (токены и ключи API) во всех записях, включая сообщения стандартного пакета log.
 package logger
 
 import (
+	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"os"
+	"strings"
 )
 
-// Logger представляет собой логгер приложения
-type Logger struct {
-	infoLog  *log.Logger
-	errorLog *log.Logger
+// Форматы вывода.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Config задает параметры логгера.
+type Config struct {
+	Level   string    // debug, info, warn или error; пустое значение - info
+	Format  string    // text или json; пустое значение - text
+	Output  io.Writer // Куда писать записи; по умолчанию os.Stdout
+	Secrets []string  // Значения, которые не должны попасть в лог
 }
 
-// New создает новый экземпляр логгера
-func New(prefix string) *Logger {
-	return &Logger{
-		infoLog:  log.New(os.Stdout, "["+prefix+" INFO] ", log.LstdFlags|log.Lmsgprefix),
-		errorLog: log.New(os.Stderr, "["+prefix+" ERROR] ", log.LstdFlags|log.Lmsgprefix),
+// New создает логгер по конфигурации.
+func New(cfg Config) (*slog.Logger, error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	output := cfg.Output
+	if output == nil {
+		output = os.Stdout
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case "", FormatText:
+		handler = slog.NewTextHandler(output, options)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(output, options)
+	default:
+		return nil, fmt.Errorf("неизвестный формат логов %q, ожидается text или json", cfg.Format)
 	}
+
+	return slog.New(NewRedactingHandler(handler, cfg.Secrets)), nil
 }
 
-// Info логирует информационное сообщение
-func (l *Logger) Info(format string, v ...interface{}) {
-	l.infoLog.Printf(format, v...)
+// Setup создает логгер и делает его логгером по умолчанию для slog и пакета log.
+func Setup(cfg Config) (*slog.Logger, error) {
+	l, err := New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	slog.SetDefault(l)
+	return l, nil
 }
 
-// Error логирует сообщение об ошибке
-func (l *Logger) Error(format string, v ...interface{}) {
-	l.errorLog.Printf(format, v...)
+// ParseLevel разбирает название уровня логирования.
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("неизвестный уровень логирования %q, ожидается debug, info, warn или error", name)
 }
 
-// Fatal логирует критическую ошибку и завершает программу
-func (l *Logger) Fatal(format string, v ...interface{}) {
-	l.errorLog.Fatalf(format, v...)
+// StdLogger возвращает *log.Logger, который пишет через логгер по умолчанию с уровнем level.
+// Нужен для библиотек, принимающих стандартный логгер (Telegram API, GORM).
+func StdLogger(level slog.Level) *log.Logger {
+	return slog.NewLogLogger(slog.Default().Handler(), level)
 }
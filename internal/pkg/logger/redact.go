@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+)
+
+// Redacted заменяет секрет в записях лога.
+const Redacted = "[REDACTED]"
+
+// minSecretLength - секреты короче не маскируются: замена слишком коротких строк
+// испортила бы все записи, а сами такие значения не защищают ничего.
+const minSecretLength = 4
+
+// RedactingHandler - обработчик slog, который заменяет секреты в сообщении и значениях
+// атрибутов перед передачей записи следующему обработчику. Ошибки и значения
+// других типов проверяются по их строковому представлению.
+type RedactingHandler struct {
+	next     slog.Handler
+	replacer *strings.Replacer
+}
+
+// NewRedactingHandler оборачивает обработчик маскированием секретов.
+// Если маскировать нечего, возвращается исходный обработчик.
+func NewRedactingHandler(next slog.Handler, secrets []string) slog.Handler {
+	var unique []string
+	seen := make(map[string]bool)
+	for _, secret := range secrets {
+		if len(secret) < minSecretLength || seen[secret] {
+			continue
+		}
+		seen[secret] = true
+		unique = append(unique, secret)
+	}
+	if len(unique) == 0 {
+		return next
+	}
+
+	// Более длинные секреты заменяются первыми, если один содержит другой
+	sort.Slice(unique, func(i, j int) bool { return len(unique[i]) > len(unique[j]) })
+	pairs := make([]string, 0, 2*len(unique))
+	for _, secret := range unique {
+		pairs = append(pairs, secret, Redacted)
+	}
+	return &RedactingHandler{next: next, replacer: strings.NewReplacer(pairs...)}
+}
+
+// Enabled сообщает, обрабатывается ли уровень.
+func (h *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle маскирует секреты в записи и передает ее дальше.
+func (h *RedactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	redacted := slog.NewRecord(r.Time, r.Level, h.replacer.Replace(r.Message), r.PC)
+	r.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(h.redactAttr(attr))
+		return true
+	})
+	return h.next.Handle(ctx, redacted)
+}
+
+// WithAttrs возвращает обработчик с общими атрибутами, уже очищенными от секретов.
+func (h *RedactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = h.redactAttr(attr)
+	}
+	return &RedactingHandler{next: h.next.WithAttrs(redacted), replacer: h.replacer}
+}
+
+// WithGroup возвращает обработчик для группы атрибутов.
+func (h *RedactingHandler) WithGroup(name string) slog.Handler {
+	return &RedactingHandler{next: h.next.WithGroup(name), replacer: h.replacer}
+}
+
+func (h *RedactingHandler) redactAttr(attr slog.Attr) slog.Attr {
+	value := attr.Value.Resolve()
+	switch value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, h.replacer.Replace(value.String()))
+	case slog.KindGroup:
+		group := value.Group()
+		redacted := make([]any, len(group))
+		for i, item := range group {
+			redacted[i] = h.redactAttr(item)
+		}
+		return slog.Group(attr.Key, redacted...)
+	case slog.KindAny:
+		// Ошибки (например, *url.Error с адресом запроса) и прочие значения
+		// заменяются строкой, только если в них действительно есть секрет
+		text := fmt.Sprint(value.Any())
+		if masked := h.replacer.Replace(text); masked != text {
+			return slog.String(attr.Key, masked)
+		}
+	}
+	return slog.Attr{Key: attr.Key, Value: value}
+}
@@ -0,0 +1,340 @@
+// Package metrics - минимальный реестр метрик в текстовом формате Prometheus
+// (https://prometheus.io/docs/instrumenting/exposition_formats/) без внешних зависимостей.
+//
+// Поддерживаются счетчики, измерители и гистограммы с метками. Метрики обычно
+// объявляются переменными пакета и регистрируются в реестре Default:
+//
+//	var updatesTotal = metrics.NewCounter("newsbot_updates_total", "Обработанные обновления.", "type")
+//
+//	updatesTotal.Inc("message")
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets - границы гистограмм по умолчанию в секундах, как в клиенте Prometheus.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Default - реестр, в котором регистрируют метрики функции пакета.
+var Default = NewRegistry()
+
+// collector - метрика, которую реестр выводит в текстовом формате.
+type collector interface {
+	describe() *desc
+	write(w *bufio.Writer)
+}
+
+// Registry хранит метрики и выводит их в текстовом формате Prometheus.
+type Registry struct {
+	mu         sync.Mutex
+	collectors []collector
+	names      map[string]bool
+}
+
+// NewRegistry создает пустой реестр.
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+// register добавляет метрику в реестр. Повторное имя - ошибка программиста, поэтому паника.
+func (r *Registry) register(c collector) {
+	d := c.describe()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[d.name] {
+		panic(fmt.Sprintf("metrics: метрика %q уже зарегистрирована", d.name))
+	}
+	r.names[d.name] = true
+	r.collectors = append(r.collectors, c)
+}
+
+// WriteText выводит все метрики реестра, отсортированные по имени.
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	collectors := append([]collector(nil), r.collectors...)
+	r.mu.Unlock()
+	sort.Slice(collectors, func(i, j int) bool { return collectors[i].describe().name < collectors[j].describe().name })
+
+	bw := bufio.NewWriter(w)
+	for _, c := range collectors {
+		d := c.describe()
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s %s\n", d.name, escapeHelp(d.help), d.name, d.kind)
+		c.write(bw)
+	}
+	return bw.Flush()
+}
+
+// Handler возвращает HTTP-обработчик, отдающий метрики реестра.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = r.WriteText(w)
+	})
+}
+
+// desc описывает метрику: имя, описание, тип и названия меток.
+type desc struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+}
+
+// key возвращает ключ ряда по значениям меток и проверяет их количество.
+func (d *desc) key(values []string) string {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: метрика %q ожидает %d меток, передано %d", d.name, len(d.labels), len(values)))
+	}
+	return strings.Join(values, "\xff")
+}
+
+// series возвращает строку меток ряда, например {type="message"}, с дополнительной меткой extra.
+func (d *desc) series(values []string, extra ...string) string {
+	if len(d.labels) == 0 && len(extra) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, label := range d.labels {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(label + `="` + escapeLabel(values[i]) + `"`)
+	}
+	for i := 0; i+1 < len(extra); i += 2 {
+		if b.Len() > 1 {
+			b.WriteByte(',')
+		}
+		b.WriteString(extra[i] + `="` + escapeLabel(extra[i+1]) + `"`)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// sortedKeys возвращает ключи рядов в стабильном порядке вывода.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// splitKey восстанавливает значения меток из ключа ряда.
+func splitKey(key string, labels int) []string {
+	if labels == 0 {
+		return nil
+	}
+	return strings.Split(key, "\xff")
+}
+
+// Counter - монотонно растущий счетчик с метками.
+type Counter struct {
+	desc   desc
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// NewCounter создает счетчик и регистрирует его в реестре.
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{desc: desc{name: name, help: help, kind: "counter", labels: labels}, values: make(map[string]float64)}
+	r.register(c)
+	return c
+}
+
+// NewCounter создает счетчик в реестре Default.
+func NewCounter(name, help string, labels ...string) *Counter {
+	return Default.NewCounter(name, help, labels...)
+}
+
+// Inc увеличивает счетчик ряда с указанными значениями меток на единицу.
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add увеличивает счетчик на v. Отрицательные значения игнорируются: счетчик не убывает.
+func (c *Counter) Add(v float64, labelValues ...string) {
+	key := c.desc.key(labelValues)
+	if v < 0 {
+		return
+	}
+	c.mu.Lock()
+	c.values[key] += v
+	c.mu.Unlock()
+}
+
+// Value возвращает текущее значение ряда.
+func (c *Counter) Value(labelValues ...string) float64 {
+	key := c.desc.key(labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.values[key]
+}
+
+func (c *Counter) describe() *desc { return &c.desc }
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(w, "%s%s %s\n", c.desc.name, c.desc.series(splitKey(key, len(c.desc.labels))), formatFloat(c.values[key]))
+	}
+}
+
+// Gauge - измеритель: значение, которое может как расти, так и убывать.
+type Gauge struct {
+	desc   desc
+	mu     sync.Mutex
+	values map[string]float64
+}
+
+// NewGauge создает измеритель и регистрирует его в реестре.
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{desc: desc{name: name, help: help, kind: "gauge", labels: labels}, values: make(map[string]float64)}
+	r.register(g)
+	return g
+}
+
+// NewGauge создает измеритель в реестре Default.
+func NewGauge(name, help string, labels ...string) *Gauge {
+	return Default.NewGauge(name, help, labels...)
+}
+
+// Set устанавливает значение ряда.
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	key := g.desc.key(labelValues)
+	g.mu.Lock()
+	g.values[key] = v
+	g.mu.Unlock()
+}
+
+// Value возвращает текущее значение ряда.
+func (g *Gauge) Value(labelValues ...string) float64 {
+	key := g.desc.key(labelValues)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.values[key]
+}
+
+func (g *Gauge) describe() *desc { return &g.desc }
+
+func (g *Gauge) write(w *bufio.Writer) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, key := range sortedKeys(g.values) {
+		fmt.Fprintf(w, "%s%s %s\n", g.desc.name, g.desc.series(splitKey(key, len(g.desc.labels))), formatFloat(g.values[key]))
+	}
+}
+
+// Histogram распределяет наблюдения по корзинам с верхними границами buckets.
+type Histogram struct {
+	desc    desc
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	counts []uint64 // Наблюдения по корзинам, без накопления
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram создает гистограмму и регистрирует ее в реестре.
+// Если buckets пуст, используются DefaultBuckets.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	h := &Histogram{
+		desc:    desc{name: name, help: help, kind: "histogram", labels: labels},
+		buckets: buckets,
+		series:  make(map[string]*histogramSeries),
+	}
+	r.register(h)
+	return h
+}
+
+// NewHistogram создает гистограмму в реестре Default.
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return Default.NewHistogram(name, help, buckets, labels...)
+}
+
+// Observe добавляет наблюдение в ряд с указанными значениями меток.
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	key := h.desc.key(labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{counts: make([]uint64, len(h.buckets))}
+		h.series[key] = s
+	}
+	if i := sort.SearchFloat64s(h.buckets, v); i < len(h.buckets) {
+		s.counts[i]++
+	}
+	s.count++
+	s.sum += v
+}
+
+// Count возвращает количество наблюдений в ряду.
+func (h *Histogram) Count(labelValues ...string) uint64 {
+	key := h.desc.key(labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if s, ok := h.series[key]; ok {
+		return s.count
+	}
+	return 0
+}
+
+func (h *Histogram) describe() *desc { return &h.desc }
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		values := splitKey(key, len(h.desc.labels))
+		var cumulative uint64
+		for i, bound := range h.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.desc.name, h.desc.series(values, "le", formatFloat(bound)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.desc.name, h.desc.series(values, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.desc.name, h.desc.series(values), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.desc.name, h.desc.series(values), s.count)
+	}
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
@@ -0,0 +1,21 @@
+#!/bin/sh
+
+# Проверка готовности для HEALTHCHECK внутри контейнера: запрашивает /readyz
+# у сервера наблюдаемости по адресу из OBSERVABILITY_ADDR (по умолчанию :9090)
+
+ADDR=${OBSERVABILITY_ADDR-:9090}
+
+# Пустой адрес отключает сервер наблюдаемости: проверять нечего
+if [ -z "$ADDR" ]; then
+    exit 0
+fi
+
+HOST=${ADDR%:*}
+PORT=${ADDR##*:}
+
+# Сервер слушает все интерфейсы: обращаемся к нему через loopback
+case "$HOST" in
+    ""|"0.0.0.0"|"[::]") HOST=127.0.0.1 ;;
+esac
+
+wget -q -O /dev/null "http://$HOST:$PORT/readyz" || exit 1
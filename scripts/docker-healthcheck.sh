@@ -27,4 +27,26 @@ else
     echo "Статус здоровья: $HEALTH_STATUS"
 fi
 
+# Запрашиваем отчеты сервера наблюдаемости: docker-compose публикует его порт на localhost
+OBSERVABILITY_URL=${OBSERVABILITY_URL:-http://127.0.0.1:9090}
+
+if ! curl -fsS -o /dev/null "$OBSERVABILITY_URL/livez"; then
+    echo "❌ Бот не отвечает на $OBSERVABILITY_URL/livez"
+    exit 1
+fi
+echo "✅ Процесс бота отвечает на /livez"
+
+# /readyz возвращает 503, если хотя бы одна проверка не пройдена; тело содержит результат каждой проверки
+READY_STATUS=$(curl -sS -o /tmp/readyz.json -w '%{http_code}' "$OBSERVABILITY_URL/readyz")
+echo "Отчет о готовности (/readyz):"
+cat /tmp/readyz.json
+rm -f /tmp/readyz.json
+
+if [ "$READY_STATUS" = "200" ]; then
+    echo "✅ Все проверки готовности пройдены"
+else
+    echo "❌ Бот не готов (HTTP $READY_STATUS): см. проверки со статусом fail"
+    exit 1
+fi
+
 echo "Проверка завершена"
@@ -0,0 +1,43 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
+)
+
+func TestMigrateArticleHashes(t *testing.T) {
+	db := setupTestDB(t)
+	if err := db.AutoMigrate(&database.SentArticle{}, &database.OutboxMessage{}, &database.DigestItem{}); err != nil {
+		t.Fatalf("Failed to migrate article tables: %v", err)
+	}
+	ctx := context.Background()
+
+	// Записи в старом формате: вместо ключа хранится адрес статьи
+	legacyURL := "http://www.example.com/news/1?utm_source=rss"
+	if err := db.Create(&database.SentArticle{UserID: 1, ArticleHash: legacyURL}).Error; err != nil {
+		t.Fatalf("Failed to create sent article: %v", err)
+	}
+	if err := db.Create(&database.FavoriteArticle{UserID: 1, ArticleURL: legacyURL, Title: "News"}).Error; err != nil {
+		t.Fatalf("Failed to create favorite article: %v", err)
+	}
+
+	// Миграция повторяется при каждом запуске и не должна менять уже перенесенные записи
+	for i := 0; i < 2; i++ {
+		if err := database.MigrateArticleHashes(db, fetcher.ArticleKey); err != nil {
+			t.Fatalf("MigrateArticleHashes() error = %v", err)
+		}
+	}
+
+	sent, err := database.NewSentArticleRepository(db).IsArticleSent(ctx, 1, fetcher.ArticleKey("https://example.com/news/1"))
+	if err != nil || !sent {
+		t.Errorf("IsArticleSent() after migration = %v, %v; want true", sent, err)
+	}
+
+	favorite, err := database.NewFavoriteArticleRepository(db).IsFavoriteArticle(ctx, 1, fetcher.ArticleKey("https://example.com/news/1/amp"))
+	if err != nil || !favorite {
+		t.Errorf("IsFavoriteArticle() after migration = %v, %v; want true", favorite, err)
+	}
+}
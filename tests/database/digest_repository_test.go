@@ -0,0 +1,75 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+)
+
+func TestDigestRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	if err := db.AutoMigrate(&database.DigestItem{}); err != nil {
+		t.Fatalf("Failed to migrate digest table: %v", err)
+	}
+	repo := database.NewDigestRepository(db)
+	ctx := context.Background()
+
+	for _, hash := range []string{"https://example.com/1", "https://example.com/2"} {
+		created, err := repo.AddDigestItem(ctx, &database.DigestItem{UserID: 1, Topic: "go", ArticleHash: hash, Payload: "{}"})
+		if err != nil || !created {
+			t.Fatalf("AddDigestItem(%s) = %v, %v; want created", hash, created, err)
+		}
+	}
+
+	// Повторное добавление той же статьи не создает дубликат
+	created, err := repo.AddDigestItem(ctx, &database.DigestItem{UserID: 1, Topic: "golang", ArticleHash: "https://example.com/1", Payload: "{}"})
+	if err != nil || created {
+		t.Fatalf("Duplicate AddDigestItem() = %v, %v; want skipped", created, err)
+	}
+
+	if inDigest, _ := repo.IsArticleInDigest(ctx, 2, "https://example.com/1"); inDigest {
+		t.Error("Digest items must not leak between users")
+	}
+
+	items, err := repo.GetDigestItems(ctx, 1)
+	if err != nil || len(items) != 2 {
+		t.Fatalf("GetDigestItems() = %d items, %v; want 2", len(items), err)
+	}
+
+	if err := repo.DeleteDigestItems(ctx, []uint{items[0].ID}); err != nil {
+		t.Fatalf("DeleteDigestItems() error = %v", err)
+	}
+	items, _ = repo.GetDigestItems(ctx, 1)
+	if len(items) != 1 || items[0].ArticleHash != "https://example.com/2" {
+		t.Errorf("Unexpected digest items after delete: %+v", items)
+	}
+}
+
+func TestDigestRepository_Duplicates(t *testing.T) {
+	db := setupTestDB(t)
+	if err := db.AutoMigrate(&database.DigestItem{}); err != nil {
+		t.Fatalf("Failed to migrate digest table: %v", err)
+	}
+	repo := database.NewDigestRepository(db)
+	ctx := context.Background()
+
+	item := &database.DigestItem{UserID: 1, Topic: "go", ArticleHash: "a1", Payload: "{}",
+		Duplicates: database.JoinDuplicates([]string{"b1", "c_1"})}
+	if _, err := repo.AddDigestItem(ctx, item); err != nil {
+		t.Fatalf("AddDigestItem() error = %v", err)
+	}
+
+	// Похожая статья уже отложена вместе с представителем
+	for _, hash := range []string{"b1", "c_1"} {
+		if inDigest, _ := repo.IsArticleInDigest(ctx, 1, hash); !inDigest {
+			t.Errorf("IsArticleInDigest(%s) = false, want true for duplicate", hash)
+		}
+	}
+	// Ключ сравнивается целиком, а не как часть другого ключа
+	for _, hash := range []string{"b", "c11", "1"} {
+		if inDigest, _ := repo.IsArticleInDigest(ctx, 1, hash); inDigest {
+			t.Errorf("IsArticleInDigest(%s) = true, want false", hash)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
 	"gorm.io/gorm"
 )
 
@@ -66,7 +67,7 @@ func TestFavoriteArticleRepository_AddFavoriteArticle(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := repo.AddFavoriteArticle(ctx, tt.userID, tt.articleURL, tt.articleTitle, "test-source", time.Now())
+			err := repo.AddFavoriteArticle(ctx, tt.userID, fetcher.ArticleKey(tt.articleURL), tt.articleURL, tt.articleTitle, "test-source", time.Now())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("AddFavoriteArticle() error = %v, wantErr %v", err, tt.wantErr)
 			}
@@ -93,7 +94,7 @@ func TestFavoriteArticleRepository_IsFavoriteArticle(t *testing.T) {
 
 	// Добавляем статью в избранное
 	favoriteURL := "https://example.com/favorite"
-	err = repo.AddFavoriteArticle(ctx, user.ID, favoriteURL, "Favorite Article", "test-source", time.Now())
+	err = repo.AddFavoriteArticle(ctx, user.ID, fetcher.ArticleKey(favoriteURL), favoriteURL, "Favorite Article", "test-source", time.Now())
 	if err != nil {
 		t.Fatalf("Failed to add favorite article: %v", err)
 	}
@@ -123,7 +124,7 @@ func TestFavoriteArticleRepository_IsFavoriteArticle(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := repo.IsFavoriteArticle(ctx, tt.userID, tt.articleURL)
+			got, err := repo.IsFavoriteArticle(ctx, tt.userID, fetcher.ArticleKey(tt.articleURL))
 			if (err != nil) != tt.wantErr {
 				t.Errorf("IsFavoriteArticle() error = %v, wantErr %v", err, tt.wantErr)
 				return
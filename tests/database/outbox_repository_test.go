@@ -0,0 +1,143 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+)
+
+func TestOutboxRepository_Lifecycle(t *testing.T) {
+	db := setupTestDB(t)
+	if err := db.AutoMigrate(&database.OutboxMessage{}); err != nil {
+		t.Fatalf("Failed to migrate outbox table: %v", err)
+	}
+	repo := database.NewOutboxRepository(db)
+	ctx := context.Background()
+	now := time.Now()
+
+	msg := &database.OutboxMessage{UserID: 1, ChatID: 100, ArticleHash: "https://example.com/1", Payload: "{}"}
+	created, err := repo.EnqueueOutboxMessage(ctx, msg)
+	if err != nil || !created {
+		t.Fatalf("EnqueueOutboxMessage() = %v, %v; want created", created, err)
+	}
+
+	// Повторная постановка той же статьи не создает дубликат
+	created, err = repo.EnqueueOutboxMessage(ctx, &database.OutboxMessage{UserID: 1, ChatID: 100, ArticleHash: "https://example.com/1", Payload: "{}"})
+	if err != nil || created {
+		t.Fatalf("Duplicate EnqueueOutboxMessage() = %v, %v; want skipped", created, err)
+	}
+
+	due, err := repo.GetDueOutboxMessages(ctx, 1, now.Add(time.Second), 10)
+	if err != nil || len(due) != 1 {
+		t.Fatalf("GetDueOutboxMessages() = %d messages, %v; want 1", len(due), err)
+	}
+
+	claimed, err := repo.ClaimOutboxMessage(ctx, msg.ID, now.Add(time.Second), now.Add(time.Minute))
+	if err != nil || !claimed {
+		t.Fatalf("ClaimOutboxMessage() = %v, %v; want claimed", claimed, err)
+	}
+	claimed, _ = repo.ClaimOutboxMessage(ctx, msg.ID, now.Add(time.Second), now.Add(time.Minute))
+	if claimed {
+		t.Error("Message should not be claimed twice while leased")
+	}
+
+	retryAt := now.Add(-time.Second)
+	if err := repo.MarkOutboxMessageFailed(ctx, msg.ID, "temporary failure", retryAt, false); err != nil {
+		t.Fatalf("MarkOutboxMessageFailed() error = %v", err)
+	}
+	due, _ = repo.GetDueOutboxMessages(ctx, 0, now, 10)
+	if len(due) != 1 || due[0].Attempts != 1 || due[0].LastError != "temporary failure" {
+		t.Fatalf("Failed message should be due again with attempt counted, got %+v", due)
+	}
+
+	if err := repo.MarkOutboxMessageDelivered(ctx, msg.ID); err != nil {
+		t.Fatalf("MarkOutboxMessageDelivered() error = %v", err)
+	}
+	if count, _ := repo.CountPendingOutboxMessages(ctx); count != 0 {
+		t.Errorf("CountPendingOutboxMessages() = %d, want 0", count)
+	}
+	if queued, _ := repo.IsArticleQueued(ctx, 1, "https://example.com/1"); queued {
+		t.Error("Delivered article should not be reported as queued")
+	}
+}
+
+func TestOutboxRepository_GiveUp(t *testing.T) {
+	db := setupTestDB(t)
+	if err := db.AutoMigrate(&database.OutboxMessage{}); err != nil {
+		t.Fatalf("Failed to migrate outbox table: %v", err)
+	}
+	repo := database.NewOutboxRepository(db)
+	ctx := context.Background()
+
+	msg := &database.OutboxMessage{UserID: 2, ChatID: 200, ArticleHash: "https://example.com/2", Payload: "{}"}
+	if _, err := repo.EnqueueOutboxMessage(ctx, msg); err != nil {
+		t.Fatalf("EnqueueOutboxMessage() error = %v", err)
+	}
+	if err := repo.MarkOutboxMessageFailed(ctx, msg.ID, "bot was blocked", time.Now(), true); err != nil {
+		t.Fatalf("MarkOutboxMessageFailed() error = %v", err)
+	}
+
+	due, _ := repo.GetDueOutboxMessages(ctx, 0, time.Now().Add(time.Hour), 10)
+	if len(due) != 0 {
+		t.Errorf("Failed messages should not be delivered again, got %d", len(due))
+	}
+	if count, _ := repo.CountPendingOutboxMessages(ctx); count != 0 {
+		t.Errorf("CountPendingOutboxMessages() = %d, want 0", count)
+	}
+
+	// Недоставленная статья не ставится в очередь повторно
+	if queued, _ := repo.IsArticleQueued(ctx, 2, "https://example.com/2"); !queued {
+		t.Error("Failed article should be reported as queued")
+	}
+	created, err := repo.EnqueueOutboxMessage(ctx, &database.OutboxMessage{UserID: 2, ChatID: 200, ArticleHash: "https://example.com/2", Payload: "{}"})
+	if err != nil || created {
+		t.Errorf("EnqueueOutboxMessage() after give up = %v, %v; want skipped", created, err)
+	}
+
+	// Очистка удаляет только сообщения, недоставленные раньше заданного момента
+	if pruned, err := repo.PruneFailedOutboxMessages(ctx, time.Now().Add(-time.Hour)); err != nil || pruned != 0 {
+		t.Errorf("PruneFailedOutboxMessages(hour ago) = %d, %v; want 0", pruned, err)
+	}
+	if pruned, err := repo.PruneFailedOutboxMessages(ctx, time.Now().Add(time.Second)); err != nil || pruned != 1 {
+		t.Errorf("PruneFailedOutboxMessages(now) = %d, %v; want 1", pruned, err)
+	}
+	if queued, _ := repo.IsArticleQueued(ctx, 2, "https://example.com/2"); queued {
+		t.Error("Pruned article should not be reported as queued")
+	}
+}
+
+func TestOutboxRepository_Duplicates(t *testing.T) {
+	db := setupTestDB(t)
+	if err := db.AutoMigrate(&database.OutboxMessage{}); err != nil {
+		t.Fatalf("Failed to migrate outbox table: %v", err)
+	}
+	repo := database.NewOutboxRepository(db)
+	ctx := context.Background()
+
+	msg := &database.OutboxMessage{UserID: 3, ChatID: 300, ArticleHash: "a1", Payload: "{}",
+		Duplicates: database.JoinDuplicates([]string{"b1", "c1"})}
+	if _, err := repo.EnqueueOutboxMessage(ctx, msg); err != nil {
+		t.Fatalf("EnqueueOutboxMessage() error = %v", err)
+	}
+
+	// Похожая статья ждет доставки вместе с представителем
+	if queued, _ := repo.IsArticleQueued(ctx, 3, "c1"); !queued {
+		t.Error("Duplicate of pending message should be reported as queued")
+	}
+	if queued, _ := repo.IsArticleQueued(ctx, 3, "c"); queued {
+		t.Error("Partial key should not match duplicates")
+	}
+
+	// Если представителя доставить не удалось, похожую статью можно отправить отдельно
+	if err := repo.MarkOutboxMessageFailed(ctx, msg.ID, "bad request", time.Now(), true); err != nil {
+		t.Fatalf("MarkOutboxMessageFailed() error = %v", err)
+	}
+	if queued, _ := repo.IsArticleQueued(ctx, 3, "c1"); queued {
+		t.Error("Duplicate of failed message should not be reported as queued")
+	}
+	if queued, _ := repo.IsArticleQueued(ctx, 3, "a1"); !queued {
+		t.Error("Failed message itself should stay queued")
+	}
+}
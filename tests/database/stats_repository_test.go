@@ -0,0 +1,85 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+)
+
+func TestStatsRepository(t *testing.T) {
+	db := setupTestDB(t)
+	if err := db.AutoMigrate(&database.SentArticle{}); err != nil {
+		t.Fatalf("Failed to migrate sent articles table: %v", err)
+	}
+	users := database.NewUserRepository(db)
+	subs := database.NewSubscriptionRepository(db)
+	sent := database.NewSentArticleRepository(db)
+	stats := database.NewStatsRepository(db)
+	ctx := context.Background()
+
+	var ids []uint
+	for i := int64(1); i <= 3; i++ {
+		user, err := users.FindOrCreateUser(ctx, 3000+i, "user", "Test", "User")
+		if err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+		ids = append(ids, user.ID)
+	}
+	for _, sub := range []struct {
+		userID uint
+		topic  string
+	}{{ids[0], "спорт"}, {ids[0], "наука"}, {ids[1], "спорт"}} {
+		if err := subs.AddSubscription(ctx, sub.userID, sub.topic); err != nil {
+			t.Fatalf("Failed to add subscription: %v", err)
+		}
+	}
+
+	now := time.Now()
+	yesterday := now.AddDate(0, 0, -1)
+	for _, article := range []database.SentArticle{
+		{UserID: ids[0], ArticleHash: "a", SentAt: now},
+		{UserID: ids[0], ArticleHash: "b", SentAt: now},
+		{UserID: ids[1], ArticleHash: "a", SentAt: yesterday},
+		{UserID: ids[2], ArticleHash: "c", SentAt: now.AddDate(0, 0, -30)},
+	} {
+		if err := db.Create(&article).Error; err != nil {
+			t.Fatalf("Failed to create sent article: %v", err)
+		}
+	}
+	// Сброс истории не должен уменьшать статистику отправок
+	if err := sent.ResetSentArticlesHistory(ctx, ids[1]); err != nil {
+		t.Fatalf("ResetSentArticlesHistory() error = %v", err)
+	}
+
+	since := now.AddDate(0, 0, -7)
+	userStats, err := stats.GetUserStats(ctx, since)
+	if err != nil {
+		t.Fatalf("GetUserStats() error = %v", err)
+	}
+	want := database.UserStats{Total: 3, Subscribed: 2, Active: 2, New: 3}
+	if userStats != want {
+		t.Errorf("GetUserStats() = %+v, want %+v", userStats, want)
+	}
+
+	topics, err := stats.GetTopTopics(ctx, 5)
+	if err != nil {
+		t.Fatalf("GetTopTopics() error = %v", err)
+	}
+	if len(topics) != 2 || topics[0] != (database.TopicStats{Topic: "спорт", Subscribers: 2}) || topics[1] != (database.TopicStats{Topic: "наука", Subscribers: 1}) {
+		t.Errorf("GetTopTopics() = %+v, want спорт:2, наука:1", topics)
+	}
+
+	days, err := stats.CountSentArticlesByDay(ctx, since)
+	if err != nil {
+		t.Fatalf("CountSentArticlesByDay() error = %v", err)
+	}
+	wantDays := []database.DailyCount{
+		{Day: yesterday.Format("2006-01-02"), Count: 1},
+		{Day: now.Format("2006-01-02"), Count: 2},
+	}
+	if len(days) != len(wantDays) || days[0] != wantDays[0] || days[1] != wantDays[1] {
+		t.Errorf("CountSentArticlesByDay() = %+v, want %+v", days, wantDays)
+	}
+}
@@ -0,0 +1,126 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+)
+
+func TestSubscriptionRepository_Settings(t *testing.T) {
+	db := setupTestDB(t)
+	repo := database.NewSubscriptionRepository(db)
+	ctx := context.Background()
+
+	if err := repo.AddSubscription(ctx, 1, "Golang"); err != nil {
+		t.Fatalf("AddSubscription() error = %v", err)
+	}
+	subs, err := repo.GetUserSubscriptionDetails(ctx, 1)
+	if err != nil || len(subs) != 1 {
+		t.Fatalf("GetUserSubscriptionDetails() = %d subscriptions, %v; want 1", len(subs), err)
+	}
+	sub := subs[0]
+	if sub.Topic != "golang" || sub.SubscriptionSettings != (database.SubscriptionSettings{}) {
+		t.Fatalf("New subscription must use defaults, got %+v", sub)
+	}
+
+	settings := database.SubscriptionSettings{IntervalMinutes: 30, MaxArticles: 3, Language: "en", Country: "us", Muted: true}
+	if err := repo.UpdateSubscriptionSettings(ctx, 1, sub.ID, settings); err != nil {
+		t.Fatalf("UpdateSubscriptionSettings() error = %v", err)
+	}
+
+	// Чужой пользователь не может изменить подписку
+	if err := repo.UpdateSubscriptionSettings(ctx, 2, sub.ID, database.SubscriptionSettings{}); err == nil {
+		t.Error("UpdateSubscriptionSettings() for another user must fail")
+	}
+	if _, err := repo.GetSubscription(ctx, 2, sub.ID); err == nil {
+		t.Error("GetSubscription() for another user must fail")
+	}
+
+	got, err := repo.GetSubscription(ctx, 1, sub.ID)
+	if err != nil {
+		t.Fatalf("GetSubscription() error = %v", err)
+	}
+	if got.SubscriptionSettings != settings {
+		t.Errorf("SubscriptionSettings = %+v, want %+v", got.SubscriptionSettings, settings)
+	}
+
+	// Сброс к общим настройкам сохраняет нулевые значения
+	if err := repo.UpdateSubscriptionSettings(ctx, 1, sub.ID, database.SubscriptionSettings{}); err != nil {
+		t.Fatalf("UpdateSubscriptionSettings() error = %v", err)
+	}
+	got, _ = repo.GetSubscription(ctx, 1, sub.ID)
+	if got.SubscriptionSettings != (database.SubscriptionSettings{}) {
+		t.Errorf("Settings were not reset: %+v", got.SubscriptionSettings)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	if err := repo.UpdateSubscriptionsLastNotifiedAt(ctx, []uint{sub.ID}, now); err != nil {
+		t.Fatalf("UpdateSubscriptionsLastNotifiedAt() error = %v", err)
+	}
+	got, _ = repo.GetSubscription(ctx, 1, sub.ID)
+	if got.LastNotifiedAt == nil || !got.LastNotifiedAt.Equal(now) {
+		t.Errorf("LastNotifiedAt = %v, want %v", got.LastNotifiedAt, now)
+	}
+}
+
+func TestSubscriptionRepository_GetSubscriptionsForUsers(t *testing.T) {
+	db := setupTestDB(t)
+	repo := database.NewSubscriptionRepository(db)
+	ctx := context.Background()
+
+	for userID, topic := range map[uint]string{1: "go", 2: "rust", 3: "java"} {
+		if err := repo.AddSubscription(ctx, userID, topic); err != nil {
+			t.Fatalf("AddSubscription() error = %v", err)
+		}
+	}
+
+	subs, err := repo.GetSubscriptionsForUsers(ctx, []uint{1, 3})
+	if err != nil {
+		t.Fatalf("GetSubscriptionsForUsers() error = %v", err)
+	}
+	if len(subs) != 2 {
+		t.Fatalf("GetSubscriptionsForUsers() = %d subscriptions, want 2", len(subs))
+	}
+	for _, sub := range subs {
+		if sub.UserID == 2 {
+			t.Errorf("Unexpected subscription of user 2: %+v", sub)
+		}
+	}
+}
+
+func TestSubscriptionRepository_UpdateTopic(t *testing.T) {
+	db := setupTestDB(t)
+	repo := database.NewSubscriptionRepository(db)
+	ctx := context.Background()
+
+	for _, topic := range []string{"футбл", "хоккей"} {
+		if err := repo.AddSubscription(ctx, 1, topic); err != nil {
+			t.Fatalf("AddSubscription(%s) error = %v", topic, err)
+		}
+	}
+	subs, _ := repo.GetUserSubscriptionDetails(ctx, 1)
+	settings := database.SubscriptionSettings{IntervalMinutes: 30, MaxArticles: 3, Language: "ru"}
+	if err := repo.UpdateSubscriptionSettings(ctx, 1, subs[0].ID, settings); err != nil {
+		t.Fatalf("UpdateSubscriptionSettings() error = %v", err)
+	}
+
+	// Переименование сохраняет подписку и ее настройки
+	if err := repo.UpdateSubscriptionTopic(ctx, 1, subs[0].ID, "Футбол"); err != nil {
+		t.Fatalf("UpdateSubscriptionTopic() error = %v", err)
+	}
+	got, err := repo.GetSubscription(ctx, 1, subs[0].ID)
+	if err != nil || got.Topic != "футбол" || got.SubscriptionSettings != settings {
+		t.Errorf("GetSubscription() = %+v, %v; want футбол with settings kept", got, err)
+	}
+
+	// На уже существующую тему переименовать нельзя
+	if err := repo.UpdateSubscriptionTopic(ctx, 1, subs[0].ID, "хоккей"); !errors.Is(err, database.ErrSubscriptionExists) {
+		t.Errorf("UpdateSubscriptionTopic() to existing topic error = %v, want ErrSubscriptionExists", err)
+	}
+	if err := repo.UpdateSubscriptionTopic(ctx, 2, subs[0].ID, "теннис"); err == nil {
+		t.Error("UpdateSubscriptionTopic() for another user must fail")
+	}
+}
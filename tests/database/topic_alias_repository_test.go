@@ -0,0 +1,57 @@
+package database_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+)
+
+func TestTopicAliasRepository(t *testing.T) {
+	db := setupTestDB(t)
+	if err := db.AutoMigrate(&database.TopicAlias{}); err != nil {
+		t.Fatalf("Failed to migrate topic aliases: %v", err)
+	}
+	repo := database.NewTopicAliasRepository(db)
+	ctx := context.Background()
+
+	if err := repo.AddTopicAlias(ctx, "Искусственный  Интелент", "искусственный интеллект", database.TopicAliasCorrection); err != nil {
+		t.Fatalf("AddTopicAlias() error = %v", err)
+	}
+	// Новое исправление того же варианта заменяет прежнее
+	if err := repo.AddTopicAlias(ctx, "искусственный интелент", "ии", database.TopicAliasCorrection); err != nil {
+		t.Fatalf("AddTopicAlias() error = %v", err)
+	}
+	if err := repo.AddTopicAlias(ctx, "ai", "ии", database.TopicAliasSynonym); err != nil {
+		t.Fatalf("AddTopicAlias() error = %v", err)
+	}
+	if err := repo.AddTopicAlias(ctx, "ии", "ИИ", database.TopicAliasSynonym); err == nil {
+		t.Error("AddTopicAlias() should reject an alias equal to its topic")
+	}
+	if err := repo.AddTopicAlias(ctx, "x", "y", "unknown"); err == nil {
+		t.Error("AddTopicAlias() should reject an unknown kind")
+	}
+
+	corrections, synonyms, err := repo.LoadTopicAliases(ctx)
+	if err != nil {
+		t.Fatalf("LoadTopicAliases() error = %v", err)
+	}
+	if want := map[string]string{"искусственный интелент": "ии"}; !reflect.DeepEqual(corrections, want) {
+		t.Errorf("corrections = %v, want %v", corrections, want)
+	}
+	if want := map[string][]string{"ии": {"ai"}, "ai": {"ии"}}; !reflect.DeepEqual(synonyms, want) {
+		t.Errorf("synonyms = %v, want %v", synonyms, want)
+	}
+
+	if err := repo.RemoveTopicAlias(ctx, "AI"); err != nil {
+		t.Fatalf("RemoveTopicAlias() error = %v", err)
+	}
+	if err := repo.RemoveTopicAlias(ctx, "ai"); err == nil {
+		t.Error("RemoveTopicAlias() should fail for a missing alias")
+	}
+	aliases, err := repo.GetTopicAliases(ctx)
+	if err != nil || len(aliases) != 1 {
+		t.Errorf("GetTopicAliases() = %d aliases, %v; want 1", len(aliases), err)
+	}
+}
@@ -0,0 +1,82 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+)
+
+func TestUserRepository_GetUsersBatch(t *testing.T) {
+	db := setupTestDB(t)
+	repo := database.NewUserRepository(db)
+	ctx := context.Background()
+
+	for i := int64(1); i <= 5; i++ {
+		if _, err := repo.FindOrCreateUser(ctx, 1000+i, "user", "Test", "User"); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	// Обходим пользователей по курсору пачками по два
+	var afterID uint
+	var pages [][]uint
+	for {
+		users, err := repo.GetUsersBatch(ctx, afterID, 2)
+		if err != nil {
+			t.Fatalf("GetUsersBatch() error = %v", err)
+		}
+		if len(users) == 0 {
+			break
+		}
+
+		var ids []uint
+		for _, user := range users {
+			if user.ID <= afterID {
+				t.Errorf("User ID %d is not after cursor %d", user.ID, afterID)
+			}
+			ids = append(ids, user.ID)
+		}
+		pages = append(pages, ids)
+		afterID = users[len(users)-1].ID
+	}
+
+	if len(pages) != 3 {
+		t.Fatalf("Expected 3 pages, got %d: %v", len(pages), pages)
+	}
+	if len(pages[0]) != 2 || len(pages[1]) != 2 || len(pages[2]) != 1 {
+		t.Errorf("Unexpected page sizes: %v", pages)
+	}
+}
+
+func TestUserRepository_UpdateTimeZoneAndQuietHours(t *testing.T) {
+	db := setupTestDB(t)
+	repo := database.NewUserRepository(db)
+	ctx := context.Background()
+
+	user, err := repo.FindOrCreateUser(ctx, 2001, "user", "Test", "User")
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+
+	if err := repo.UpdateUserTimeZone(ctx, user.ID, "Asia/Novosibirsk"); err != nil {
+		t.Fatalf("UpdateUserTimeZone() error = %v", err)
+	}
+	if err := repo.UpdateUserQuietHours(ctx, user.ID, "22:00", "08:00"); err != nil {
+		t.Fatalf("UpdateUserQuietHours() error = %v", err)
+	}
+
+	user, _ = repo.FindOrCreateUser(ctx, 2001, "user", "Test", "User")
+	if user.TimeZone != "Asia/Novosibirsk" || user.QuietHoursStart != "22:00" || user.QuietHoursEnd != "08:00" {
+		t.Errorf("Settings were not saved: %q %q-%q", user.TimeZone, user.QuietHoursStart, user.QuietHoursEnd)
+	}
+
+	// Пустые значения отключают тихие часы
+	if err := repo.UpdateUserQuietHours(ctx, user.ID, "", ""); err != nil {
+		t.Fatalf("UpdateUserQuietHours() error = %v", err)
+	}
+	user, _ = repo.FindOrCreateUser(ctx, 2001, "user", "Test", "User")
+	if user.QuietHoursStart != "" || user.QuietHoursEnd != "" {
+		t.Errorf("Quiet hours were not disabled: %q-%q", user.QuietHoursStart, user.QuietHoursEnd)
+	}
+}
@@ -0,0 +1,87 @@
+package fetcher_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/query"
+)
+
+// staticAliasStore - хранилище синонимов с заранее заданной таблицей.
+type staticAliasStore struct {
+	corrections map[string]string
+	synonyms    map[string][]string
+}
+
+func (s staticAliasStore) LoadTopicAliases(ctx context.Context) (map[string]string, map[string][]string, error) {
+	return s.corrections, s.synonyms, nil
+}
+
+func newTestAliases(t *testing.T) *fetcher.Aliases {
+	t.Helper()
+	aliases := fetcher.NewAliases(staticAliasStore{
+		corrections: map[string]string{
+			"искусственный интелент": "искусственный интеллект",
+			"гугл": "google",
+		},
+		synonyms: map[string][]string{
+			"ии": {"искусственный интеллект"},
+			"искусственный интеллект": {"ии"},
+		},
+	})
+	if err := aliases.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	return aliases
+}
+
+func TestAliasesExpand(t *testing.T) {
+	aliases := newTestAliases(t)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"искусственный интелент", `(искусственный AND интеллект) OR ии`},
+		{"гугл -реклама", "google AND NOT реклама"},
+		{"ии", `ии OR "искусственный интеллект"`},
+		{"apple", "apple"},
+	}
+	for _, tt := range tests {
+		expr, err := query.Parse(tt.input)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.input, err)
+		}
+		if got := aliases.Expand(tt.input, expr).String(); got != tt.want {
+			t.Errorf("Expand(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestAliasesSuggest(t *testing.T) {
+	aliases := newTestAliases(t)
+
+	if got := aliases.Suggest("Искусственный интелент"); got != "искусственный интеллект" {
+		t.Errorf("Suggest() = %q, want the corrected topic", got)
+	}
+	if got := aliases.Suggest("новости гугл"); got != "новости google" {
+		t.Errorf("Suggest() = %q, want a per-word correction", got)
+	}
+	if got := aliases.Suggest("apple"); got != "" {
+		t.Errorf("Suggest() = %q, want no suggestion", got)
+	}
+}
+
+func TestSearchUsesAliases(t *testing.T) {
+	provider := &stubProvider{name: "boolean", boolean: true, articles: []fetcher.Article{{Title: "Новость", URL: "https://example.com/1"}}}
+	f := fetcher.NewFetcher(newRegistry(t, provider))
+	f.SetAliases(newTestAliases(t))
+
+	if _, err := f.FetchNews(context.Background(), "гугл"); err != nil {
+		t.Fatalf("FetchNews() error = %v", err)
+	}
+	if got := provider.lastReq.Expr.String(); got != "google" {
+		t.Errorf("Provider expression = %q, want the corrected topic", got)
+	}
+}
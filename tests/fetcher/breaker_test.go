@@ -0,0 +1,192 @@
+package fetcher_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
+	"gorm.io/gorm"
+)
+
+func TestCircuitBreakerStates(t *testing.T) {
+	breaker := fetcher.NewCircuitBreaker(fetcher.BreakerConfig{FailureThreshold: 2, Cooldown: 20 * time.Millisecond})
+
+	breaker.Failure()
+	if !breaker.Allow() {
+		t.Fatal("Breaker should stay closed below the failure threshold")
+	}
+	breaker.Failure()
+	if breaker.State() != fetcher.BreakerOpen || breaker.Allow() {
+		t.Fatalf("State() = %s, want open after consecutive failures", breaker.State())
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if breaker.State() != fetcher.BreakerHalfOpen {
+		t.Fatalf("State() = %s, want half-open after cooldown", breaker.State())
+	}
+	if !breaker.Allow() {
+		t.Fatal("Half-open breaker should allow a probe request")
+	}
+	if breaker.Allow() {
+		t.Error("Half-open breaker should allow only one probe at a time")
+	}
+
+	// Неудачная проба снова размыкает выключатель, удачная - замыкает
+	breaker.Failure()
+	if breaker.State() != fetcher.BreakerOpen {
+		t.Fatalf("State() = %s, want open after failed probe", breaker.State())
+	}
+	time.Sleep(30 * time.Millisecond)
+	breaker.Allow()
+	breaker.Success()
+	if breaker.State() != fetcher.BreakerClosed {
+		t.Errorf("State() = %s, want closed after successful probe", breaker.State())
+	}
+}
+
+func TestFetcherSkipsOpenBreaker(t *testing.T) {
+	failing := &stubProvider{name: "failing", err: errors.New("timeout")}
+	backup := &stubProvider{name: "backup", articles: []fetcher.Article{{Title: "Новость"}}}
+	f := fetcher.NewFetcher(newRegistry(t, failing, backup))
+	f.SetBreakerConfig(fetcher.BreakerConfig{FailureThreshold: 2, Cooldown: time.Hour})
+
+	for i := 0; i < 4; i++ {
+		if _, err := f.FetchNews(context.Background(), "политика"); err != nil {
+			t.Fatalf("FetchNews() error = %v", err)
+		}
+	}
+	if failing.calls != 2 {
+		t.Errorf("Failing provider called %d times, want 2 before the breaker opens", failing.calls)
+	}
+	if health := f.ProviderHealth(); health[0].Breaker != fetcher.BreakerOpen {
+		t.Errorf("ProviderHealth()[0].Breaker = %s, want open", health[0].Breaker)
+	}
+}
+
+func TestFetcherHealthCheck(t *testing.T) {
+	failing := &stubProvider{name: "failing", err: errors.New("timeout")}
+	f := fetcher.NewFetcher(newRegistry(t, failing))
+	f.SetBreakerConfig(fetcher.BreakerConfig{FailureThreshold: 1, Cooldown: time.Hour})
+
+	if err := f.HealthCheck(context.Background()); err != nil {
+		t.Fatalf("HealthCheck() error = %v, want nil while breakers are closed", err)
+	}
+	f.FetchNews(context.Background(), "политика")
+	if err := f.HealthCheck(context.Background()); !errors.Is(err, fetcher.ErrCircuitOpen) {
+		t.Errorf("HealthCheck() error = %v, want ErrCircuitOpen when every breaker is open", err)
+	}
+}
+
+func TestFetcherQuotaExhausted(t *testing.T) {
+	db, err := gorm.Open(database.NewSQLiteDialector(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&database.ProviderQuota{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	store := database.NewProviderQuotaRepository(db)
+
+	limited := &stubProvider{name: "limited", articles: []fetcher.Article{{Title: "Новость"}}}
+	// Провайдер, израсходовавший дневной лимит, отвечает ошибкой до полуночи UTC
+	rejecting := &stubProvider{name: "rejecting", err: &fetcher.RateLimitError{Provider: "Test API", ResetAt: time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)}}
+	f := fetcher.NewFetcher(newRegistry(t, rejecting, limited))
+	quota := fetcher.NewQuota(map[string]int{"limited": 1}, store)
+	f.SetQuota(quota)
+
+	if _, err := f.FetchNews(context.Background(), "политика"); err != nil {
+		t.Fatalf("First FetchNews() error = %v", err)
+	}
+
+	_, err = f.FetchNews(context.Background(), "экономика")
+	var quotaErr *fetcher.QuotaExhaustedError
+	if !errors.As(err, &quotaErr) {
+		t.Fatalf("FetchNews() error = %v, want *fetcher.QuotaExhaustedError", err)
+	}
+	if !quotaErr.ResetAt.After(time.Now()) || quotaErr.ResetAt.UTC().Hour() != 0 {
+		t.Errorf("ResetAt = %s, want next midnight UTC", quotaErr.ResetAt)
+	}
+	if limited.calls != 1 || rejecting.calls != 1 {
+		t.Errorf("Provider calls = %d/%d, want 1/1: exhausted providers must be skipped", limited.calls, rejecting.calls)
+	}
+
+	// При остановке бот сохраняет накопленный расход; новый счетчик (как после перезапуска) читает его из БД
+	if err := quota.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	restored := fetcher.NewQuota(map[string]int{"limited": 1}, store)
+	if ok, _ := restored.Allow("limited"); ok {
+		t.Error("Restored quota should keep the exhausted limit")
+	}
+	if ok, _ := restored.Allow("rejecting"); ok {
+		t.Error("Restored quota should keep the provider-reported exhaustion")
+	}
+}
+
+// countingQuotaStore считает сохранения квоты.
+type countingQuotaStore struct {
+	mu    sync.Mutex
+	saves int
+	used  map[string]int
+}
+
+func (s *countingQuotaStore) LoadProviderQuota(ctx context.Context, provider, day string) (int, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.used[provider], false, nil
+}
+
+func (s *countingQuotaStore) SaveProviderQuota(ctx context.Context, provider, day string, used int, exhausted bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saves++
+	s.used[provider] = used
+	return nil
+}
+
+func TestQuotaBatchesSaves(t *testing.T) {
+	store := &countingQuotaStore{used: make(map[string]int)}
+	quota := fetcher.NewQuota(map[string]int{"gnews": 100}, store)
+
+	for i := 0; i < 10; i++ {
+		quota.Record("gnews")
+	}
+	// Первый запрос сохраняется сразу, остальные копятся в памяти до следующего сохранения
+	if store.saves != 1 {
+		t.Errorf("saves after 10 requests = %d, want 1", store.saves)
+	}
+	if err := quota.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if store.used["gnews"] != 10 {
+		t.Errorf("saved usage = %d, want 10", store.used["gnews"])
+	}
+	// Без новых запросов сохранять нечего
+	saves := store.saves
+	if err := quota.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if store.saves != saves {
+		t.Errorf("saves after idle Flush() = %d, want %d", store.saves, saves)
+	}
+}
+
+func TestQuotaSkipsCancelledRequests(t *testing.T) {
+	f := fetcher.NewFetcher(newRegistry(t, &blockingProvider{started: make(chan struct{})}))
+	quota := fetcher.NewQuota(map[string]int{"blocking": 10}, nil)
+	f.SetQuota(quota)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := f.FetchNews(ctx, "политика"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("FetchNews() error = %v, want context.DeadlineExceeded", err)
+	}
+	// Провайдер не ответил, поэтому запрос не расходует квоту
+	if used := quota.Usage("blocking").Used; used != 0 {
+		t.Errorf("quota used = %d, want 0 for a cancelled request", used)
+	}
+}
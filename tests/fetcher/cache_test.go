@@ -0,0 +1,116 @@
+package fetcher_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
+	"gorm.io/gorm"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	if got := fetcher.NormalizeQuery("  Искусственный   ИНТЕЛЛЕКТ "); got != "искусственный интеллект" {
+		t.Errorf("NormalizeQuery() = %q", got)
+	}
+}
+
+func TestCacheLRUEviction(t *testing.T) {
+	cache := fetcher.NewCache(time.Hour, 2, nil)
+	cache.Set(context.Background(), "a", []fetcher.Article{{Title: "A"}})
+	cache.Set(context.Background(), "b", []fetcher.Article{{Title: "B"}})
+
+	// Обращение к "a" делает "b" самой старой записью
+	if _, ok := cache.Get(context.Background(), "a"); !ok {
+		t.Fatal("Get(a) should hit")
+	}
+	cache.Set(context.Background(), "c", []fetcher.Article{{Title: "C"}})
+
+	if _, ok := cache.Get(context.Background(), "b"); ok {
+		t.Error("Least recently used entry should be evicted")
+	}
+	if _, ok := cache.Get(context.Background(), "a"); !ok {
+		t.Error("Recently used entry should stay in cache")
+	}
+
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 || stats.Entries != 2 {
+		t.Errorf("Stats() = %+v, want 2 hits, 1 miss, 2 entries", stats)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	cache := fetcher.NewCache(20*time.Millisecond, 10, nil)
+	cache.Set(context.Background(), "topic", []fetcher.Article{{Title: "Old"}})
+	time.Sleep(30 * time.Millisecond)
+
+	if _, ok := cache.Get(context.Background(), "topic"); ok {
+		t.Error("Expired entry should not be returned")
+	}
+}
+
+func TestCachePersistentStore(t *testing.T) {
+	db, err := gorm.Open(database.NewSQLiteDialector(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&database.CachedResult{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	store := database.NewCacheRepository(db)
+
+	fetcher.NewCache(time.Hour, 10, store).Set(context.Background(), "политика", []fetcher.Article{{Title: "Сохраненная", URL: "https://example.com/1"}})
+
+	// Новый экземпляр кэша (как после перезапуска) читает запись из БД
+	restored := fetcher.NewCache(time.Hour, 10, store)
+	articles, ok := restored.Get(context.Background(), "политика")
+	if !ok || len(articles) != 1 || articles[0].Title != "Сохраненная" {
+		t.Fatalf("Get() = %v, %v; want article restored from store", articles, ok)
+	}
+	if stats := restored.Stats(); stats.StoreHits != 1 {
+		t.Errorf("StoreHits = %d, want 1", stats.StoreHits)
+	}
+}
+
+func TestCachePrunesExpiredStoreEntries(t *testing.T) {
+	db, err := gorm.Open(database.NewSQLiteDialector(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&database.CachedResult{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+	store := database.NewCacheRepository(db)
+	ctx := context.Background()
+
+	// Запись от давно выполненного поискового запроса
+	if err := store.SaveCachedResult(ctx, "старый запрос", []byte("[]"), time.Now().Add(-2*time.Hour)); err != nil {
+		t.Fatalf("SaveCachedResult() error = %v", err)
+	}
+
+	fetcher.NewCache(time.Hour, 10, store).Set(ctx, "новый запрос", []fetcher.Article{{Title: "Свежая"}})
+
+	var keys []string
+	if err := db.Unscoped().Model(&database.CachedResult{}).Order("key").Pluck("key", &keys).Error; err != nil {
+		t.Fatalf("Failed to read cached results: %v", err)
+	}
+	if len(keys) != 1 || keys[0] != "новый запрос" {
+		t.Errorf("Stored keys = %v, want only the fresh entry", keys)
+	}
+}
+
+func TestFetchNewsUsesCache(t *testing.T) {
+	provider := &stubProvider{name: "stub", articles: []fetcher.Article{{Title: "Новость"}}}
+	f := fetcher.NewFetcher(newRegistry(t, provider))
+	f.SetCache(fetcher.NewCache(time.Hour, 10, nil))
+
+	for _, query := range []string{"Политика", "политика ", "  ПОЛИТИКА"} {
+		if _, err := f.FetchNews(context.Background(), query); err != nil {
+			t.Fatalf("FetchNews(%q) error = %v", query, err)
+		}
+	}
+	if provider.calls != 1 {
+		t.Errorf("Provider should be called once for equivalent queries, got %d calls", provider.calls)
+	}
+}
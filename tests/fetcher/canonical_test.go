@@ -0,0 +1,53 @@
+package fetcher_test
+
+import (
+	"testing"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"https://example.com/news/1", "https://example.com/news/1"},
+		{"http://WWW.Example.com/news/1/", "https://example.com/news/1"},
+		{"https://example.com/news/1?utm_source=gnews&utm_medium=rss#comments", "https://example.com/news/1"},
+		{"https://example.com/news/1?id=5&fbclid=abc&a=1", "https://example.com/news/1?a=1&id=5"},
+		{"https://m.example.com/news/1/amp", "https://example.com/news/1"},
+		{"https://amp.example.com/amp/news/1", "https://example.com/news/1"},
+		{"https://example.com/news/1.amp.html", "https://example.com/news/1.html"},
+		{"https://example.com:443/news/1", "https://example.com/news/1"},
+		{"https://example.com:8080/news/1", "https://example.com:8080/news/1"},
+		{"not a url", "not a url"},
+	}
+
+	for _, tt := range tests {
+		if got := fetcher.CanonicalURL(tt.raw); got != tt.want {
+			t.Errorf("CanonicalURL(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestArticleKey(t *testing.T) {
+	key := fetcher.ArticleKey("https://example.com/news/1")
+	if len(key) != 64 {
+		t.Fatalf("ArticleKey() length = %d, want 64", len(key))
+	}
+
+	variants := []string{
+		"http://www.example.com/news/1",
+		"https://example.com/news/1/?utm_campaign=daily",
+		"https://example.com/news/1/amp#top",
+	}
+	for _, variant := range variants {
+		if got := fetcher.ArticleKey(variant); got != key {
+			t.Errorf("ArticleKey(%q) differs from the canonical article key", variant)
+		}
+	}
+
+	if fetcher.ArticleKey("https://example.com/news/2") == key {
+		t.Error("Different articles must have different keys")
+	}
+}
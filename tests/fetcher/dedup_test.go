@@ -0,0 +1,67 @@
+package fetcher_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
+)
+
+func newsArticle(title, description, source string, publishedAt time.Time) fetcher.Article {
+	return fetcher.Article{
+		Title:       title,
+		Description: description,
+		PublishedAt: publishedAt,
+		Source:      fetcher.Source{Name: source},
+	}
+}
+
+func TestClusterNearDuplicates(t *testing.T) {
+	now := time.Date(2024, 9, 13, 12, 0, 0, 0, time.UTC)
+	articles := []fetcher.Article{
+		newsArticle("Центробанк повысил ключевую ставку до 18% - РБК", "Банк России на заседании в пятницу повысил ключевую ставку на 100 базисных пунктов", "РБК", now),
+		newsArticle("Apple unveils new iPhone 16 with AI features", "Apple on Monday announced the iPhone 16 lineup with Apple Intelligence", "Reuters", now),
+		newsArticle("ЦБ повысил ключевую ставку до 18%", "Совет директоров Банка России повысил ключевую ставку до 18% годовых", "Коммерсант", now.Add(time.Hour)),
+		newsArticle("Apple unveils iPhone 16 with new AI features - The Verge", "The iPhone 16 lineup was announced on Monday with Apple Intelligence", "The Verge", now),
+		newsArticle("Apple shares fall after iPhone event", "Investors were unimpressed by the latest product launch", "Bloomberg", now),
+		newsArticle("Курс доллара упал ниже 90 рублей", "На Московской бирже доллар подешевел", "РБК", now),
+	}
+
+	got := fetcher.ClusterNearDuplicates(articles, 48*time.Hour)
+	want := [][]int{{0, 2}, {1, 3}, {4}, {5}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ClusterNearDuplicates() = %v, want %v", got, want)
+	}
+}
+
+func TestClusterNearDuplicatesWindow(t *testing.T) {
+	now := time.Date(2024, 9, 13, 12, 0, 0, 0, time.UTC)
+	articles := []fetcher.Article{
+		newsArticle("Центробанк повысил ключевую ставку до 18%", "", "РБК", now),
+		newsArticle("Центробанк повысил ключевую ставку до 18%", "", "ТАСС", now.Add(72*time.Hour)),
+	}
+
+	if got := fetcher.ClusterNearDuplicates(articles, 48*time.Hour); len(got) != 2 {
+		t.Errorf("ClusterNearDuplicates() = %v, want separate clusters outside the window", got)
+	}
+}
+
+func TestMergeDuplicates(t *testing.T) {
+	representative := newsArticle("ЦБ повысил ставку", "", "РБК", time.Time{})
+	duplicates := []fetcher.Article{
+		newsArticle("ЦБ повысил ставку", "", "ТАСС", time.Time{}),
+		newsArticle("ЦБ повысил ставку", "", "рбк", time.Time{}),
+		newsArticle("ЦБ повысил ставку", "", "", time.Time{}),
+		newsArticle("ЦБ повысил ставку", "", "ТАСС", time.Time{}),
+		newsArticle("ЦБ повысил ставку", "", "Интерфакс", time.Time{}),
+	}
+
+	merged := fetcher.MergeDuplicates(representative, duplicates)
+	if want := []string{"ТАСС", "Интерфакс"}; !reflect.DeepEqual(merged.AlsoReportedBy, want) {
+		t.Errorf("AlsoReportedBy = %v, want %v", merged.AlsoReportedBy, want)
+	}
+	if merged.Source.Name != "РБК" {
+		t.Errorf("Representative source = %q, want РБК", merged.Source.Name)
+	}
+}
@@ -0,0 +1,127 @@
+package fetcher_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/pkg/metrics"
+)
+
+const testAPIKey = "secret-api-key"
+
+// roundTripFunc подменяет HTTP-транспорт заранее заданным ответом.
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// stubResponse возвращает клиент, который на любой запрос отвечает status и body.
+func stubResponse(status int, body string, header http.Header) *http.Client {
+	return &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			StatusCode: status,
+			Status:     http.StatusText(status),
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}, nil
+	})}
+}
+
+func TestProviderErrorCategories(t *testing.T) {
+	body := `{"status":"error","code":"%s","message":"details with ` + testAPIKey + `"}`
+	tests := []struct {
+		name     string
+		provider fetcher.NewsProvider
+		want     error
+	}{
+		{"gnews unauthorized", fetcher.NewGNewsProvider(testAPIKey, stubResponse(http.StatusUnauthorized, `{"errors":["bad key"]}`, nil)), fetcher.ErrUnauthorized},
+		{"gnews daily quota", fetcher.NewGNewsProvider(testAPIKey, stubResponse(http.StatusForbidden, `{"errors":["limit"]}`, nil)), fetcher.ErrRateLimited},
+		{"gnews bad request", fetcher.NewGNewsProvider(testAPIKey, stubResponse(http.StatusBadRequest, `{}`, nil)), fetcher.ErrBadQuery},
+		{"gnews server error", fetcher.NewGNewsProvider(testAPIKey, stubResponse(http.StatusServiceUnavailable, `oops`, nil)), fetcher.ErrUpstreamUnavailable},
+		{"gnews broken json", fetcher.NewGNewsProvider(testAPIKey, stubResponse(http.StatusOK, `{`, nil)), fetcher.ErrUpstreamUnavailable},
+		{"newsapi invalid key", fetcher.NewNewsAPIProvider(testAPIKey, stubResponse(http.StatusUnauthorized, strings.Replace(body, "%s", "apiKeyInvalid", 1), nil)), fetcher.ErrUnauthorized},
+		{"newsapi exhausted key", fetcher.NewNewsAPIProvider(testAPIKey, stubResponse(http.StatusTooManyRequests, strings.Replace(body, "%s", "apiKeyExhausted", 1), nil)), fetcher.ErrRateLimited},
+		{"newsapi bad parameter", fetcher.NewNewsAPIProvider(testAPIKey, stubResponse(http.StatusBadRequest, strings.Replace(body, "%s", "parameterInvalid", 1), nil)), fetcher.ErrBadQuery},
+		{"newsapi missing key", fetcher.NewNewsAPIProvider("", nil), fetcher.ErrUnauthorized},
+		{"rss unavailable", fetcher.NewRSSProvider([]string{"https://example.com/feed"}, stubResponse(http.StatusBadGateway, "", nil)), fetcher.ErrUpstreamUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.provider.Search(context.Background(), fetcher.SearchRequest{Query: "политика"})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Search() error = %v, want %v", err, tt.want)
+			}
+			if strings.Contains(err.Error(), testAPIKey) || strings.Contains(err.Error(), "details") {
+				t.Errorf("Error %q must not contain the API key or the response body", err)
+			}
+		})
+	}
+}
+
+func TestRateLimitResetTime(t *testing.T) {
+	provider := fetcher.NewNewsAPIProvider(testAPIKey, stubResponse(http.StatusTooManyRequests, `{"code":"rateLimited"}`, http.Header{"Retry-After": []string{"120"}}))
+	_, err := provider.Search(context.Background(), fetcher.SearchRequest{Query: "политика"})
+
+	var rateErr *fetcher.RateLimitError
+	if !errors.As(err, &rateErr) {
+		t.Fatalf("Search() error = %v, want *fetcher.RateLimitError", err)
+	}
+	if until := time.Until(rateErr.ResetAt); until < time.Minute || until > 3*time.Minute {
+		t.Errorf("ResetAt in %s, want about 2 minutes from Retry-After", until)
+	}
+
+	gnews := fetcher.NewGNewsProvider(testAPIKey, stubResponse(http.StatusForbidden, `{}`, nil))
+	_, err = gnews.Search(context.Background(), fetcher.SearchRequest{Query: "политика"})
+	if !errors.As(err, &rateErr) || rateErr.ResetAt.UTC().Hour() != 0 || !rateErr.ResetAt.After(time.Now()) {
+		t.Errorf("GNews quota error = %v, want reset at next midnight UTC", err)
+	}
+}
+
+func TestTransportErrorHidesAPIKey(t *testing.T) {
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection refused")
+	})}
+
+	_, err := fetcher.NewGNewsProvider(testAPIKey, client).Search(context.Background(), fetcher.SearchRequest{Query: "политика"})
+	if !errors.Is(err, fetcher.ErrUpstreamUnavailable) {
+		t.Fatalf("Search() error = %v, want ErrUpstreamUnavailable", err)
+	}
+	if strings.Contains(err.Error(), testAPIKey) {
+		t.Errorf("Error %q must not contain the request URL with the API key", err)
+	}
+}
+
+func TestProviderErrorsMetric(t *testing.T) {
+	f := fetcher.NewFetcher(newRegistry(t,
+		fetcher.NewGNewsProvider(testAPIKey, stubResponse(http.StatusServiceUnavailable, `oops`, nil)),
+		&stubProvider{name: "limited", err: &fetcher.RateLimitError{Provider: "limited", ResetAt: time.Now().Add(time.Minute)}},
+	))
+	if _, err := f.FetchNews(context.Background(), "политика"); err == nil {
+		t.Fatal("FetchNews() should fail when every provider fails")
+	}
+
+	var out strings.Builder
+	if err := metrics.Default.WriteText(&out); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	for _, series := range []string{
+		`newsbot_provider_errors_total{provider="gnews",status="503"}`,
+		`newsbot_provider_errors_total{provider="limited",status="rate_limited"}`,
+	} {
+		if !strings.Contains(out.String(), series) {
+			t.Errorf("Metrics output lacks %s:\n%s", series, out.String())
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package fetcher_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
+)
+
+// stubProvider - тестовый провайдер с заранее заданным ответом.
+type stubProvider struct {
+	name     string
+	articles []fetcher.Article
+	err      error
+	calls    int
+	boolean  bool
+	lastReq  fetcher.SearchRequest
+}
+
+func (p *stubProvider) Name() string { return p.name }
+
+func (p *stubProvider) Capabilities() fetcher.Capabilities {
+	return fetcher.Capabilities{MaxResults: 10, BooleanQueries: p.boolean}
+}
+
+func (p *stubProvider) Search(ctx context.Context, req fetcher.SearchRequest) ([]fetcher.Article, error) {
+	p.calls++
+	p.lastReq = req
+	return p.articles, p.err
+}
+
+func newRegistry(t *testing.T, providers ...fetcher.NewsProvider) *fetcher.Registry {
+	t.Helper()
+	registry := fetcher.NewRegistry()
+	for _, p := range providers {
+		if err := registry.Register(p); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+	}
+	return registry
+}
+
+func TestRegistryConfigure(t *testing.T) {
+	first := &stubProvider{name: "first"}
+	second := &stubProvider{name: "second"}
+	third := &stubProvider{name: "third"}
+	registry := newRegistry(t, first, second, third)
+
+	if err := registry.Configure([]string{"third", " First "}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+
+	enabled := registry.Enabled()
+	if len(enabled) != 2 || enabled[0].Name() != "third" || enabled[1].Name() != "first" {
+		t.Fatalf("Enabled() returned unexpected order: %v", providerNames(enabled))
+	}
+	if registry.IsEnabled("second") {
+		t.Error("Provider missing from configuration should be disabled")
+	}
+
+	if err := registry.SetEnabled("second", true); err != nil {
+		t.Fatalf("SetEnabled() error = %v", err)
+	}
+	if got := providerNames(registry.Enabled()); len(got) != 3 || got[2] != "second" {
+		t.Errorf("Re-enabled provider should be appended to the chain, got %v", got)
+	}
+
+	if err := registry.Configure([]string{"unknown"}); err == nil {
+		t.Error("Configure() should fail for an unknown provider")
+	}
+	if err := registry.Register(&stubProvider{name: "FIRST"}); err == nil {
+		t.Error("Register() should reject duplicate names")
+	}
+}
+
+func TestFetchNewsFallback(t *testing.T) {
+	failing := &stubProvider{name: "failing", err: errors.New("boom")}
+	empty := &stubProvider{name: "empty"}
+	working := &stubProvider{name: "working", articles: []fetcher.Article{{Title: "Новость", URL: "https://example.com/1"}}}
+
+	f := fetcher.NewFetcher(newRegistry(t, failing, empty, working))
+	articles, err := f.FetchNews(context.Background(), "политика")
+	if err != nil {
+		t.Fatalf("FetchNews() error = %v", err)
+	}
+	if len(articles) != 1 {
+		t.Fatalf("FetchNews() returned %d articles, want 1", len(articles))
+	}
+	if f.LastAPIUsed() != "working" {
+		t.Errorf("LastAPIUsed() = %q, want %q", f.LastAPIUsed(), "working")
+	}
+	if failing.calls != 1 || empty.calls != 1 || working.calls != 1 {
+		t.Errorf("Each provider should be called once, got %d/%d/%d", failing.calls, empty.calls, working.calls)
+	}
+}
+
+func TestFetchNewsAllProvidersFail(t *testing.T) {
+	f := fetcher.NewFetcher(newRegistry(t,
+		&stubProvider{name: "a", err: errors.New("first failure")},
+		&stubProvider{name: "b", err: errors.New("second failure")},
+	))
+
+	if _, err := f.FetchNews(context.Background(), "спорт"); err == nil {
+		t.Fatal("FetchNews() should fail when every provider fails")
+	}
+
+	// Успешный, но пустой ответ не считается ошибкой
+	f = fetcher.NewFetcher(newRegistry(t,
+		&stubProvider{name: "a"},
+		&stubProvider{name: "b", err: errors.New("failure")},
+	))
+	articles, err := f.FetchNews(context.Background(), "спорт")
+	if err != nil || len(articles) != 0 {
+		t.Errorf("FetchNews() = %v, %v; want empty result without error", articles, err)
+	}
+}
+
+// blockingProvider ждет отмены контекста, как зависший HTTP-запрос.
+type blockingProvider struct {
+	started chan struct{}
+}
+
+func (p *blockingProvider) Name() string { return "blocking" }
+
+func (p *blockingProvider) Capabilities() fetcher.Capabilities {
+	return fetcher.Capabilities{MaxResults: 10}
+}
+
+func (p *blockingProvider) Search(ctx context.Context, req fetcher.SearchRequest) ([]fetcher.Article, error) {
+	close(p.started)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestFetchNewsCancelled(t *testing.T) {
+	blocking := &blockingProvider{started: make(chan struct{})}
+	next := &stubProvider{name: "next", articles: []fetcher.Article{{Title: "Новость", URL: "https://example.com/1"}}}
+	f := fetcher.NewFetcher(newRegistry(t, blocking, next))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-blocking.started
+		cancel()
+	}()
+
+	if _, err := f.FetchNews(ctx, "политика"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("FetchNews() error = %v, want context.Canceled", err)
+	}
+	if next.calls != 0 {
+		t.Errorf("Next provider called %d times after cancellation, want 0", next.calls)
+	}
+	// Отмена не считается сбоем провайдера
+	if health := f.ProviderHealth(); health[0].Breaker != fetcher.BreakerClosed {
+		t.Errorf("Breaker state after cancellation = %v, want closed", health[0].Breaker)
+	}
+}
+
+func providerNames(providers []fetcher.NewsProvider) []string {
+	names := make([]string, 0, len(providers))
+	for _, p := range providers {
+		names = append(names, p.Name())
+	}
+	return names
+}
+
+func TestSearchBooleanQuery(t *testing.T) {
+	articles := []fetcher.Article{
+		{Title: "Apple выпустила iPhone", URL: "https://example.com/1"},
+		{Title: "Apple: рецепт пирога", Description: "Сезонный fruit", URL: "https://example.com/2"},
+		{Title: "Google представила Pixel", URL: "https://example.com/3"},
+	}
+
+	// Провайдер без поддержки операторов получает ключевые слова, а ответ фильтруется локально
+	keywords := &stubProvider{name: "keywords", articles: articles}
+	got, err := fetcher.NewFetcher(newRegistry(t, keywords)).FetchNews(context.Background(), "(apple OR google) -fruit")
+	if err != nil {
+		t.Fatalf("FetchNews() error = %v", err)
+	}
+	if keywords.lastReq.Query != "apple google" {
+		t.Errorf("Provider query = %q, want keywords only", keywords.lastReq.Query)
+	}
+	if len(got) != 2 || got[0].URL != "https://example.com/1" || got[1].URL != "https://example.com/3" {
+		t.Errorf("Unexpected filtered articles: %+v", got)
+	}
+
+	// Провайдер с поддержкой операторов получает разобранный запрос без локальной фильтрации
+	boolean := &stubProvider{name: "boolean", articles: articles, boolean: true}
+	got, err = fetcher.NewFetcher(newRegistry(t, boolean)).FetchNews(context.Background(), "(apple OR google) -fruit")
+	if err != nil {
+		t.Fatalf("FetchNews() error = %v", err)
+	}
+	if boolean.lastReq.Expr == nil || boolean.lastReq.Expr.String() != "(apple OR google) AND NOT fruit" {
+		t.Errorf("Provider expression = %v", boolean.lastReq.Expr)
+	}
+	if len(got) != len(articles) {
+		t.Errorf("Boolean provider results must not be filtered, got %d", len(got))
+	}
+
+	if _, err := fetcher.NewFetcher(newRegistry(t, boolean)).FetchNews(context.Background(), `"unterminated`); err == nil {
+		t.Error("FetchNews() should reject an invalid query")
+	}
+}
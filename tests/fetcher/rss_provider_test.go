@@ -0,0 +1,181 @@
+package fetcher_test
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
+	"golang.org/x/text/encoding/charmap"
+)
+
+const testRSSFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0">
+  <channel>
+    <title>Тестовые новости</title>
+    <link>https://news.example.com</link>
+    <item>
+      <title>Искусственный интеллект помог врачам</title>
+      <description><![CDATA[<p>Нейросеть &laquo;нашла&raquo; опухоль</p>]]></description>
+      <link>https://news.example.com/ai</link>
+      <pubDate>Mon, 06 Jan 2025 10:00:00 +0300</pubDate>
+      <enclosure url="https://news.example.com/ai.jpg" type="image/jpeg" length="1024"/>
+    </item>
+    <item>
+      <title>Погода на выходные</title>
+      <description>Ожидается снег</description>
+      <link>https://news.example.com/weather</link>
+      <pubDate>Mon, 06 Jan 2025 09:00:00 +0300</pubDate>
+    </item>
+  </channel>
+</rss>`
+
+const testAtomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>Atom-лента</title>
+  <link href="https://atom.example.com/"/>
+  <entry>
+    <title>Регулирование: искусственный интеллект под контролем</title>
+    <link rel="alternate" href="https://atom.example.com/regulation"/>
+    <link rel="enclosure" type="image/png" href="https://atom.example.com/regulation.png"/>
+    <id>urn:uuid:1</id>
+    <updated>2025-01-07T08:30:00Z</updated>
+    <summary>Новые правила для разработчиков</summary>
+  </entry>
+</feed>`
+
+func newFeedServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rss", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		_, _ = w.Write([]byte(testRSSFeed))
+	})
+	mux.HandleFunc("/atom", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/atom+xml")
+		_, _ = w.Write([]byte(testAtomFeed))
+	})
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRSSProviderSearch(t *testing.T) {
+	server := newFeedServer(t)
+	provider := fetcher.NewRSSProvider(
+		[]string{server.URL + "/rss", server.URL + "/atom", server.URL + "/broken"},
+		server.Client(),
+	)
+
+	articles, err := provider.Search(context.Background(), fetcher.SearchRequest{Query: "Искусственный Интеллект"})
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(articles) != 2 {
+		t.Fatalf("Search() returned %d articles, want 2", len(articles))
+	}
+
+	// Статьи отсортированы от новых к старым: первой идет запись из Atom-ленты
+	atom := articles[0]
+	if atom.URL != "https://atom.example.com/regulation" {
+		t.Errorf("Atom article URL = %q", atom.URL)
+	}
+	if atom.Image != "https://atom.example.com/regulation.png" {
+		t.Errorf("Atom article image = %q", atom.Image)
+	}
+	if atom.Source.Name != "Atom-лента" {
+		t.Errorf("Atom article source = %q", atom.Source.Name)
+	}
+	if !atom.PublishedAt.Equal(time.Date(2025, 1, 7, 8, 30, 0, 0, time.UTC)) {
+		t.Errorf("Atom article published at %v", atom.PublishedAt)
+	}
+
+	rss := articles[1]
+	if rss.Title != "Искусственный интеллект помог врачам" {
+		t.Errorf("RSS article title = %q", rss.Title)
+	}
+	if rss.Description != "Нейросеть «нашла» опухоль" {
+		t.Errorf("RSS article description should be stripped of HTML, got %q", rss.Description)
+	}
+	if rss.Image != "https://news.example.com/ai.jpg" {
+		t.Errorf("RSS article image = %q", rss.Image)
+	}
+	if rss.Source.Name != "Тестовые новости" || rss.Source.URL != "https://news.example.com" {
+		t.Errorf("RSS article source = %+v", rss.Source)
+	}
+	if rss.PublishedAt.IsZero() {
+		t.Error("RSS article pubDate should be parsed")
+	}
+}
+
+func TestRSSProviderAllFeedsFail(t *testing.T) {
+	server := newFeedServer(t)
+	provider := fetcher.NewRSSProvider([]string{server.URL + "/broken"}, server.Client())
+
+	if _, err := provider.Search(context.Background(), fetcher.SearchRequest{Query: "новости"}); err == nil {
+		t.Error("Search() should fail when no feed could be loaded")
+	}
+}
+
+func TestParseFeedAtomSelfLink(t *testing.T) {
+	// atom:link после обычной ссылки не должен ее затирать
+	feed := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom">
+  <channel>
+    <title>Лента</title>
+    <link>https://news.example.com</link>
+    <atom:link href="https://news.example.com/rss" rel="self" type="application/rss+xml"/>
+    <item>
+      <title>Новость</title>
+      <link>https://news.example.com/1</link>
+      <atom:link href="https://news.example.com/1/amp" rel="amphtml"/>
+    </item>
+  </channel>
+</rss>`
+
+	articles, err := fetcher.ParseFeed(strings.NewReader(feed))
+	if err != nil {
+		t.Fatalf("ParseFeed() error = %v", err)
+	}
+	if len(articles) != 1 {
+		t.Fatalf("ParseFeed() = %d articles, want 1", len(articles))
+	}
+	if articles[0].URL != "https://news.example.com/1" {
+		t.Errorf("URL = %q, want item link", articles[0].URL)
+	}
+	if articles[0].Source.URL != "https://news.example.com" {
+		t.Errorf("Source.URL = %q, want channel link", articles[0].Source.URL)
+	}
+}
+
+func TestParseFeedCharsets(t *testing.T) {
+	for _, tc := range []struct {
+		charset string
+		encoder *charmap.Charmap
+	}{
+		{"windows-1251", charmap.Windows1251},
+		{"koi8-r", charmap.KOI8R},
+	} {
+		feed := `<?xml version="1.0" encoding="` + tc.charset + `"?>
+<rss version="2.0"><channel><title>Лента</title><item><title>Новости дня</title><link>https://news.example.com/1</link></item></channel></rss>`
+		encoded, err := tc.encoder.NewEncoder().String(feed)
+		if err != nil {
+			t.Fatalf("%s: failed to encode feed: %v", tc.charset, err)
+		}
+
+		articles, err := fetcher.ParseFeed(bytes.NewReader([]byte(encoded)))
+		if err != nil {
+			t.Fatalf("%s: ParseFeed() error = %v", tc.charset, err)
+		}
+		if len(articles) != 1 || articles[0].Title != "Новости дня" || articles[0].Source.Name != "Лента" {
+			t.Errorf("%s: ParseFeed() = %+v, want decoded title and source", tc.charset, articles)
+		}
+	}
+}
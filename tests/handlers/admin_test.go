@@ -0,0 +1,175 @@
+package handlers_test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/handlers"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/sender"
+	"gorm.io/gorm"
+)
+
+const adminID = 42
+
+// recordingAPI запоминает отправленные сообщения и правки сообщений.
+type recordingAPI struct {
+	mu       sync.Mutex
+	messages []tgbotapi.MessageConfig
+	edits    []tgbotapi.EditMessageTextConfig
+}
+
+func (a *recordingAPI) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	switch v := c.(type) {
+	case tgbotapi.MessageConfig:
+		a.messages = append(a.messages, v)
+	case tgbotapi.EditMessageTextConfig:
+		a.edits = append(a.edits, v)
+	}
+	return &tgbotapi.APIResponse{Ok: true, Result: []byte(`{"message_id": 1}`)}, nil
+}
+
+// lastEdit возвращает текст последней правки сообщения.
+func (a *recordingAPI) lastEdit() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.edits) == 0 {
+		return ""
+	}
+	return a.edits[len(a.edits)-1].Text
+}
+
+// recipients возвращает чаты, получившие сообщение с текстом text.
+func (a *recordingAPI) recipients(text string) []int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var chats []int64
+	for _, msg := range a.messages {
+		if msg.Text == text {
+			chats = append(chats, msg.ChatID)
+		}
+	}
+	return chats
+}
+
+func newAdminHandler(t *testing.T) (*handlers.Handler, *recordingAPI) {
+	t.Helper()
+	db, err := gorm.Open(database.NewSQLiteDialector(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&database.User{}, &database.Subscription{}, &database.SentArticle{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	api := &recordingAPI{}
+	s := sender.New(api, sender.Config{GlobalRate: 1000, GlobalBurst: 1000, ChatRate: 1000, ChatBurst: 1000})
+	userRepo := database.NewUserRepository(db)
+	for _, id := range []int64{adminID, 1001, 1002} {
+		if _, err := userRepo.FindOrCreateUser(context.Background(), id, "user", "Test", "User"); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+	h := handlers.NewHandler(s, userRepo, database.NewSubscriptionRepository(db), nil, database.NewStatsRepository(db), nil, nil, []int64{adminID})
+	return h, api
+}
+
+func commandUpdate(from int64, text string) tgbotapi.Update {
+	command := strings.Fields(text)[0]
+	return tgbotapi.Update{Message: &tgbotapi.Message{
+		From:     &tgbotapi.User{ID: from, FirstName: "Test"},
+		Chat:     &tgbotapi.Chat{ID: from},
+		Text:     text,
+		Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(command)}},
+	}}
+}
+
+func callbackUpdate(from int64, data string) tgbotapi.Update {
+	return tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:      "callback",
+		From:    &tgbotapi.User{ID: from},
+		Message: &tgbotapi.Message{MessageID: 7, Chat: &tgbotapi.Chat{ID: from}},
+		Data:    data,
+	}}
+}
+
+func TestBroadcastRequiresConfirmation(t *testing.T) {
+	h, api := newAdminHandler(t)
+
+	h.HandleUpdate(commandUpdate(adminID, "/broadcast Плановые работы"))
+	if got := api.recipients("Плановые работы"); len(got) != 0 {
+		t.Fatalf("Broadcast sent before confirmation to %v", got)
+	}
+
+	// Подтвердить рассылку может только администратор
+	h.HandleUpdate(callbackUpdate(1001, "broadcast_confirm"))
+	h.HandleUpdate(callbackUpdate(adminID, "broadcast_confirm"))
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.HasPrefix(api.lastEdit(), "✅") {
+		if time.Now().After(deadline) {
+			t.Fatalf("Broadcast did not finish, last report %q", api.lastEdit())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := api.recipients("Плановые работы"); len(got) != 3 {
+		t.Errorf("Broadcast recipients = %v, want all 3 users", got)
+	}
+	if report := api.lastEdit(); !strings.Contains(report, "доставлено 3") {
+		t.Errorf("Final report = %q, want 3 delivered", report)
+	}
+
+	// Подтверждение срабатывает один раз
+	h.HandleUpdate(callbackUpdate(adminID, "broadcast_confirm"))
+	time.Sleep(50 * time.Millisecond)
+	if got := api.recipients("Плановые работы"); len(got) != 3 {
+		t.Errorf("Broadcast repeated after second confirmation: %v", got)
+	}
+}
+
+func TestBroadcastStopWaitsForRunningBroadcast(t *testing.T) {
+	h, api := newAdminHandler(t)
+
+	h.HandleUpdate(commandUpdate(adminID, "/broadcast Плановые работы"))
+	h.HandleUpdate(callbackUpdate(adminID, "broadcast_confirm"))
+	h.Stop()
+
+	// После Stop рассылка завершена или прервана, но больше не выполняется
+	if report := api.lastEdit(); !strings.HasPrefix(report, "✅") && !strings.HasPrefix(report, "⚠️") {
+		t.Fatalf("Report after Stop = %q, want finished or interrupted broadcast", report)
+	}
+	sent := len(api.recipients("Плановые работы"))
+
+	// Остановленный обработчик не запускает новых рассылок
+	h.HandleUpdate(commandUpdate(adminID, "/broadcast Повторная рассылка"))
+	h.HandleUpdate(callbackUpdate(adminID, "broadcast_confirm"))
+	if got := api.recipients("Повторная рассылка"); len(got) != 0 {
+		t.Errorf("Broadcast started after Stop to %v", got)
+	}
+	if got := len(api.recipients("Плановые работы")); got != sent {
+		t.Errorf("Broadcast recipients changed after Stop: %d, want %d", got, sent)
+	}
+}
+
+func TestAdminCommandsHiddenFromUsers(t *testing.T) {
+	h, api := newAdminHandler(t)
+
+	h.HandleUpdate(commandUpdate(1001, "/users"))
+	h.HandleUpdate(commandUpdate(adminID, "/users"))
+
+	if len(api.messages) != 2 {
+		t.Fatalf("Sent %d messages, want 2", len(api.messages))
+	}
+	if text := api.messages[0].Text; !strings.HasPrefix(text, "Неизвестная команда") {
+		t.Errorf("Non-admin reply = %q, want unknown command", text)
+	}
+	if text := api.messages[1].Text; !strings.Contains(text, "Всего: 3") {
+		t.Errorf("Admin reply = %q, want user count", text)
+	}
+}
@@ -0,0 +1,24 @@
+package handlers_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScheduleParseErrorEscaped(t *testing.T) {
+	h, api := newAdminHandler(t)
+
+	h.HandleUpdate(commandUpdate(1001, "/schedule 0 9 * *"))
+
+	if len(api.messages) != 1 {
+		t.Fatalf("Sent %d messages, want 1", len(api.messages))
+	}
+	text := api.messages[0].Text
+	if !strings.Contains(text, "Не удалось разобрать расписание") {
+		t.Fatalf("Reply = %q, want parse error", text)
+	}
+	// Непарный "_" вне блока кода Telegram отклоняет как некорректную разметку
+	if !strings.Contains(text, `день\_недели), получено 4`) {
+		t.Errorf("Reply = %q, want escaped error text", text)
+	}
+}
@@ -0,0 +1,101 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/pkg/logger"
+)
+
+const (
+	botToken = "123456:AAE-secret-bot-token"
+	apiKey   = "gnews-api-key-42"
+)
+
+func newLogger(t *testing.T, format string) (*bytes.Buffer, func(msg string, args ...any)) {
+	t.Helper()
+	var buf bytes.Buffer
+	l, err := logger.New(logger.Config{
+		Level:   "info",
+		Format:  format,
+		Output:  &buf,
+		Secrets: []string{botToken, apiKey, "", "ab"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return &buf, l.Info
+}
+
+func TestRedactsSecrets(t *testing.T) {
+	for _, format := range []string{logger.FormatText, logger.FormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			buf, info := newLogger(t, format)
+			requestErr := fmt.Errorf("request failed: %w", errors.New(`Get "https://api.telegram.org/bot`+botToken+`/getMe": timeout`))
+
+			info("Запрос к GNews API: https://gnews.io/api/v4/search?token="+apiKey,
+				"url", "https://gnews.io/api/v4/search?q=go&token="+apiKey,
+				"error", requestErr,
+				"attempt", 2)
+
+			out := buf.String()
+			if strings.Contains(out, botToken) || strings.Contains(out, apiKey) {
+				t.Fatalf("Log output leaks a secret: %s", out)
+			}
+			if got := strings.Count(out, logger.Redacted); got != 3 {
+				t.Errorf("Log output contains %d redacted values, want 3: %s", got, out)
+			}
+			// Значения без секретов и короткие "секреты" не меняются
+			if !strings.Contains(out, "attempt=2") && !strings.Contains(out, `"attempt":2`) {
+				t.Errorf("Log output lost an unrelated attribute: %s", out)
+			}
+		})
+	}
+}
+
+func TestRedactsSharedAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := logger.New(logger.Config{Format: logger.FormatJSON, Output: &buf, Secrets: []string{apiKey}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	l.With("key", apiKey).WithGroup("request").Info("Запрос", "query", "политика", "token", apiKey)
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("Output is not JSON: %v: %s", err, buf.String())
+	}
+	if record["key"] != logger.Redacted {
+		t.Errorf("Shared attribute = %v, want %s", record["key"], logger.Redacted)
+	}
+	request, _ := record["request"].(map[string]any)
+	if request["token"] != logger.Redacted || request["query"] != "политика" {
+		t.Errorf("Grouped attributes = %v", request)
+	}
+}
+
+func TestLevels(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := logger.New(logger.Config{Level: "warn", Output: &buf})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	l.Info("скрыто")
+	l.Warn("видно")
+	if out := buf.String(); strings.Contains(out, "скрыто") || !strings.Contains(out, "видно") {
+		t.Errorf("Level filtering failed: %s", out)
+	}
+
+	if _, err := logger.New(logger.Config{Level: "verbose"}); err == nil {
+		t.Error("New() should reject an unknown level")
+	}
+	if _, err := logger.New(logger.Config{Format: "xml"}); err == nil {
+		t.Error("New() should reject an unknown format")
+	}
+}
@@ -0,0 +1,83 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/pkg/metrics"
+)
+
+func TestWriteText(t *testing.T) {
+	registry := metrics.NewRegistry()
+	updates := registry.NewCounter("test_updates_total", "Обновления\nпо типу.", "type")
+	depth := registry.NewGauge("test_depth", "Размер очереди.")
+	latency := registry.NewHistogram("test_latency_seconds", "Задержка.", []float64{1, 0.1}, "command")
+
+	updates.Inc("message")
+	updates.Add(2, "message")
+	updates.Inc(`say "hi"`)
+	updates.Add(-5, "message") // Счетчик не убывает
+	depth.Set(7)
+	latency.Observe(0.05, "start")
+	latency.Observe(0.5, "start")
+	latency.Observe(3, "start")
+
+	var out strings.Builder
+	if err := registry.WriteText(&out); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+
+	want := `# HELP test_depth Размер очереди.
+# TYPE test_depth gauge
+test_depth 7
+# HELP test_latency_seconds Задержка.
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{command="start",le="0.1"} 1
+test_latency_seconds_bucket{command="start",le="1"} 2
+test_latency_seconds_bucket{command="start",le="+Inf"} 3
+test_latency_seconds_sum{command="start"} 3.55
+test_latency_seconds_count{command="start"} 3
+# HELP test_updates_total Обновления\nпо типу.
+# TYPE test_updates_total counter
+test_updates_total{type="message"} 3
+test_updates_total{type="say \"hi\""} 1
+`
+	if out.String() != want {
+		t.Errorf("WriteText() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestRegistryPanics(t *testing.T) {
+	registry := metrics.NewRegistry()
+	counter := registry.NewCounter("test_total", "Счетчик.", "a", "b")
+
+	assertPanics(t, "duplicate name", func() { registry.NewGauge("test_total", "Повтор.") })
+	assertPanics(t, "wrong label count", func() { counter.Inc("only-one") })
+}
+
+func TestHandler(t *testing.T) {
+	registry := metrics.NewRegistry()
+	registry.NewCounter("test_total", "Счетчик.").Inc()
+
+	rec := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "test_total 1\n") {
+		t.Errorf("Body = %q", rec.Body.String())
+	}
+}
+
+func assertPanics(t *testing.T, name string, fn func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s: expected panic", name)
+		}
+	}()
+	fn()
+}
@@ -0,0 +1,107 @@
+package query_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/query"
+)
+
+func TestParseCanonicalForm(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"apple", "apple"},
+		{"Apple iPhone", "apple AND iphone"},
+		{"apple -fruit", "apple AND NOT fruit"},
+		{"-fruit apple", "apple AND NOT fruit"},
+		{"apple OR google AND ai", "apple OR (google AND ai)"},
+		{"(apple OR google) ai", "(apple OR google) AND ai"},
+		{`"Искусственный   интеллект" AND регулирование`, `"искусственный интеллект" AND регулирование`},
+		{"covid-19 not (vaccine or mask)", "covid-19 AND NOT (vaccine OR mask)"},
+	}
+
+	for _, tt := range tests {
+		node, err := query.Parse(tt.input)
+		if err != nil {
+			t.Errorf("Parse(%q) error = %v", tt.input, err)
+			continue
+		}
+		if got := node.String(); got != tt.want {
+			t.Errorf("Parse(%q).String() = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		`"unterminated`,
+		"(apple OR google",
+		"apple)",
+		"apple AND",
+		"NOT",
+		"-fruit",
+		"apple OR -fruit",
+		`""`,
+		strings.Repeat("a ", 17),
+		strings.Repeat("(", 10) + "a" + strings.Repeat(")", 10),
+	}
+
+	for _, input := range tests {
+		_, err := query.Parse(input)
+		var parseErr *query.ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("Parse(%q) error = %v, want *query.ParseError", input, err)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	text := "Apple представила новый iPhone с искусственным   интеллектом"
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"apple iphone", true},
+		{"apple -iphone", false},
+		{"apple NOT fruit", true},
+		{"samsung OR iphone", true},
+		{"samsung OR google", false},
+		{`"искусственным интеллектом"`, true},
+		{`"интеллектом искусственным"`, false},
+		{"(samsung OR apple) AND NOT (fruit OR juice)", true},
+	}
+
+	for _, tt := range tests {
+		node, err := query.Parse(tt.query)
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.query, err)
+		}
+		if got := query.Match(node, text); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestKeywordsAndIsSimple(t *testing.T) {
+	node, err := query.Parse(`(apple OR "big data") -fruit apple`)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got, want := query.Keywords(node), []string{"apple", "big data"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Keywords() = %v, want %v", got, want)
+	}
+	if query.IsSimple(node) {
+		t.Error("IsSimple() = true for a query with operators")
+	}
+
+	simple, _ := query.Parse("apple iphone")
+	if !query.IsSimple(simple) {
+		t.Error("IsSimple() = false for plain keywords")
+	}
+}
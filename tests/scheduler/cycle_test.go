@@ -0,0 +1,79 @@
+package scheduler_test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/scheduler"
+)
+
+func TestCycleFetchesSharedTopicOnce(t *testing.T) {
+	// Подписчики темы попадают в разные страницы выборки пользователей
+	f := newFixture(t, scheduler.Options{Interval: 20 * time.Millisecond, Workers: 3, BatchSize: 2})
+	const subscribers = 5
+	for i := 0; i < subscribers; i++ {
+		f.addUser(database.User{NotificationIntervalMinutes: 60}, "технологии")
+	}
+	f.setArticles("технологии", newsArticle("Вышел новый процессор", "Хабр", "https://example.com/cpu"))
+
+	f.scheduler.Start()
+	f.waitMessages(t, subscribers)
+	f.scheduler.Stop()
+
+	if got := f.provider.fetches("технологии"); got != 1 {
+		t.Errorf("topic fetches = %d, want 1 for %d subscribers", got, subscribers)
+	}
+}
+
+func TestCyclePagesThroughAllUsers(t *testing.T) {
+	f := newFixture(t, scheduler.Options{Interval: 20 * time.Millisecond, Workers: 2, BatchSize: 2})
+	const users = 5
+	for i := 0; i < users; i++ {
+		f.addUser(database.User{NotificationIntervalMinutes: 60}, "технологии")
+	}
+	f.setArticles("технологии", newsArticle("Вышел новый процессор", "Хабр", "https://example.com/cpu"))
+
+	f.scheduler.Start()
+	f.waitMessages(t, users)
+	f.scheduler.Stop()
+
+	// Первый цикл читает три страницы: 2 + 2 + 1 пользователь
+	if got := f.users.cursors(); len(got) < 3 || !slices.Equal(got[:3], []uint{0, 2, 4}) {
+		t.Errorf("GetUsersBatch cursors = %v, want cycle starting with [0 2 4]", got)
+	}
+	perChat := make(map[int64]int)
+	for _, msg := range f.api.sent() {
+		perChat[msg.ChatID]++
+	}
+	for id := uint(1); id <= users; id++ {
+		chatID := f.user(t, id).TelegramID
+		if perChat[chatID] != 1 {
+			t.Errorf("messages to user %d = %d, want 1", id, perChat[chatID])
+		}
+	}
+}
+
+func TestCycleSkippedWhilePreviousIsRunning(t *testing.T) {
+	f := newFixture(t, scheduler.Options{Interval: 10 * time.Millisecond})
+	f.addUser(database.User{NotificationIntervalMinutes: 60}, "технологии")
+	f.setArticles("технологии", newsArticle("Вышел новый процессор", "Хабр", "https://example.com/cpu"))
+	release := f.blockFetches()
+	defer release()
+
+	f.scheduler.Start()
+	// За это время наступает около десяти интервалов, но первый цикл еще ждет провайдера
+	time.Sleep(100 * time.Millisecond)
+
+	if got := f.provider.fetches("технологии"); got != 1 {
+		t.Errorf("topic fetches while cycle is running = %d, want 1", got)
+	}
+	if got := f.users.cursors(); len(got) != 1 {
+		t.Errorf("GetUsersBatch calls while cycle is running = %d, want 1", len(got))
+	}
+
+	release()
+	f.waitMessages(t, 1)
+	f.scheduler.Stop()
+}
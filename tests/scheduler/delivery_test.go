@@ -0,0 +1,46 @@
+package scheduler_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/scheduler"
+)
+
+func TestProcessUserMarksArticlesSentAfterDelivery(t *testing.T) {
+	f := newFixture(t, scheduler.Options{})
+	user := f.addUser(database.User{}, "технологии")
+	f.setArticles("технологии",
+		newsArticle("Вышел новый процессор", "Хабр", "https://example.com/cpu"),
+		newsArticle("Обновление языка Go", "Хабр", "https://example.com/go"),
+	)
+	ctx := context.Background()
+
+	if got := f.scheduler.ProcessUser(ctx, user, true); got != 2 {
+		t.Fatalf("ProcessUser() = %d, want 2", got)
+	}
+	f.wantMessages(t, 2)
+	f.wantSent(t, user.ID, "https://example.com/cpu", "https://example.com/go")
+	if got := f.outbox.list(); len(got) != 0 {
+		t.Errorf("outbox = %+v, want delivered messages removed", got)
+	}
+
+	// Повторный запуск не отправляет те же статьи
+	if got := f.scheduler.ProcessUser(ctx, user, true); got != 0 {
+		t.Errorf("second ProcessUser() = %d, want 0", got)
+	}
+	f.wantMessages(t, 2)
+}
+
+func TestProcessUserDoesNotMarkUndeliveredArticles(t *testing.T) {
+	f := newFixture(t, scheduler.Options{})
+	user := f.addUser(database.User{}, "технологии")
+	f.setArticles("технологии", newsArticle("Вышел новый процессор", "Хабр", "https://example.com/cpu"))
+	f.api.setError(errServer)
+
+	if got := f.scheduler.ProcessUser(context.Background(), user, true); got != 0 {
+		t.Fatalf("ProcessUser() = %d, want 0", got)
+	}
+	f.wantNotSent(t, user.ID, "https://example.com/cpu")
+}
@@ -0,0 +1,73 @@
+package scheduler_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/scheduler"
+)
+
+func TestDigestMarksOverLimitItemsSent(t *testing.T) {
+	f := newFixture(t, scheduler.Options{})
+	user := f.addUser(database.User{DeliveryMode: database.DeliveryModeDigest, NewsLimit: 1}, "технологии")
+	urls := []string{"https://example.com/cpu", "https://example.com/go", "https://example.com/ai"}
+	f.setArticles("технологии",
+		newsArticle("Вышел новый процессор", "Хабр", urls[0]),
+		newsArticle("Обновление языка Go", "Хабр", urls[1]),
+		newsArticle("Новая языковая модель", "Хабр", urls[2]),
+	)
+	ctx := context.Background()
+
+	if got := f.scheduler.ProcessUser(ctx, user, true); got != 1 {
+		t.Fatalf("ProcessUser() = %d, want 1", got)
+	}
+	f.wantMessages(t, 1)
+	// Новости сверх лимита помечаются вместе с отправленной, иначе они попадут в следующую сводку
+	f.wantSent(t, user.ID, urls...)
+	if items, _ := f.digest.GetDigestItems(ctx, user.ID); len(items) != 0 {
+		t.Errorf("digest items = %d, want 0", len(items))
+	}
+
+	if got := f.scheduler.ProcessUser(ctx, user, true); got != 0 {
+		t.Errorf("second ProcessUser() = %d, want 0", got)
+	}
+	f.wantMessages(t, 1)
+}
+
+func TestDigestKeepsItemsWhenSendFails(t *testing.T) {
+	f := newFixture(t, scheduler.Options{})
+	user := f.addUser(database.User{DeliveryMode: database.DeliveryModeDigest}, "технологии")
+	f.setArticles("технологии", newsArticle("Вышел новый процессор", "Хабр", "https://example.com/cpu"))
+	f.api.setError(errServer)
+	ctx := context.Background()
+
+	if got := f.scheduler.ProcessUser(ctx, user, true); got != 0 {
+		t.Fatalf("ProcessUser() = %d, want 0", got)
+	}
+	f.wantNotSent(t, user.ID, "https://example.com/cpu")
+
+	// После восстановления новость уходит в следующей сводке
+	f.api.setError(nil)
+	if got := f.scheduler.ProcessUser(ctx, user, true); got != 1 {
+		t.Fatalf("second ProcessUser() = %d, want 1", got)
+	}
+	if sent := f.wantMessages(t, 1); !strings.Contains(sent[0].Text, "Вышел новый процессор") {
+		t.Errorf("digest does not contain the article:\n%s", sent[0].Text)
+	}
+}
+
+func TestDigestKeepsUserDueWhenAllTopicsFail(t *testing.T) {
+	f := newFixture(t, scheduler.Options{})
+	user := f.addUser(database.User{DeliveryMode: database.DeliveryModeDigest, NotificationIntervalMinutes: 60}, "погода")
+	f.setFetchError("погода", fetcher.ErrBadQuery)
+
+	f.scheduler.ProcessUser(context.Background(), user, false)
+
+	// Время пользователя не сдвигается, пока не удалось получить новости ни по одной теме
+	if got := f.user(t, user.ID).LastNotifiedAt; got != nil {
+		t.Errorf("user check time = %v, want nil", got)
+	}
+}
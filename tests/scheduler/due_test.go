@@ -0,0 +1,65 @@
+package scheduler_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/scheduler"
+)
+
+func TestCycleProcessesTopicsWithOwnInterval(t *testing.T) {
+	f := newFixture(t, scheduler.Options{Interval: 20 * time.Millisecond, Workers: 2})
+
+	// Общий интервал пользователя не наступил, темы с собственным интервалом готовы к проверке
+	now := time.Now()
+	lastChecked := now.Add(-2 * time.Minute)
+	user := f.addUser(database.User{NotificationIntervalMinutes: 60, LastNotifiedAt: &now})
+	everyMinute := database.SubscriptionSettings{IntervalMinutes: 1}
+	f.addSubscription(database.Subscription{UserID: user.ID, Topic: "наука", SubscriptionSettings: everyMinute, LastNotifiedAt: &lastChecked})
+	f.addSubscription(database.Subscription{UserID: user.ID, Topic: "погода", SubscriptionSettings: everyMinute, LastNotifiedAt: &lastChecked})
+	f.addSubscription(database.Subscription{UserID: user.ID, Topic: "спорт"})
+
+	f.setArticles("наука", newsArticle("Открыта новая экзопланета", "N+1", "https://example.com/planet"))
+	f.setFetchError("погода", errors.New("провайдер не отвечает"))
+	f.setArticles("спорт", newsArticle("Итоги матча", "Спорт-Экспресс", "https://example.com/match"))
+
+	f.scheduler.Start()
+	f.waitMessages(t, 1)
+	f.scheduler.Stop()
+
+	if sent := f.wantMessages(t, 1); !strings.Contains(sent[0].Text, "Открыта новая экзопланета") {
+		t.Fatalf("sent message is not from the due topic:\n%s", sent[0].Text)
+	}
+	// Тема без собственного интервала ждет общего интервала пользователя
+	f.wantNotSent(t, user.ID, "https://example.com/match")
+
+	if got := f.subscription(t, user.ID, "наука").LastNotifiedAt; got == nil || !got.After(lastChecked) {
+		t.Errorf("processed topic check time = %v, want advanced", got)
+	}
+	// Тема, новости по которой получить не удалось, проверяется снова в следующем цикле
+	if got := f.subscription(t, user.ID, "погода").LastNotifiedAt; got == nil || !got.Equal(lastChecked) {
+		t.Errorf("failed topic check time = %v, want %v", got, lastChecked)
+	}
+	if got := f.user(t, user.ID).LastNotifiedAt; !got.Equal(now) {
+		t.Errorf("user check time = %v, want %v", got, now)
+	}
+}
+
+func TestCycleKeepsUserDueWhenAllTopicsFail(t *testing.T) {
+	f := newFixture(t, scheduler.Options{Interval: 20 * time.Millisecond})
+	user := f.addUser(database.User{NotificationIntervalMinutes: 60}, "погода")
+	f.setFetchError("погода", fetcher.ErrBadQuery)
+
+	f.scheduler.Start()
+	time.Sleep(100 * time.Millisecond)
+	f.scheduler.Stop()
+
+	// Время пользователя не сдвигается, пока не удалось получить новости ни по одной теме
+	if got := f.user(t, user.ID).LastNotifiedAt; got != nil {
+		t.Errorf("user check time = %v, want nil", got)
+	}
+}
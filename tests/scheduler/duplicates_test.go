@@ -0,0 +1,141 @@
+package scheduler_test
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/scheduler"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/pkg/metrics"
+)
+
+const (
+	rbcURL         = "https://www.rbc.ru/finances/rate"
+	kommersantURL  = "https://www.kommersant.ru/doc/rate"
+	duplicateTopic = "экономика"
+)
+
+// keyRateArticles возвращает одну новость от двух источников.
+func keyRateArticles() []fetcher.Article {
+	published := time.Now().Add(-2 * time.Hour)
+	return []fetcher.Article{
+		{
+			Title:       "Центробанк повысил ключевую ставку до 18% - РБК",
+			Description: "Банк России на заседании в пятницу повысил ключевую ставку на 100 базисных пунктов",
+			URL:         rbcURL,
+			PublishedAt: published,
+			Source:      fetcher.Source{Name: "РБК"},
+		},
+		{
+			Title:       "ЦБ повысил ключевую ставку до 18%",
+			Description: "Совет директоров Банка России повысил ключевую ставку до 18% годовых",
+			URL:         kommersantURL,
+			PublishedAt: published.Add(time.Hour),
+			Source:      fetcher.Source{Name: "Коммерсант"},
+		},
+	}
+}
+
+func TestDuplicatesMergedIntoOneMessage(t *testing.T) {
+	f := newFixture(t, scheduler.Options{})
+	user := f.addUser(database.User{}, duplicateTopic)
+	f.setArticles(duplicateTopic, keyRateArticles()...)
+
+	if got := f.scheduler.ProcessUser(context.Background(), user, true); got != 1 {
+		t.Fatalf("ProcessUser() = %d, want 1", got)
+	}
+	sent := f.wantMessages(t, 1)
+	if !strings.Contains(sent[0].Text, "</i>\n\n<i>🔁 Также сообщают: Коммерсант</i>\n\n<a href") {
+		t.Errorf("message does not list other sources:\n%s", sent[0].Text)
+	}
+	f.wantSent(t, user.ID, rbcURL, kommersantURL)
+}
+
+func TestDuplicatesNotMarkedWhenDeliveryFails(t *testing.T) {
+	f := newFixture(t, scheduler.Options{})
+	user := f.addUser(database.User{}, duplicateTopic)
+	f.setArticles(duplicateTopic, keyRateArticles()...)
+	f.api.setError(errBlocked)
+
+	f.scheduler.ProcessUser(context.Background(), user, true)
+
+	f.wantNotSent(t, user.ID, rbcURL, kommersantURL)
+}
+
+func TestDuplicatesMarkedWhenDigestSent(t *testing.T) {
+	f := newFixture(t, scheduler.Options{})
+	user := f.addUser(database.User{DeliveryMode: database.DeliveryModeDigest}, duplicateTopic)
+	f.setArticles(duplicateTopic, keyRateArticles()...)
+	ctx := context.Background()
+
+	// Сводка не доставлена: объединенные статьи остаются неотправленными
+	f.api.setError(errServer)
+	f.scheduler.ProcessUser(ctx, user, true)
+	f.wantNotSent(t, user.ID, rbcURL, kommersantURL)
+
+	f.api.setError(nil)
+	if got := f.scheduler.ProcessUser(ctx, user, true); got != 1 {
+		t.Fatalf("ProcessUser() = %d, want 1", got)
+	}
+	f.wantSent(t, user.ID, rbcURL, kommersantURL)
+}
+
+func TestArticleMessageWithoutDuplicates(t *testing.T) {
+	f := newFixture(t, scheduler.Options{})
+	user := f.addUser(database.User{}, duplicateTopic)
+	article := keyRateArticles()[0]
+	f.setArticles(duplicateTopic, article)
+
+	f.scheduler.ProcessUser(context.Background(), user, true)
+
+	sent := f.wantMessages(t, 1)
+	// Сообщение без похожих статей сохраняет прежний вид: пустая строка перед ссылкой
+	want := "<i>📅 Опубликовано: " + article.PublishedAt.Format("02.01.2006 15:04") + "</i>\n\n<a href=\"" + rbcURL + "\">"
+	if strings.Contains(sent[0].Text, "Также сообщают") || !strings.Contains(sent[0].Text, want) {
+		t.Errorf("unexpected message layout:\n%s", sent[0].Text)
+	}
+}
+
+// deduplicatedTotal возвращает сумму newsbot_articles_deduplicated_total по всем темам.
+func deduplicatedTotal(t *testing.T) float64 {
+	t.Helper()
+	var out strings.Builder
+	if err := metrics.Default.WriteText(&out); err != nil {
+		t.Fatalf("WriteText() error = %v", err)
+	}
+	total := 0.0
+	for _, line := range strings.Split(out.String(), "\n") {
+		if !strings.HasPrefix(line, "newsbot_articles_deduplicated_total{") {
+			continue
+		}
+		value, err := strconv.ParseFloat(line[strings.LastIndexByte(line, ' ')+1:], 64)
+		if err != nil {
+			t.Fatalf("metric line %q: %v", line, err)
+		}
+		total += value
+	}
+	return total
+}
+
+func TestDuplicatesCountedOncePerTopic(t *testing.T) {
+	f := newFixture(t, scheduler.Options{Interval: 20 * time.Millisecond})
+	const subscribers = 3
+	for i := 0; i < subscribers; i++ {
+		f.addUser(database.User{NotificationIntervalMinutes: 60}, duplicateTopic)
+	}
+	f.setArticles(duplicateTopic, keyRateArticles()...)
+	before := deduplicatedTotal(t)
+
+	f.scheduler.Start()
+	f.waitMessages(t, subscribers)
+	f.scheduler.Stop()
+
+	// Одна похожая статья в ответе провайдера учитывается один раз, а не для каждого подписчика
+	if got := deduplicatedTotal(t) - before; got != 1 {
+		t.Errorf("deduplicated articles = %v, want 1 for %d subscribers", got, subscribers)
+	}
+}
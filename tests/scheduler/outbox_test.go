@@ -0,0 +1,88 @@
+package scheduler_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/scheduler"
+)
+
+func TestOutboxRetriesTransientErrors(t *testing.T) {
+	f := newFixture(t, scheduler.Options{})
+	user := f.addUser(database.User{}, "технологии")
+	f.setArticles("технологии", newsArticle("Вышел новый процессор", "Хабр", "https://example.com/cpu"))
+	f.api.setError(errServer)
+
+	f.scheduler.ProcessUser(context.Background(), user, true)
+
+	messages := f.outbox.list()
+	if len(messages) != 1 {
+		t.Fatalf("outbox size = %d, want 1", len(messages))
+	}
+	msg := messages[0]
+	if msg.Status != database.OutboxStatusPending || msg.Attempts != 1 {
+		t.Errorf("message status = %q, attempts = %d, want pending after 1 attempt", msg.Status, msg.Attempts)
+	}
+	if msg.LastError == "" {
+		t.Error("last error is not recorded")
+	}
+}
+
+func TestOutboxGivesUpWhenBotIsBlocked(t *testing.T) {
+	f := newFixture(t, scheduler.Options{})
+	user := f.addUser(database.User{}, "технологии")
+	f.setArticles("технологии", newsArticle("Вышел новый процессор", "Хабр", "https://example.com/cpu"))
+	f.api.setError(errBlocked)
+	ctx := context.Background()
+
+	f.scheduler.ProcessUser(ctx, user, true)
+
+	messages := f.outbox.list()
+	if len(messages) != 1 || messages[0].Status != database.OutboxStatusFailed {
+		t.Fatalf("outbox = %+v, want one failed message", messages)
+	}
+	f.wantNotSent(t, user.ID, "https://example.com/cpu")
+
+	// Недоставленная окончательно статья не ставится в очередь снова
+	f.api.setError(nil)
+	if got := f.scheduler.ProcessUser(ctx, user, true); got != 0 {
+		t.Errorf("second ProcessUser() = %d, want 0", got)
+	}
+	if got := len(f.outbox.list()); got != 1 {
+		t.Errorf("outbox size after second run = %d, want 1", got)
+	}
+	f.wantMessages(t, 0)
+}
+
+func TestOutboxGivesUpAfterRepeatedRateLimits(t *testing.T) {
+	f := newFixture(t, scheduler.Options{})
+	user := f.addUser(database.User{}, "технологии")
+	f.setArticles("технологии", newsArticle("Вышел новый процессор", "Хабр", "https://example.com/cpu"))
+	f.api.setError(&tgbotapi.Error{Code: http.StatusTooManyRequests, Message: "Too Many Requests",
+		ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 1}})
+	ctx := context.Background()
+
+	// Каждый ответ 429 расходует попытку; после восьмой сообщение больше не повторяется.
+	// Очередь пользователя доставляется вместе с новыми статьями, поэтому каждый запуск
+	// находит еще одну статью
+	const maxAttempts = 8
+	for i := 0; i < maxAttempts+2; i++ {
+		if i > 0 {
+			f.setArticles("технологии", newsArticle("Новость", "Хабр", fmt.Sprintf("https://example.com/news/%d", i)))
+		}
+		f.outbox.makeDue()
+		f.scheduler.ProcessUser(ctx, user, true)
+	}
+
+	messages := f.outbox.list()
+	if len(messages) == 0 {
+		t.Fatal("outbox is empty")
+	}
+	if msg := messages[0]; msg.Status != database.OutboxStatusFailed || msg.Attempts != maxAttempts {
+		t.Errorf("message status = %q, attempts = %d, want failed after %d attempts", msg.Status, msg.Attempts, maxAttempts)
+	}
+}
@@ -0,0 +1,77 @@
+package scheduler_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/scheduler"
+)
+
+// quietNow возвращает пользователя, у которого сейчас тихие часы, и момент их окончания.
+func quietNow(user database.User) (database.User, time.Time) {
+	now := time.Now().UTC()
+	user.TimeZone = "UTC"
+	user.QuietHoursStart = now.Add(-time.Hour).Format("15:04")
+	user.QuietHoursEnd = now.Add(time.Hour).Format("15:04")
+	return user, now.Add(time.Hour).Truncate(time.Minute)
+}
+
+func TestQuietHoursDelayDelivery(t *testing.T) {
+	f := newFixture(t, scheduler.Options{})
+	quiet, end := quietNow(database.User{})
+	user := f.addUser(quiet, "технологии")
+	f.setArticles("технологии", newsArticle("Вышел новый процессор", "Хабр", "https://example.com/cpu"))
+
+	if got := f.scheduler.ProcessUser(context.Background(), user, false); got != 0 {
+		t.Fatalf("ProcessUser() = %d, want 0", got)
+	}
+	f.wantMessages(t, 0)
+	f.wantNotSent(t, user.ID, "https://example.com/cpu")
+
+	// Сообщение ждет в очереди окончания тихих часов
+	messages := f.outbox.list()
+	if len(messages) != 1 {
+		t.Fatalf("outbox size = %d, want 1", len(messages))
+	}
+	if msg := messages[0]; msg.Status != database.OutboxStatusPending || !msg.NextAttemptAt.Equal(end) {
+		t.Errorf("message status = %q, next attempt = %v, want pending until %v", msg.Status, msg.NextAttemptAt, end)
+	}
+}
+
+func TestQuietHoursIgnoredByForcedRun(t *testing.T) {
+	f := newFixture(t, scheduler.Options{})
+	quiet, _ := quietNow(database.User{})
+	user := f.addUser(quiet, "технологии")
+	f.setArticles("технологии", newsArticle("Вышел новый процессор", "Хабр", "https://example.com/cpu"))
+
+	if got := f.scheduler.ProcessUser(context.Background(), user, true); got != 1 {
+		t.Fatalf("ProcessUser() = %d, want 1", got)
+	}
+	f.wantMessages(t, 1)
+	f.wantSent(t, user.ID, "https://example.com/cpu")
+}
+
+func TestQuietHoursDelayDigest(t *testing.T) {
+	f := newFixture(t, scheduler.Options{})
+	quiet, _ := quietNow(database.User{DeliveryMode: database.DeliveryModeDigest})
+	user := f.addUser(quiet, "технологии")
+	f.setArticles("технологии", newsArticle("Вышел новый процессор", "Хабр", "https://example.com/cpu"))
+	ctx := context.Background()
+
+	// Сводке пора уйти, но она ждет окончания тихих часов
+	if got := f.scheduler.ProcessUser(ctx, user, false); got != 0 {
+		t.Fatalf("ProcessUser() = %d, want 0", got)
+	}
+	f.wantMessages(t, 0)
+	if items, _ := f.digest.GetDigestItems(ctx, user.ID); len(items) != 1 {
+		t.Fatalf("digest items = %d, want 1", len(items))
+	}
+
+	if got := f.scheduler.ProcessUser(ctx, user, true); got != 1 {
+		t.Fatalf("forced ProcessUser() = %d, want 1", got)
+	}
+	f.wantMessages(t, 1)
+	f.wantSent(t, user.ID, "https://example.com/cpu")
+}
@@ -0,0 +1,601 @@
+package scheduler_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+)
+
+// Репозитории в памяти для тестов планировщика. Они следуют правилам репозиториев БД
+// в том объеме, который нужен планировщику: отбор ожидающих сообщений очереди,
+// учет похожих статей и т. п.
+
+// fakeUsers хранит пользователей в памяти.
+type fakeUsers struct {
+	mu    sync.Mutex
+	users []database.User
+
+	// afterIDs - курсоры всех вызовов GetUsersBatch по порядку.
+	afterIDs []uint
+}
+
+func (r *fakeUsers) FindOrCreateUser(ctx context.Context, telegramID int64, username, firstName, lastName string) (*database.User, error) {
+	if user, err := r.GetUserByTelegramID(ctx, telegramID); err == nil {
+		return user, nil
+	}
+	user := r.add(database.User{TelegramID: telegramID, Username: username, FirstName: firstName, LastName: lastName})
+	return &user, nil
+}
+
+func (r *fakeUsers) GetAllUsers(ctx context.Context) ([]database.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.users), nil
+}
+
+func (r *fakeUsers) GetUsersBatch(ctx context.Context, afterID uint, limit int) ([]database.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.afterIDs = append(r.afterIDs, afterID)
+	var batch []database.User
+	for _, user := range r.users {
+		if user.ID > afterID && len(batch) < limit {
+			batch = append(batch, user)
+		}
+	}
+	return batch, nil
+}
+
+func (r *fakeUsers) GetUserByTelegramID(ctx context.Context, telegramID int64) (*database.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if user.TelegramID == telegramID {
+			return &user, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to get user: %w", database.ErrNotFound)
+}
+
+func (r *fakeUsers) SearchUsers(ctx context.Context, query string, afterID uint, limit int) ([]database.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var found []database.User
+	for _, user := range r.users {
+		if user.ID > afterID && len(found) < limit &&
+			(strings.Contains(user.Username+" "+user.FirstName+" "+user.LastName, query) || fmt.Sprint(user.TelegramID) == query) {
+			found = append(found, user)
+		}
+	}
+	return found, nil
+}
+
+func (r *fakeUsers) SetUserState(ctx context.Context, userID uint, state string) error {
+	return r.update(userID, func(user *database.User) { user.State = state })
+}
+
+func (r *fakeUsers) GetUserState(ctx context.Context, userID uint) (string, error) {
+	user, ok := r.get(userID)
+	if !ok {
+		return "", fmt.Errorf("failed to get user state: %w", database.ErrNotFound)
+	}
+	return user.State, nil
+}
+
+func (r *fakeUsers) UpdateUserLastNotifiedAt(ctx context.Context, userID uint, notifyTime time.Time) error {
+	return r.update(userID, func(user *database.User) { user.LastNotifiedAt = &notifyTime })
+}
+
+func (r *fakeUsers) UpdateUserNotificationInterval(ctx context.Context, userID uint, intervalMinutes uint) error {
+	return r.update(userID, func(user *database.User) { user.NotificationIntervalMinutes = intervalMinutes })
+}
+
+func (r *fakeUsers) UpdateUserNewsLimit(ctx context.Context, userID uint, newsLimit uint) error {
+	return r.update(userID, func(user *database.User) { user.NewsLimit = newsLimit })
+}
+
+func (r *fakeUsers) UpdateUserDeliveryMode(ctx context.Context, userID uint, mode, digestPeriod string) error {
+	return r.update(userID, func(user *database.User) { user.DeliveryMode, user.DigestPeriod = mode, digestPeriod })
+}
+
+func (r *fakeUsers) UpdateUserDigestTime(ctx context.Context, userID uint, digestTime string) error {
+	return r.update(userID, func(user *database.User) { user.DigestTime = digestTime })
+}
+
+func (r *fakeUsers) UpdateUserLastDigestAt(ctx context.Context, userID uint, digestTime time.Time) error {
+	return r.update(userID, func(user *database.User) { user.LastDigestAt = &digestTime })
+}
+
+func (r *fakeUsers) UpdateUserTimeZone(ctx context.Context, userID uint, timeZone string) error {
+	return r.update(userID, func(user *database.User) { user.TimeZone = timeZone })
+}
+
+func (r *fakeUsers) UpdateUserQuietHours(ctx context.Context, userID uint, start, end string) error {
+	return r.update(userID, func(user *database.User) { user.QuietHoursStart, user.QuietHoursEnd = start, end })
+}
+
+func (r *fakeUsers) UpdateUserSchedule(ctx context.Context, userID uint, schedule string) error {
+	return r.update(userID, func(user *database.User) { user.Schedule = schedule })
+}
+
+// add сохраняет пользователя и возвращает его с присвоенным ID.
+func (r *fakeUsers) add(user database.User) database.User {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user.ID = uint(len(r.users) + 1)
+	if user.TelegramID == 0 {
+		user.TelegramID = int64(1000 + user.ID)
+	}
+	r.users = append(r.users, user)
+	return user
+}
+
+func (r *fakeUsers) update(userID uint, apply func(user *database.User)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.users {
+		if r.users[i].ID == userID {
+			apply(&r.users[i])
+			return nil
+		}
+	}
+	return fmt.Errorf("failed to update user: %w", database.ErrNotFound)
+}
+
+func (r *fakeUsers) get(userID uint) (database.User, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, user := range r.users {
+		if user.ID == userID {
+			return user, true
+		}
+	}
+	return database.User{}, false
+}
+
+// cursors возвращает курсоры вызовов GetUsersBatch.
+func (r *fakeUsers) cursors() []uint {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.afterIDs)
+}
+
+// fakeSubscriptions хранит подписки в памяти.
+type fakeSubscriptions struct {
+	users *fakeUsers // Для GetSubscribersForTopic
+
+	mu   sync.Mutex
+	subs []database.Subscription
+}
+
+func (r *fakeSubscriptions) AddSubscription(ctx context.Context, userID uint, topic string) error {
+	topic = strings.ToLower(topic)
+	if _, ok := r.get(userID, topic); ok {
+		return database.ErrSubscriptionExists
+	}
+	r.add(database.Subscription{UserID: userID, Topic: topic})
+	return nil
+}
+
+func (r *fakeSubscriptions) RemoveSubscription(ctx context.Context, userID uint, topic string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	before := len(r.subs)
+	r.subs = slices.DeleteFunc(r.subs, func(sub database.Subscription) bool {
+		return sub.UserID == userID && sub.Topic == strings.ToLower(topic)
+	})
+	if len(r.subs) == before {
+		return errors.New("subscription not found")
+	}
+	return nil
+}
+
+func (r *fakeSubscriptions) GetUserSubscriptions(ctx context.Context, userID uint) ([]string, error) {
+	subs, _ := r.GetUserSubscriptionDetails(ctx, userID)
+	topics := make([]string, 0, len(subs))
+	for _, sub := range subs {
+		topics = append(topics, sub.Topic)
+	}
+	return topics, nil
+}
+
+func (r *fakeSubscriptions) GetUserSubscriptionDetails(ctx context.Context, userID uint) ([]database.Subscription, error) {
+	return r.GetSubscriptionsForUsers(ctx, []uint{userID})
+}
+
+func (r *fakeSubscriptions) GetSubscriptionsForUsers(ctx context.Context, userIDs []uint) ([]database.Subscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var subs []database.Subscription
+	for _, sub := range r.subs {
+		if slices.Contains(userIDs, sub.UserID) {
+			subs = append(subs, sub)
+		}
+	}
+	return subs, nil
+}
+
+func (r *fakeSubscriptions) GetSubscription(ctx context.Context, userID, subscriptionID uint) (*database.Subscription, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, sub := range r.subs {
+		if sub.ID == subscriptionID && sub.UserID == userID {
+			return &sub, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to get subscription: %w", database.ErrNotFound)
+}
+
+func (r *fakeSubscriptions) UpdateSubscriptionSettings(ctx context.Context, userID, subscriptionID uint, settings database.SubscriptionSettings) error {
+	return r.update(userID, subscriptionID, func(sub *database.Subscription) { sub.SubscriptionSettings = settings })
+}
+
+func (r *fakeSubscriptions) UpdateSubscriptionTopic(ctx context.Context, userID, subscriptionID uint, topic string) error {
+	topic = strings.ToLower(topic)
+	if existing, ok := r.get(userID, topic); ok && existing.ID != subscriptionID {
+		return database.ErrSubscriptionExists
+	}
+	return r.update(userID, subscriptionID, func(sub *database.Subscription) { sub.Topic = topic })
+}
+
+func (r *fakeSubscriptions) UpdateSubscriptionsLastNotifiedAt(ctx context.Context, subscriptionIDs []uint, notifyTime time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.subs {
+		if slices.Contains(subscriptionIDs, r.subs[i].ID) {
+			r.subs[i].LastNotifiedAt = &notifyTime
+		}
+	}
+	return nil
+}
+
+func (r *fakeSubscriptions) GetAllUniqueTopics(ctx context.Context) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var topics []string
+	for _, sub := range r.subs {
+		if !slices.Contains(topics, sub.Topic) {
+			topics = append(topics, sub.Topic)
+		}
+	}
+	return topics, nil
+}
+
+func (r *fakeSubscriptions) GetSubscribersForTopic(ctx context.Context, topic string) ([]int64, error) {
+	r.mu.Lock()
+	var userIDs []uint
+	for _, sub := range r.subs {
+		if sub.Topic == topic {
+			userIDs = append(userIDs, sub.UserID)
+		}
+	}
+	r.mu.Unlock()
+
+	var telegramIDs []int64
+	for _, id := range userIDs {
+		if user, ok := r.users.get(id); ok {
+			telegramIDs = append(telegramIDs, user.TelegramID)
+		}
+	}
+	return telegramIDs, nil
+}
+
+// add сохраняет подписку с присвоенным ID.
+func (r *fakeSubscriptions) add(sub database.Subscription) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	sub.ID = uint(len(r.subs) + 1)
+	r.subs = append(r.subs, sub)
+}
+
+func (r *fakeSubscriptions) update(userID, subscriptionID uint, apply func(sub *database.Subscription)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.subs {
+		if r.subs[i].ID == subscriptionID && r.subs[i].UserID == userID {
+			apply(&r.subs[i])
+			return nil
+		}
+	}
+	return errors.New("subscription not found")
+}
+
+// get возвращает подписку пользователя на тему.
+func (r *fakeSubscriptions) get(userID uint, topic string) (database.Subscription, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, sub := range r.subs {
+		if sub.UserID == userID && sub.Topic == topic {
+			return sub, true
+		}
+	}
+	return database.Subscription{}, false
+}
+
+// fakeSentArticles хранит отметки об отправке в памяти.
+type fakeSentArticles struct {
+	mu   sync.Mutex
+	sent map[uint]map[string]bool
+}
+
+func (r *fakeSentArticles) IsArticleSent(ctx context.Context, userID uint, articleHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.sent[userID][articleHash], nil
+}
+
+func (r *fakeSentArticles) MarkArticleAsSent(ctx context.Context, userID uint, articleHash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.sent == nil {
+		r.sent = make(map[uint]map[string]bool)
+	}
+	if r.sent[userID] == nil {
+		r.sent[userID] = make(map[string]bool)
+	}
+	r.sent[userID][articleHash] = true
+	return nil
+}
+
+func (r *fakeSentArticles) ResetSentArticlesHistory(ctx context.Context, userID uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.sent, userID)
+	return nil
+}
+
+// fakeFavorites хранит избранное в памяти.
+type fakeFavorites struct {
+	mu        sync.Mutex
+	favorites []database.FavoriteArticle
+}
+
+func (r *fakeFavorites) AddFavoriteArticle(ctx context.Context, userID uint, articleKey, articleURL string, title string, source string, publishedAt time.Time) error {
+	if favorite, _ := r.IsFavoriteArticle(ctx, userID, articleKey); favorite {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.favorites = append(r.favorites, database.FavoriteArticle{UserID: userID, ArticleKey: articleKey, ArticleURL: articleURL,
+		Title: title, Source: source, PublishedAt: publishedAt, AddedAt: time.Now()})
+	return nil
+}
+
+func (r *fakeFavorites) RemoveFavoriteArticle(ctx context.Context, userID uint, articleKey string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.favorites = slices.DeleteFunc(r.favorites, func(favorite database.FavoriteArticle) bool {
+		return favorite.UserID == userID && favorite.ArticleKey == articleKey
+	})
+	return nil
+}
+
+func (r *fakeFavorites) GetUserFavoriteArticles(ctx context.Context, userID uint) ([]database.FavoriteArticle, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var favorites []database.FavoriteArticle
+	for _, favorite := range r.favorites {
+		if favorite.UserID == userID {
+			favorites = append(favorites, favorite)
+		}
+	}
+	return favorites, nil
+}
+
+func (r *fakeFavorites) IsFavoriteArticle(ctx context.Context, userID uint, articleKey string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.ContainsFunc(r.favorites, func(favorite database.FavoriteArticle) bool {
+		return favorite.UserID == userID && favorite.ArticleKey == articleKey
+	}), nil
+}
+
+// fakeOutbox хранит очередь сообщений в памяти.
+type fakeOutbox struct {
+	mu       sync.Mutex
+	nextID   uint
+	messages []database.OutboxMessage
+}
+
+func (r *fakeOutbox) EnqueueOutboxMessage(ctx context.Context, msg *database.OutboxMessage) (bool, error) {
+	if queued, _ := r.IsArticleQueued(ctx, msg.UserID, msg.ArticleHash); queued {
+		return false, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	msg.ID = r.nextID
+	msg.Status = database.OutboxStatusPending
+	if msg.NextAttemptAt.IsZero() {
+		msg.NextAttemptAt = time.Now()
+	}
+	msg.UpdatedAt = time.Now()
+	r.messages = append(r.messages, *msg)
+	return true, nil
+}
+
+func (r *fakeOutbox) IsArticleQueued(ctx context.Context, userID uint, articleHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, msg := range r.messages {
+		if msg.UserID != userID {
+			continue
+		}
+		if msg.ArticleHash == articleHash ||
+			msg.Status == database.OutboxStatusPending && slices.Contains(database.SplitDuplicates(msg.Duplicates), articleHash) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeOutbox) GetDueOutboxMessages(ctx context.Context, userID uint, now time.Time, limit int) ([]database.OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var due []database.OutboxMessage
+	for _, msg := range r.messages {
+		if msg.Status == database.OutboxStatusPending && !msg.NextAttemptAt.After(now) &&
+			(userID == 0 || msg.UserID == userID) && len(due) < limit {
+			due = append(due, msg)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeOutbox) ClaimOutboxMessage(ctx context.Context, id uint, now, leaseUntil time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.messages {
+		msg := &r.messages[i]
+		if msg.ID == id && msg.Status == database.OutboxStatusPending && !msg.NextAttemptAt.After(now) {
+			msg.NextAttemptAt = leaseUntil
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeOutbox) MarkOutboxMessageDelivered(ctx context.Context, id uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = slices.DeleteFunc(r.messages, func(msg database.OutboxMessage) bool { return msg.ID == id })
+	return nil
+}
+
+func (r *fakeOutbox) MarkOutboxMessageFailed(ctx context.Context, id uint, lastError string, nextAttemptAt time.Time, giveUp bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.messages {
+		msg := &r.messages[i]
+		if msg.ID != id {
+			continue
+		}
+		msg.Attempts++
+		msg.LastError = lastError
+		msg.NextAttemptAt = nextAttemptAt
+		msg.UpdatedAt = time.Now()
+		if giveUp {
+			msg.Status = database.OutboxStatusFailed
+		}
+	}
+	return nil
+}
+
+func (r *fakeOutbox) PruneFailedOutboxMessages(ctx context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := len(r.messages)
+	r.messages = slices.DeleteFunc(r.messages, func(msg database.OutboxMessage) bool {
+		return msg.Status == database.OutboxStatusFailed && msg.UpdatedAt.Before(before)
+	})
+	return int64(count - len(r.messages)), nil
+}
+
+func (r *fakeOutbox) CountPendingOutboxMessages(ctx context.Context) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var count int64
+	for _, msg := range r.messages {
+		if msg.Status == database.OutboxStatusPending {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeOutbox) ListOutboxMessages(ctx context.Context, filter database.OutboxFilter, limit int) ([]database.OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var messages []database.OutboxMessage
+	for _, msg := range r.messages {
+		if (filter.Status == "" || msg.Status == filter.Status) && (filter.UserID == 0 || msg.UserID == filter.UserID) &&
+			len(messages) < limit {
+			messages = append(messages, msg)
+		}
+	}
+	return messages, nil
+}
+
+// makeDue переносит время следующей попытки всех сообщений в прошлое.
+func (r *fakeOutbox) makeDue() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.messages {
+		r.messages[i].NextAttemptAt = time.Now().Add(-time.Second)
+	}
+}
+
+// list возвращает копию очереди.
+func (r *fakeOutbox) list() []database.OutboxMessage {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.messages)
+}
+
+// fakeDigest хранит новости сводок в памяти.
+type fakeDigest struct {
+	mu     sync.Mutex
+	nextID uint
+	items  []database.DigestItem
+}
+
+func (r *fakeDigest) AddDigestItem(ctx context.Context, item *database.DigestItem) (bool, error) {
+	if exists, _ := r.IsArticleInDigest(ctx, item.UserID, item.ArticleHash); exists {
+		return false, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	item.ID = r.nextID
+	r.items = append(r.items, *item)
+	return true, nil
+}
+
+func (r *fakeDigest) IsArticleInDigest(ctx context.Context, userID uint, articleHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, item := range r.items {
+		if item.UserID == userID &&
+			(item.ArticleHash == articleHash || slices.Contains(database.SplitDuplicates(item.Duplicates), articleHash)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeDigest) GetDigestItems(ctx context.Context, userID uint) ([]database.DigestItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var items []database.DigestItem
+	for _, item := range r.items {
+		if item.UserID == userID {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (r *fakeDigest) DeleteDigestItems(ctx context.Context, ids []uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items = slices.DeleteFunc(r.items, func(item database.DigestItem) bool { return slices.Contains(ids, item.ID) })
+	return nil
+}
+
+// Проверяем, что репозитории в памяти реализуют интерфейсы целиком
+var (
+	_ database.UserRepository            = (*fakeUsers)(nil)
+	_ database.SubscriptionRepository    = (*fakeSubscriptions)(nil)
+	_ database.SentArticleRepository     = (*fakeSentArticles)(nil)
+	_ database.FavoriteArticleRepository = (*fakeFavorites)(nil)
+	_ database.OutboxRepository          = (*fakeOutbox)(nil)
+	_ database.DigestRepository          = (*fakeDigest)(nil)
+)
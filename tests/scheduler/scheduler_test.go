@@ -0,0 +1,245 @@
+package scheduler_test
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/scheduler"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/sender"
+)
+
+// telegramStub запоминает доставленные сообщения. Если задана ошибка, все запросы завершаются ею.
+type telegramStub struct {
+	mu       sync.Mutex
+	messages []tgbotapi.MessageConfig
+	err      error
+}
+
+func (a *telegramStub) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.err != nil {
+		return nil, a.err
+	}
+	if msg, ok := c.(tgbotapi.MessageConfig); ok {
+		a.messages = append(a.messages, msg)
+	}
+	return &tgbotapi.APIResponse{Ok: true, Result: []byte(`{"message_id": 1}`)}, nil
+}
+
+func (a *telegramStub) setError(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.err = err
+}
+
+// sent возвращает копию доставленных сообщений.
+func (a *telegramStub) sent() []tgbotapi.MessageConfig {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]tgbotapi.MessageConfig(nil), a.messages...)
+}
+
+// errBlocked - ответ Telegram для пользователя, заблокировавшего бота.
+var errBlocked = &tgbotapi.Error{Code: http.StatusForbidden, Message: "Forbidden: bot was blocked by the user"}
+
+// errServer - временная ошибка Telegram.
+var errServer = &tgbotapi.Error{Code: http.StatusBadGateway, Message: "Bad Gateway"}
+
+// topicProvider отвечает заранее заданными статьями или ошибкой по теме запроса
+// и считает запросы по каждой теме. Если задан block, ответ задерживается до его закрытия.
+type topicProvider struct {
+	mu       sync.Mutex
+	articles map[string][]fetcher.Article
+	errs     map[string]error
+	calls    map[string]int
+	block    chan struct{}
+}
+
+func (p *topicProvider) Name() string { return "stub" }
+
+func (p *topicProvider) Capabilities() fetcher.Capabilities {
+	return fetcher.Capabilities{MaxResults: 10}
+}
+
+func (p *topicProvider) Search(ctx context.Context, req fetcher.SearchRequest) ([]fetcher.Article, error) {
+	p.mu.Lock()
+	p.calls[req.Query]++
+	block := p.block
+	p.mu.Unlock()
+
+	if block != nil {
+		select {
+		case <-block:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.articles[req.Query], p.errs[req.Query]
+}
+
+// fetches возвращает число запросов по теме.
+func (p *topicProvider) fetches(topic string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls[topic]
+}
+
+type fixture struct {
+	api       *telegramStub
+	provider  *topicProvider
+	scheduler *scheduler.Scheduler
+	users     *fakeUsers
+	subs      *fakeSubscriptions
+	sent      *fakeSentArticles
+	outbox    *fakeOutbox
+	digest    *fakeDigest
+}
+
+func newFixture(t *testing.T, options scheduler.Options) *fixture {
+	t.Helper()
+	provider := &topicProvider{articles: make(map[string][]fetcher.Article), errs: make(map[string]error), calls: make(map[string]int)}
+	registry := fetcher.NewRegistry()
+	if err := registry.Register(provider); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	users := &fakeUsers{}
+	f := &fixture{
+		api:      &telegramStub{},
+		provider: provider,
+		users:    users,
+		subs:     &fakeSubscriptions{users: users},
+		sent:     &fakeSentArticles{},
+		outbox:   &fakeOutbox{},
+		digest:   &fakeDigest{},
+	}
+	s := sender.New(f.api, sender.Config{GlobalRate: 1000, GlobalBurst: 1000, ChatRate: 1000, ChatBurst: 1000})
+	f.scheduler = scheduler.NewScheduler(s, f.users, f.subs, f.sent, &fakeFavorites{}, f.outbox, f.digest,
+		fetcher.NewFetcher(registry), options)
+	t.Cleanup(f.scheduler.Stop)
+	return f
+}
+
+// addUser сохраняет пользователя, подписанного на topics, и возвращает его с присвоенным ID.
+func (f *fixture) addUser(user database.User, topics ...string) database.User {
+	user = f.users.add(user)
+	for _, topic := range topics {
+		f.subs.add(database.Subscription{UserID: user.ID, Topic: topic})
+	}
+	return user
+}
+
+// addSubscription сохраняет подписку с собственными настройками.
+func (f *fixture) addSubscription(sub database.Subscription) {
+	f.subs.add(sub)
+}
+
+// user возвращает сохраненное состояние пользователя.
+func (f *fixture) user(t *testing.T, userID uint) database.User {
+	t.Helper()
+	user, ok := f.users.get(userID)
+	if !ok {
+		t.Fatalf("user %d not found", userID)
+	}
+	return user
+}
+
+// subscription возвращает сохраненное состояние подписки пользователя на тему.
+func (f *fixture) subscription(t *testing.T, userID uint, topic string) database.Subscription {
+	t.Helper()
+	sub, ok := f.subs.get(userID, topic)
+	if !ok {
+		t.Fatalf("subscription %q of user %d not found", topic, userID)
+	}
+	return sub
+}
+
+// setArticles задает ответ провайдера по теме.
+func (f *fixture) setArticles(topic string, articles ...fetcher.Article) {
+	f.provider.mu.Lock()
+	defer f.provider.mu.Unlock()
+	f.provider.articles[topic] = articles
+}
+
+// setFetchError задает ошибку провайдера по теме.
+func (f *fixture) setFetchError(topic string, err error) {
+	f.provider.mu.Lock()
+	defer f.provider.mu.Unlock()
+	f.provider.errs[topic] = err
+}
+
+// blockFetches задерживает ответы провайдера до вызова возвращенной функции.
+func (f *fixture) blockFetches() (release func()) {
+	block := make(chan struct{})
+	f.provider.mu.Lock()
+	defer f.provider.mu.Unlock()
+	f.provider.block = block
+	return sync.OnceFunc(func() { close(block) })
+}
+
+// isSent проверяет, помечена ли статья как отправленная пользователю.
+func (f *fixture) isSent(userID uint, articleURL string) bool {
+	sent, _ := f.sent.IsArticleSent(context.Background(), userID, fetcher.ArticleKey(articleURL))
+	return sent
+}
+
+// wantSent проверяет, что статьи помечены как отправленные пользователю.
+func (f *fixture) wantSent(t *testing.T, userID uint, urls ...string) {
+	t.Helper()
+	for _, url := range urls {
+		if !f.isSent(userID, url) {
+			t.Errorf("article %s is not marked as sent", url)
+		}
+	}
+}
+
+// wantNotSent проверяет, что статьи не помечены как отправленные пользователю.
+func (f *fixture) wantNotSent(t *testing.T, userID uint, urls ...string) {
+	t.Helper()
+	for _, url := range urls {
+		if f.isSent(userID, url) {
+			t.Errorf("article %s is marked as sent", url)
+		}
+	}
+}
+
+// wantMessages проверяет количество сообщений, принятых Telegram, и возвращает их.
+func (f *fixture) wantMessages(t *testing.T, want int) []tgbotapi.MessageConfig {
+	t.Helper()
+	sent := f.api.sent()
+	if len(sent) != want {
+		t.Fatalf("sent messages = %d, want %d", len(sent), want)
+	}
+	return sent
+}
+
+// waitMessages ждет, пока Telegram примет хотя бы want сообщений.
+func (f *fixture) waitMessages(t *testing.T, want int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(f.api.sent()) < want {
+		if time.Now().After(deadline) {
+			t.Fatalf("sent messages = %d, want %d", len(f.api.sent()), want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func newsArticle(title, source, url string) fetcher.Article {
+	return fetcher.Article{
+		Title:       title,
+		URL:         url,
+		PublishedAt: time.Now().Add(-time.Hour),
+		Source:      fetcher.Source{Name: source},
+	}
+}
@@ -0,0 +1,117 @@
+package scheduling_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/scheduling"
+)
+
+func mustParse(t *testing.T, expr string) *scheduling.Schedule {
+	t.Helper()
+	schedule, err := scheduling.Parse(expr)
+	if err != nil {
+		t.Fatalf("Parse(%q) error = %v", expr, err)
+	}
+	return schedule
+}
+
+func TestScheduleNext(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skipf("Time zone data is not available: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		expr  string
+		after time.Time
+		want  time.Time
+	}{
+		{
+			name:  "weekday morning",
+			expr:  "0 9,18 * * 1-5",
+			after: time.Date(2024, 3, 4, 8, 30, 0, 0, moscow), // понедельник
+			want:  time.Date(2024, 3, 4, 9, 0, 0, 0, moscow),
+		},
+		{
+			name:  "weekday evening",
+			expr:  "0 9,18 * * 1-5",
+			after: time.Date(2024, 3, 4, 9, 0, 0, 0, moscow),
+			want:  time.Date(2024, 3, 4, 18, 0, 0, 0, moscow),
+		},
+		{
+			name:  "skips weekend",
+			expr:  "0 9,18 * * 1-5",
+			after: time.Date(2024, 3, 8, 18, 0, 0, 0, moscow), // пятница
+			want:  time.Date(2024, 3, 11, 9, 0, 0, 0, moscow),
+		},
+		{
+			name:  "step",
+			expr:  "*/15 * * * *",
+			after: time.Date(2024, 3, 4, 10, 7, 30, 0, moscow),
+			want:  time.Date(2024, 3, 4, 10, 15, 0, 0, moscow),
+		},
+		{
+			name:  "sunday as seven",
+			expr:  "0 12 * * 7",
+			after: time.Date(2024, 3, 4, 0, 0, 0, 0, moscow),
+			want:  time.Date(2024, 3, 10, 12, 0, 0, 0, moscow),
+		},
+		{
+			name:  "day of month or weekday",
+			expr:  "0 10 1 * 3",
+			after: time.Date(2024, 2, 26, 0, 0, 0, 0, moscow), // понедельник
+			want:  time.Date(2024, 2, 28, 10, 0, 0, 0, moscow),
+		},
+		{
+			name:  "macro",
+			expr:  "@weekdays",
+			after: time.Date(2024, 3, 9, 12, 0, 0, 0, moscow), // суббота
+			want:  time.Date(2024, 3, 11, 9, 0, 0, 0, moscow),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mustParse(t, tt.expr).Next(tt.after)
+			if !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleNextImpossibleDate(t *testing.T) {
+	if next := mustParse(t, "0 9 31 2 *").Next(time.Now()); !next.IsZero() {
+		t.Errorf("Expected zero time for February 31st, got %v", next)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"0 9 * *",
+		"60 9 * * *",
+		"0 24 * * *",
+		"0 9 * * 8",
+		"0 9-5 * * *",
+		"*/0 * * * *",
+		"a b c d e",
+		"@never",
+	} {
+		if _, err := scheduling.Parse(expr); err == nil {
+			t.Errorf("Parse(%q) should fail", expr)
+		}
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	hour, minute, err := scheduling.ParseClock("07:45")
+	if err != nil || hour != 7 || minute != 45 {
+		t.Errorf("ParseClock() = %d, %d, %v; want 7, 45", hour, minute, err)
+	}
+	if _, _, err := scheduling.ParseClock("25:00"); err == nil {
+		t.Error("ParseClock(25:00) should fail")
+	}
+}
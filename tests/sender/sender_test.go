@@ -0,0 +1,172 @@
+package sender_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/sender"
+)
+
+// fakeAPI записывает время каждого запроса и может вернуть заранее заданные ошибки.
+type fakeAPI struct {
+	mu     sync.Mutex
+	calls  []time.Time
+	errors []error
+}
+
+func (f *fakeAPI) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, time.Now())
+	if len(f.errors) > 0 {
+		err := f.errors[0]
+		f.errors = f.errors[1:]
+		if err != nil {
+			return &tgbotapi.APIResponse{Ok: false}, err
+		}
+	}
+	return &tgbotapi.APIResponse{Ok: true, Result: []byte(`{"message_id": 1}`)}, nil
+}
+
+func TestSenderPerChatLimit(t *testing.T) {
+	api := &fakeAPI{}
+	s := sender.New(api, sender.Config{GlobalRate: 1000, GlobalBurst: 1000, ChatRate: 20, ChatBurst: 1})
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := s.Send(ctx, tgbotapi.NewMessage(1, "text")); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	// Три сообщения в один чат при 20 сообщениях в секунду занимают не меньше 100 мс
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Messages to one chat were not throttled: %v", elapsed)
+	}
+
+	// Сообщение в другой чат не ждет лимита первого чата
+	start = time.Now()
+	if _, err := s.Send(ctx, tgbotapi.NewMessage(2, "text")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 40*time.Millisecond {
+		t.Errorf("Message to another chat was delayed: %v", elapsed)
+	}
+}
+
+func TestSenderGlobalLimit(t *testing.T) {
+	api := &fakeAPI{}
+	s := sender.New(api, sender.Config{GlobalRate: 20, GlobalBurst: 2, ChatRate: 1000, ChatBurst: 1000})
+	ctx := context.Background()
+
+	start := time.Now()
+	for chatID := int64(1); chatID <= 4; chatID++ {
+		if _, err := s.Send(ctx, tgbotapi.NewMessage(chatID, "text")); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	// Серия из двух сообщений проходит сразу, еще два ждут по 50 мс
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("Global limit was not applied: %v", elapsed)
+	}
+}
+
+func TestSenderRetryAfter(t *testing.T) {
+	api := &fakeAPI{errors: []error{&tgbotapi.Error{
+		Code:               429,
+		Message:            "Too Many Requests: retry after 1",
+		ResponseParameters: tgbotapi.ResponseParameters{RetryAfter: 1},
+	}}}
+	s := sender.New(api, sender.DefaultConfig())
+
+	if _, err := s.Send(context.Background(), tgbotapi.NewMessage(1, "text")); err != nil {
+		t.Fatalf("Send() should succeed after retry, got %v", err)
+	}
+	if len(api.calls) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(api.calls))
+	}
+	if gap := api.calls[1].Sub(api.calls[0]); gap < 900*time.Millisecond {
+		t.Errorf("Retry happened before retry_after elapsed: %v", gap)
+	}
+}
+
+func TestSenderReturnsOtherErrors(t *testing.T) {
+	api := &fakeAPI{errors: []error{&tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}}}
+	s := sender.New(api, sender.DefaultConfig())
+
+	_, err := s.Send(context.Background(), tgbotapi.NewMessage(1, "text"))
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) || tgErr.Code != 403 {
+		t.Fatalf("Send() error = %v, want Telegram 403 error", err)
+	}
+	if len(api.calls) != 1 {
+		t.Errorf("Non-429 errors should not be retried, got %d requests", len(api.calls))
+	}
+}
+
+func TestSenderContextCancel(t *testing.T) {
+	api := &fakeAPI{}
+	s := sender.New(api, sender.Config{GlobalRate: 1000, GlobalBurst: 1000, ChatRate: 0.5, ChatBurst: 1})
+
+	if _, err := s.Send(context.Background(), tgbotapi.NewMessage(1, "first")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := s.Send(ctx, tgbotapi.NewMessage(1, "second")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Send() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestSenderContextCancelReleasesSlot(t *testing.T) {
+	api := &fakeAPI{}
+	s := sender.New(api, sender.Config{GlobalRate: 1000, GlobalBurst: 1000, ChatRate: 2, ChatBurst: 1})
+
+	start := time.Now()
+	if _, err := s.Send(context.Background(), tgbotapi.NewMessage(1, "first")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := s.Send(ctx, tgbotapi.NewMessage(1, "cancelled")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Send() error = %v, want context.DeadlineExceeded", err)
+	}
+
+	// Отмененное сообщение не занимает место: следующее уходит через один интервал (500 мс), а не через два
+	if _, err := s.Send(context.Background(), tgbotapi.NewMessage(1, "third")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 800*time.Millisecond {
+		t.Errorf("Cancelled message kept its slot: next message sent after %v", elapsed)
+	}
+}
+
+func TestSenderInvalidConfigUsesDefaults(t *testing.T) {
+	api := &fakeAPI{}
+	s := sender.New(api, sender.Config{GlobalRate: -1, ChatRate: 0})
+	defaults := sender.DefaultConfig()
+
+	// Серия по умолчанию проходит без ожидания
+	start := time.Now()
+	for i := 0; i < defaults.ChatBurst; i++ {
+		if _, err := s.Send(context.Background(), tgbotapi.NewMessage(1, "text")); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Default burst was throttled: %v", elapsed)
+	}
+
+	// Сообщение сверх серии ждет лимита чата по умолчанию (одно сообщение в секунду)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := s.Send(ctx, tgbotapi.NewMessage(1, "text")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Send() error = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,282 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/database"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/fetcher"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/server"
+	"gorm.io/gorm"
+)
+
+const adminToken = "admin-token-for-tests"
+
+// fakeProcessor запоминает пользователей, которым запрошена рассылка.
+type fakeProcessor struct {
+	users []database.User
+	force bool
+}
+
+func (p *fakeProcessor) ProcessUser(ctx context.Context, user database.User, force bool) int {
+	p.users = append(p.users, user)
+	p.force = force
+	return 2
+}
+
+// staticProvider - провайдер новостей без сетевых запросов.
+type staticProvider struct{ name string }
+
+func (p staticProvider) Name() string { return p.name }
+func (p staticProvider) Search(ctx context.Context, req fetcher.SearchRequest) ([]fetcher.Article, error) {
+	return nil, nil
+}
+func (p staticProvider) Capabilities() fetcher.Capabilities { return fetcher.Capabilities{} }
+
+type adminFixture struct {
+	handler   http.Handler
+	processor *fakeProcessor
+}
+
+func newAdminFixture(t *testing.T) *adminFixture {
+	t.Helper()
+	db, err := gorm.Open(database.NewSQLiteDialector(":memory:"), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("Failed to connect to test database: %v", err)
+	}
+	if err := db.AutoMigrate(&database.User{}, &database.Subscription{}, &database.OutboxMessage{}); err != nil {
+		t.Fatalf("Failed to migrate test database: %v", err)
+	}
+
+	userRepo := database.NewUserRepository(db)
+	ctx := context.Background()
+	for _, u := range []struct {
+		id       int64
+		username string
+	}{{1001, "ivan_petrov"}, {1002, "maria"}, {1003, "ivanova"}} {
+		if _, err := userRepo.FindOrCreateUser(ctx, u.id, u.username, "Test", ""); err != nil {
+			t.Fatalf("Failed to create user: %v", err)
+		}
+	}
+
+	registry := fetcher.NewRegistry()
+	for _, name := range []string{"gnews", "rss"} {
+		if err := registry.Register(staticProvider{name: name}); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+	}
+
+	processor := &fakeProcessor{}
+	api := server.NewAdminAPI(server.AdminConfig{Token: adminToken}, userRepo, database.NewSubscriptionRepository(db),
+		database.NewOutboxRepository(db), processor, registry, fetcher.NewFetcher(registry))
+	return &adminFixture{handler: api.Handler(), processor: processor}
+}
+
+// do выполняет запрос с токеном администратора и декодирует JSON-ответ в out.
+func (f *adminFixture) do(t *testing.T, method, path, body string, out any) int {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Authorization", "Bearer "+adminToken)
+	rec := httptest.NewRecorder()
+	f.handler.ServeHTTP(rec, req)
+	if out != nil && rec.Body.Len() > 0 {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			t.Fatalf("%s %s: failed to decode %q: %v", method, path, rec.Body.String(), err)
+		}
+	}
+	return rec.Code
+}
+
+func TestAdminAPIRequiresToken(t *testing.T) {
+	f := newAdminFixture(t)
+
+	for _, header := range []string{"", "Bearer wrong-token", adminToken} {
+		req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		f.handler.ServeHTTP(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Errorf("Authorization %q: status = %d, want 401", header, rec.Code)
+		}
+	}
+}
+
+func TestAdminAPIUsers(t *testing.T) {
+	f := newAdminFixture(t)
+
+	var page struct {
+		Users []struct {
+			TelegramID int64  `json:"telegram_id"`
+			Username   string `json:"username"`
+		} `json:"users"`
+		NextAfterID uint `json:"next_after_id"`
+	}
+	if code := f.do(t, http.MethodGet, "/api/users?q=ivan", "", &page); code != http.StatusOK {
+		t.Fatalf("GET /api/users?q=ivan status = %d", code)
+	}
+	if len(page.Users) != 2 || page.Users[0].Username != "ivan_petrov" || page.Users[1].Username != "ivanova" {
+		t.Errorf("Search result = %+v, want ivan_petrov and ivanova", page.Users)
+	}
+	// Курсор применяется к результатам поиска, а не к отдельным условиям
+	if f.do(t, http.MethodGet, "/api/users?q=ivan&after_id=1", "", &page); len(page.Users) != 1 || page.Users[0].Username != "ivanova" {
+		t.Errorf("Search after cursor = %+v, want ivanova", page.Users)
+	}
+
+	// Постраничный обход по курсору next_after_id
+	if code := f.do(t, http.MethodGet, "/api/users?limit=2", "", &page); code != http.StatusOK || len(page.Users) != 2 || page.NextAfterID == 0 {
+		t.Fatalf("First page = %d %+v", code, page)
+	}
+	cursor := page.NextAfterID
+	page.NextAfterID = 0
+	if f.do(t, http.MethodGet, "/api/users?limit=2&after_id="+strconv.FormatUint(uint64(cursor), 10), "", &page); len(page.Users) != 1 || page.NextAfterID != 0 {
+		t.Errorf("Last page = %+v, want one user without cursor", page)
+	}
+
+	if code := f.do(t, http.MethodGet, "/api/users/999", "", nil); code != http.StatusNotFound {
+		t.Errorf("GET unknown user status = %d, want 404", code)
+	}
+}
+
+func TestAdminAPIUserSettings(t *testing.T) {
+	f := newAdminFixture(t)
+
+	var user struct {
+		NewsLimit       uint   `json:"news_limit"`
+		TimeZone        string `json:"time_zone"`
+		QuietHoursStart string `json:"quiet_hours_start"`
+		Schedule        string `json:"schedule"`
+	}
+	body := `{"news_limit": 10, "time_zone": "Europe/Moscow", "quiet_hours_start": "23:00", "quiet_hours_end": "07:00", "schedule": "@weekdays"}`
+	if code := f.do(t, http.MethodPatch, "/api/users/1001/settings", body, &user); code != http.StatusOK {
+		t.Fatalf("PATCH settings status = %d", code)
+	}
+	if user.NewsLimit != 10 || user.TimeZone != "Europe/Moscow" || user.QuietHoursStart != "23:00" || user.Schedule == "" {
+		t.Errorf("Updated user = %+v", user)
+	}
+
+	for _, invalid := range []string{
+		`{"time_zone": "Mars/Olympus"}`,
+		`{"quiet_hours_start": "25:00"}`,
+		`{"digest_period": "weekly"}`, // Пользователь в режиме instant
+		`{"news_limt": 5}`,
+	} {
+		if code := f.do(t, http.MethodPatch, "/api/users/1001/settings", invalid, nil); code != http.StatusBadRequest {
+			t.Errorf("PATCH %s status = %d, want 400", invalid, code)
+		}
+	}
+}
+
+func TestAdminAPISubscriptions(t *testing.T) {
+	f := newAdminFixture(t)
+
+	var subs []struct {
+		ID    uint   `json:"id"`
+		Topic string `json:"topic"`
+		Muted bool   `json:"muted"`
+	}
+	if code := f.do(t, http.MethodPost, "/api/users/1002/subscriptions", `{"topic": "Наука"}`, &subs); code != http.StatusCreated {
+		t.Fatalf("POST subscription status = %d", code)
+	}
+	if len(subs) != 1 || subs[0].Topic != "наука" {
+		t.Fatalf("Subscriptions = %+v, want наука", subs)
+	}
+	if code := f.do(t, http.MethodPost, "/api/users/1002/subscriptions", `{"topic": "наука"}`, nil); code != http.StatusConflict {
+		t.Errorf("Duplicate subscription status = %d, want 409", code)
+	}
+
+	path := "/api/users/1002/subscriptions/" + strconv.FormatUint(uint64(subs[0].ID), 10)
+	var sub struct {
+		Muted bool `json:"muted"`
+	}
+	if code := f.do(t, http.MethodPatch, path, `{"muted": true}`, &sub); code != http.StatusOK || !sub.Muted {
+		t.Errorf("PATCH subscription = %d %+v, want muted", code, sub)
+	}
+	// Чужую подписку нельзя изменить через другого пользователя
+	if code := f.do(t, http.MethodDelete, "/api/users/1001/subscriptions/"+strconv.FormatUint(uint64(subs[0].ID), 10), "", nil); code != http.StatusNotFound {
+		t.Errorf("DELETE foreign subscription status = %d, want 404", code)
+	}
+	if code := f.do(t, http.MethodDelete, path, "", nil); code != http.StatusNoContent {
+		t.Errorf("DELETE subscription status = %d, want 204", code)
+	}
+	if f.do(t, http.MethodGet, "/api/users/1002/subscriptions", "", &subs); len(subs) != 0 {
+		t.Errorf("Subscriptions after delete = %+v", subs)
+	}
+}
+
+func TestAdminAPIDuplicateSubscription(t *testing.T) {
+	f := newAdminFixture(t)
+
+	if code := f.do(t, http.MethodPost, "/api/users/1002/subscriptions", `{"topic": "космос"}`, nil); code != http.StatusCreated {
+		t.Fatalf("POST subscription status = %d", code)
+	}
+	var body struct {
+		Error string `json:"error"`
+	}
+	if code := f.do(t, http.MethodPost, "/api/users/1002/subscriptions", `{"topic": "Космос"}`, &body); code != http.StatusConflict {
+		t.Fatalf("Duplicate subscription status = %d, want 409", code)
+	}
+	if body.Error != `пользователь уже подписан на "космос"` {
+		t.Errorf("Duplicate subscription error = %q", body.Error)
+	}
+}
+
+func TestAdminAPIProcessUser(t *testing.T) {
+	f := newAdminFixture(t)
+
+	var result struct {
+		Sent int `json:"sent"`
+	}
+	if code := f.do(t, http.MethodPost, "/api/users/1003/process", "", &result); code != http.StatusOK || result.Sent != 2 {
+		t.Fatalf("POST process = %d %+v", code, result)
+	}
+	if len(f.processor.users) != 1 || f.processor.users[0].TelegramID != 1003 || !f.processor.force {
+		t.Errorf("ProcessUser calls = %+v force=%v, want user 1003 with force", f.processor.users, f.processor.force)
+	}
+}
+
+func TestAdminAPIOutboxAndProviders(t *testing.T) {
+	f := newAdminFixture(t)
+
+	var outbox struct {
+		Pending  int64             `json:"pending"`
+		Messages []json.RawMessage `json:"messages"`
+	}
+	if code := f.do(t, http.MethodGet, "/api/outbox?status=pending", "", &outbox); code != http.StatusOK || outbox.Pending != 0 || outbox.Messages == nil {
+		t.Errorf("GET /api/outbox = %d %+v", code, outbox)
+	}
+	if code := f.do(t, http.MethodGet, "/api/outbox?status=sent", "", nil); code != http.StatusBadRequest {
+		t.Errorf("GET /api/outbox?status=sent status = %d, want 400", code)
+	}
+
+	var provider struct {
+		Name    string `json:"name"`
+		Enabled bool   `json:"enabled"`
+	}
+	if code := f.do(t, http.MethodPatch, "/api/providers/GNews", `{"enabled": false}`, &provider); code != http.StatusOK || provider.Name != "gnews" || provider.Enabled {
+		t.Errorf("PATCH provider = %d %+v, want gnews disabled", code, provider)
+	}
+	var providers []struct {
+		Name    string `json:"name"`
+		Enabled bool   `json:"enabled"`
+		Breaker string `json:"breaker"`
+	}
+	f.do(t, http.MethodGet, "/api/providers", "", &providers)
+	if len(providers) != 2 || providers[0].Enabled || !providers[1].Enabled || providers[1].Breaker != "closed" {
+		t.Errorf("GET /api/providers = %+v", providers)
+	}
+	if code := f.do(t, http.MethodPatch, "/api/providers/unknown", `{"enabled": true}`, nil); code != http.StatusNotFound {
+		t.Errorf("PATCH unknown provider status = %d, want 404", code)
+	}
+}
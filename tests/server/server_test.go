@@ -0,0 +1,158 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/bot/server"
+	"github.com/vladislavdragonenkov/news-telegram-bot/internal/pkg/metrics"
+)
+
+const testToken = "123:test-token"
+
+func newTestServer(secret string) http.Handler {
+	bot := &tgbotapi.BotAPI{Token: testToken}
+	return server.New(bot, nil, server.Config{SecretToken: secret}).Handler()
+}
+
+func TestWebhookRejectsInvalidSecret(t *testing.T) {
+	handler := newTestServer("s3cret")
+
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{name: "missing header", header: ""},
+		{name: "wrong secret", header: "other"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/"+testToken, strings.NewReader(`{"update_id": 1}`))
+			if tt.header != "" {
+				req.Header.Set("X-Telegram-Bot-Api-Secret-Token", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("Expected status 401, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+func TestWebhookRejectsMalformedUpdate(t *testing.T) {
+	handler := newTestServer("s3cret")
+
+	req := httptest.NewRequest(http.MethodPost, "/"+testToken, strings.NewReader(`not json`))
+	req.Header.Set("X-Telegram-Bot-Api-Secret-Token", "s3cret")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	// Секрет верный, поэтому запрос доходит до разбора обновления
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestHealthEndpoint(t *testing.T) {
+	handler := newTestServer("s3cret")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	if rec.Code != http.StatusOK || rec.Body.String() != "OK" {
+		t.Errorf("Unexpected health response: %d %q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestObservabilityServesMetrics(t *testing.T) {
+	registry := metrics.NewRegistry()
+	registry.NewCounter("test_requests_total", "Запросы.").Inc()
+	handler := server.NewObservability(":0", registry).Handler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "test_requests_total 1") {
+		t.Errorf("GET /metrics = %d %q", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST /metrics status = %d, want 405", rec.Code)
+	}
+}
+
+func TestObservabilityLivez(t *testing.T) {
+	obs := server.NewObservability(":0", metrics.NewRegistry())
+	obs.AddCheck("database", func(ctx context.Context) error { return errors.New("недоступна") })
+
+	rec := httptest.NewRecorder()
+	obs.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	// Живость не зависит от проверок готовности
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /livez status = %d, want 200", rec.Code)
+	}
+	var report server.HealthReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode /livez: %v", err)
+	}
+	if report.Status != server.StatusOK {
+		t.Errorf("status = %q, want %q", report.Status, server.StatusOK)
+	}
+}
+
+func TestObservabilityReadyz(t *testing.T) {
+	obs := server.NewObservability(":0", metrics.NewRegistry())
+	providersErr := errors.New("все провайдеры недоступны")
+	var failProviders bool
+	obs.AddCheck("database", func(ctx context.Context) error { return nil })
+	obs.AddCheck("providers", func(ctx context.Context) error {
+		if failProviders {
+			return providersErr
+		}
+		return nil
+	})
+
+	readyz := func() (int, server.HealthReport) {
+		rec := httptest.NewRecorder()
+		obs.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var report server.HealthReport
+		if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+			t.Fatalf("failed to decode /readyz: %v", err)
+		}
+		return rec.Code, report
+	}
+
+	code, report := readyz()
+	if code != http.StatusOK || report.Status != server.StatusOK || len(report.Checks) != 2 {
+		t.Fatalf("GET /readyz = %d %+v, want 200 with 2 checks", code, report)
+	}
+
+	failProviders = true
+	code, report = readyz()
+	if code != http.StatusServiceUnavailable || report.Status != server.StatusFail {
+		t.Fatalf("GET /readyz = %d %q, want 503 fail", code, report.Status)
+	}
+	if got := report.Checks[0]; got.Name != "database" || got.Status != server.StatusOK || got.Error != "" {
+		t.Errorf("checks[0] = %+v, want passing database check", got)
+	}
+	if got := report.Checks[1]; got.Name != "providers" || got.Status != server.StatusFail || got.Error != providersErr.Error() {
+		t.Errorf("checks[1] = %+v, want failing providers check", got)
+	}
+}
+
+func TestPollerNotReadyBeforeRun(t *testing.T) {
+	poller := server.NewPoller(nil, nil)
+	if err := poller.HealthCheck(context.Background()); err == nil {
+		t.Error("HealthCheck() before Run = nil, want error")
+	}
+}